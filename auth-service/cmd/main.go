@@ -10,7 +10,6 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -38,6 +37,18 @@ func main() {
 
 	cfg := config.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		db, err := sql.Open("mysql", cfg.DatabaseDSN())
+		if err != nil {
+			log.Fatal("Failed to connect to DB:", err)
+		}
+		defer db.Close()
+		if err := runMigrate(ctx, db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	otelShutdown, err := utils.SetupOTelSDK(ctx, serviceName, serviceVersion, cfg.OtelExporterURL)
 	if err != nil {
 		log.Fatalf("Error setting up OpenTelemetry SDK: %v", err)
@@ -56,15 +67,7 @@ func main() {
 	utils.InitJWT(cfg.JWTSecret)
 
 	// Подключение к MariaDB (Consider instrumenting your SQL connection)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBName,
-	)
-
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", cfg.DatabaseDSN())
 	if err != nil {
 		log.Fatal("Failed to connect to DB:", err)
 	}
@@ -74,6 +77,12 @@ func main() {
 		log.Fatal("Failed to ping DB:", err)
 	}
 
+	if cfg.AutoMigrate {
+		if err := runMigrate(ctx, db, []string{"up"}); err != nil {
+			log.Fatal("Failed to migrate DB:", err)
+		}
+	}
+
 	// Подключение к Redis (Consider instrumenting your Redis client)
 	redisClient := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr, Password: cfg.RedisPassword})
 	defer redisClient.Close()
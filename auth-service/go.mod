@@ -19,6 +19,7 @@ require (
 	go.opentelemetry.io/otel/sdk/metric v1.38.0
 	golang.org/x/crypto v0.41.0
 	google.golang.org/grpc v1.76.0
+	pkg/migrate v0.0.0
 )
 
 require (
@@ -54,3 +55,5 @@ require (
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20250825161204-c5933d9347a5 // indirect
 	google.golang.org/protobuf v1.36.8 // indirect
 )
+
+replace pkg/migrate => ../pkg/migrate
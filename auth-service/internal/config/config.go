@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"fmt"
+	"os"
+)
 
 type Config struct {
 	DBHost     string
@@ -8,6 +11,8 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 	DBName     string
+	// AutoMigrate применяет миграции при старте (см. `auth-service migrate`).
+	AutoMigrate bool
 
 	RedisAddr     string
 	RedisPassword string
@@ -25,6 +30,8 @@ func Load() *Config {
 		DBPassword: getEnv("DB_PASSWORD", "auth_pass"),
 		DBName:     getEnv("DB_NAME", "auth_db"),
 
+		AutoMigrate: getEnv("AUTO_MIGRATE", "false") == "true",
+
 		RedisAddr:     getEnv("REDIS_ADDR", "192.168.0.176:6379"),
 		RedisPassword: getEnv("REDIS_PASSWORD", "2Uve6YlxN7"),
 
@@ -34,6 +41,11 @@ func Load() *Config {
 	}
 }
 
+// DatabaseDSN returns the MySQL connection string.
+func (c *Config) DatabaseDSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
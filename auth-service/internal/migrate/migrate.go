@@ -0,0 +1,26 @@
+// internal/migrate/migrate.go
+
+// Package migrate — миграции auth-service (MySQL/MariaDB): файлы migrations/ и
+// общий мигратор pkg/migrate.
+package migrate
+
+import (
+	"database/sql"
+	"embed"
+
+	"pkg/migrate"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// lockName — имя advisory lock; одинаковое у всех реплик сервиса.
+const lockName = "auth-service.migrate"
+
+func New(db *sql.DB) (*migrate.Migrator, error) {
+	driver, err := migrate.NewSQLDriver(db, migrate.DialectMySQL)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(driver, lockName, migrationsFS, "migrations")
+}
@@ -0,0 +1,41 @@
+package events
+
+import (
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// HeaderCarrier adapts Kafka message headers to propagation.TextMapCarrier,
+// so traceparent and baggage travel with the message.
+type HeaderCarrier struct {
+	msg *kafka.Message
+}
+
+var _ propagation.TextMapCarrier = HeaderCarrier{}
+
+func NewHeaderCarrier(msg *kafka.Message) HeaderCarrier {
+	return HeaderCarrier{msg: msg}
+}
+
+func (c HeaderCarrier) Get(key string) string {
+	return Header(*c.msg, key)
+}
+
+// Set replaces an existing header with the same key instead of appending a duplicate.
+func (c HeaderCarrier) Set(key, value string) {
+	for i, h := range c.msg.Headers {
+		if h.Key == key {
+			c.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.msg.Headers = append(c.msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+	for _, h := range c.msg.Headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
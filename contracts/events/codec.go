@@ -0,0 +1,56 @@
+package events
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+)
+
+// newProtoMessage encodes event with the latest schema of topic.
+// The message key is the order ID for order events and the product ID for inventory
+// events, so all events of one order or product land in one partition.
+func newProtoMessage(topic string, key int64, event proto.Message) (kafka.Message, error) {
+	schema, err := Latest(topic)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	if schema.ContentType != ContentTypeProtobuf {
+		return kafka.Message{}, fmt.Errorf("%w: latest %s schema is not protobuf", ErrUnsupportedSchema, topic)
+	}
+
+	payload, err := proto.Marshal(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	return kafka.Message{
+		Topic: topic,
+		Key:   []byte(strconv.FormatInt(key, 10)),
+		Value: payload,
+		Headers: []kafka.Header{
+			{Key: HeaderContentType, Value: []byte(schema.ContentType)},
+			{Key: HeaderSchemaVersion, Value: versionHeader(schema)},
+		},
+	}, nil
+}
+
+// decodeProto decodes a message of a Protobuf-only topic.
+func decodeProto(msg kafka.Message, topic string, event proto.Message) error {
+	schema, err := schemaOf(topic, Header(msg, HeaderSchemaVersion), Header(msg, HeaderContentType))
+	if err != nil {
+		return err
+	}
+	if schema.ContentType != ContentTypeProtobuf {
+		return fmt.Errorf("%w: %s v%d", ErrUnsupportedSchema, topic, schema.Version)
+	}
+	return unmarshalProto(msg.Value, event)
+}
+
+func unmarshalProto(value []byte, event proto.Message) error {
+	if err := proto.Unmarshal(value, event); err != nil {
+		return fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package events — общий контракт Kafka-сообщений между сервисами.
+// Producer и consumer используют одни и те же функции кодирования, а contract-тесты
+// (events_test.go) прогоняют каждую версию каждого топика реестра через New*Message
+// и Decode*, поэтому расхождение схем валит go test, а не продакшен.
+package events
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	HeaderContentType   = "content-type"
+	HeaderSchemaVersion = "schema-version"
+
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+const (
+	TopicOrderCreated   = "order.created"
+	TopicOrderPaid      = "order.paid"
+	TopicOrderCancelled = "order.cancelled"
+	TopicOrderShipped   = "order.shipped"
+	TopicOrderDelivered = "order.delivered"
+
+	TopicOrderReturnReceived = "order.return_received"
+)
+
+// Топики inventory-service; ключ сообщения — product_id, у reservation_failed — order_id.
+const (
+	TopicStockChanged = "inventory.stock_changed"
+	TopicLowStock     = "inventory.low_stock"
+	TopicOutOfStock   = "inventory.out_of_stock"
+
+	TopicReservationFailed = "inventory.reservation_failed"
+)
+
+var (
+	ErrUnsupportedSchema = errors.New("unsupported schema")
+	ErrMalformedMessage  = errors.New("malformed message")
+)
+
+// NewEventID returns a unique ID for an outgoing event.
+func NewEventID() string {
+	return uuid.NewString()
+}
+
+// Header returns the value of the first header with the given key.
+func Header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
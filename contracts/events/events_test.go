@@ -0,0 +1,246 @@
+package events_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"contracts/events"
+	inventoryv1 "contracts/gen/inventory/v1"
+	ordersv1 "contracts/gen/orders/v1"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Contract-тесты: для каждой версии каждого топика из реестра сообщение кодируется
+// так, как его пишет producer (New*Message или старый формат), и читается так, как
+// его читает consumer (Decode*). Новый топик или версия без случая здесь валит тест.
+
+var occurredAt = timestamppb.New(time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC))
+
+var items = []*ordersv1.OrderItem{
+	{ProductId: 123, Quantity: 2, UnitPrice: 49900},
+	{ProductId: 456, Quantity: 1, UnitPrice: 129900},
+}
+
+// latestCases — producer и consumer текущей версии каждого топика.
+var latestCases = map[string]func(t *testing.T){
+	events.TopicOrderCreated: func(t *testing.T) {
+		roundTrip(t, events.TopicOrderCreated, 42, &ordersv1.OrderCreated{
+			EventId: "e-1", OrderId: 42, UserId: 7, Currency: "RUB", TotalAmount: 229700,
+			Items: items, OccurredAt: occurredAt, ShippingRegion: "msk",
+		}, events.NewOrderCreatedMessage, events.DecodeOrderCreated)
+	},
+	events.TopicOrderPaid: func(t *testing.T) {
+		roundTrip(t, events.TopicOrderPaid, 42, &ordersv1.OrderPaid{
+			EventId: "e-2", OrderId: 42, UserId: 7, Currency: "RUB", Amount: 229700, OccurredAt: occurredAt,
+		}, events.NewOrderPaidMessage, events.DecodeOrderPaid)
+	},
+	events.TopicOrderCancelled: func(t *testing.T) {
+		roundTrip(t, events.TopicOrderCancelled, 42, &ordersv1.OrderCancelled{
+			EventId: "e-3", OrderId: 42, UserId: 7, Reason: "payment_timeout", Items: items,
+			Refunded: true, OccurredAt: occurredAt,
+		}, events.NewOrderCancelledMessage, events.DecodeOrderCancelled)
+	},
+	events.TopicOrderShipped: func(t *testing.T) {
+		roundTrip(t, events.TopicOrderShipped, 42, &ordersv1.OrderShipped{
+			EventId: "e-4", OrderId: 42, UserId: 7, Carrier: "cdek", TrackingNumber: "TRK-1", OccurredAt: occurredAt,
+		}, events.NewOrderShippedMessage, events.DecodeOrderShipped)
+	},
+	events.TopicOrderDelivered: func(t *testing.T) {
+		roundTrip(t, events.TopicOrderDelivered, 42, &ordersv1.OrderDelivered{
+			EventId: "e-5", OrderId: 42, UserId: 7, OccurredAt: occurredAt,
+		}, events.NewOrderDeliveredMessage, events.DecodeOrderDelivered)
+	},
+	events.TopicOrderReturnReceived: func(t *testing.T) {
+		roundTrip(t, events.TopicOrderReturnReceived, 42, &ordersv1.OrderReturnReceived{
+			EventId: "e-6", ReturnId: 3, OrderId: 42, UserId: 7, Items: items[:1], Quarantine: true, OccurredAt: occurredAt,
+		}, events.NewOrderReturnReceivedMessage, events.DecodeOrderReturnReceived)
+	},
+	events.TopicStockChanged: func(t *testing.T) {
+		roundTrip(t, events.TopicStockChanged, 123, &inventoryv1.StockChanged{
+			EventId: "e-7", WarehouseCode: "main", ProductId: 123, MovementType: "reserve",
+			QuantityDelta: -1, ReservedDelta: 2, QuarantineDelta: 3, Quantity: 10, Reserved: 4, Available: 6,
+			Quarantine: 3, Version: 9, OrderId: 42, OccurredAt: occurredAt,
+		}, events.NewStockChangedMessage, events.DecodeStockChanged)
+	},
+	events.TopicLowStock: func(t *testing.T) {
+		roundTrip(t, events.TopicLowStock, 123, &inventoryv1.LowStock{
+			EventId: "e-8", ProductId: 123, Available: 5, Threshold: 10, OccurredAt: occurredAt,
+		}, events.NewLowStockMessage, events.DecodeLowStock)
+	},
+	events.TopicOutOfStock: func(t *testing.T) {
+		roundTrip(t, events.TopicOutOfStock, 123, &inventoryv1.OutOfStock{
+			EventId: "e-9", ProductId: 123, Threshold: 10, OccurredAt: occurredAt,
+		}, events.NewOutOfStockMessage, events.DecodeOutOfStock)
+	},
+	events.TopicReservationFailed: func(t *testing.T) {
+		roundTrip(t, events.TopicReservationFailed, 42, &inventoryv1.ReservationFailed{
+			EventId: "e-10", OrderId: 42, Reason: "insufficient stock for product 123", OccurredAt: occurredAt,
+		}, events.NewReservationFailedMessage, events.DecodeReservationFailed)
+	},
+}
+
+// zeroFields — поля, которые в событии всегда нулевые, а в proto3 ноль неотличим от пустого.
+var zeroFields = map[protoreflect.FullName]bool{
+	"inventory.v1.OutOfStock.available": true,
+}
+
+// legacyCases — старые версии, которые consumer ещё обязан читать: сообщения в том
+// виде, в каком их писали прежние producer'ы.
+var legacyCases = map[string]map[int]func(t *testing.T){
+	events.TopicOrderCreated: {
+		1: func(t *testing.T) {
+			want := &ordersv1.OrderCreated{OrderId: 42, UserId: 7, Currency: "RUB", TotalAmount: 229700, Items: items}
+			multiItem := []byte(`{"order_id":42,"user_id":7,"currency":"RUB","total_amount":229700,"items":[` +
+				`{"product_id":123,"quantity":2,"unit_price":49900},{"product_id":456,"quantity":1,"unit_price":129900}]}`)
+
+			// Без заголовков — так писал order-service до реестра схем
+			decodeCreated(t, kafka.Message{Value: multiItem}, want)
+			decodeCreated(t, kafka.Message{Value: multiItem, Headers: headers("1", events.ContentTypeJSON)}, want)
+
+			// Самый ранний формат: одна позиция в корне сообщения
+			decodeCreated(t, kafka.Message{Value: []byte(`{"order_id":42,"user_id":7,"product_id":123,"quantity":2}`)},
+				&ordersv1.OrderCreated{OrderId: 42, UserId: 7, Items: []*ordersv1.OrderItem{{ProductId: 123, Quantity: 2}}})
+		},
+	},
+}
+
+func TestEveryRegisteredSchemaHasContract(t *testing.T) {
+	for _, subject := range events.Subjects() {
+		latest, err := events.Latest(subject)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, schema := range events.Versions(subject) {
+			name := subject + "/v" + strconv.Itoa(schema.Version)
+			test := legacyCases[subject][schema.Version]
+			if schema.Version == latest.Version {
+				test = latestCases[subject]
+			}
+			if test == nil {
+				t.Errorf("%s: no contract test for the registered schema", name)
+				continue
+			}
+			t.Run(name, test)
+		}
+	}
+}
+
+func TestOrderCreatedCompatibility(t *testing.T) {
+	event := &ordersv1.OrderCreated{EventId: "e-1", OrderId: 42, UserId: 7, Currency: "RUB", Items: items, OccurredAt: occurredAt}
+	msg, err := events.NewOrderCreatedMessage(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// v2 без content-type (старые инструменты копируют только schema-version)
+	decodeCreated(t, kafka.Message{Value: msg.Value, Headers: headers("2", "")}, event)
+
+	// Protobuf без заголовков читается как v1 JSON — это ошибка формата, а не тихий мусор
+	if _, err := events.DecodeOrderCreated(kafka.Message{Value: msg.Value}); !errors.Is(err, events.ErrMalformedMessage) {
+		t.Errorf("v2 payload without headers: got %v, want ErrMalformedMessage", err)
+	}
+
+	for name, h := range map[string][]kafka.Header{
+		"v2 declared as JSON":  headers("2", events.ContentTypeJSON),
+		"v1 declared as proto": headers("1", events.ContentTypeProtobuf),
+		"unknown version":      headers("3", events.ContentTypeProtobuf),
+		"malformed version":    headers("two", events.ContentTypeProtobuf),
+	} {
+		if _, err := events.DecodeOrderCreated(kafka.Message{Value: msg.Value, Headers: h}); !errors.Is(err, events.ErrUnsupportedSchema) {
+			t.Errorf("%s: got %v, want ErrUnsupportedSchema", name, err)
+		}
+	}
+
+	// У остальных топиков одна версия: v2 consumer не угадывает
+	paid := kafka.Message{Value: msg.Value, Headers: headers("2", events.ContentTypeProtobuf)}
+	if _, err := events.DecodeOrderPaid(paid); !errors.Is(err, events.ErrUnsupportedSchema) {
+		t.Errorf("order.paid v2: got %v, want ErrUnsupportedSchema", err)
+	}
+}
+
+func TestConstructorsFillEventIDAndTime(t *testing.T) {
+	event := &ordersv1.OrderPaid{OrderId: 42}
+	msg, err := events.NewOrderPaidMessage(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := events.DecodeOrderPaid(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.GetEventId() == "" || decoded.GetOccurredAt() == nil {
+		t.Errorf("event_id and occurred_at must be filled in, got %v", decoded)
+	}
+}
+
+// roundTrip encodes event with the producer constructor and decodes it with the consumer
+// function: topic, key and schema headers must match the latest schema and every field must survive.
+func roundTrip[T proto.Message](t *testing.T, topic string, key int64, event T,
+	encode func(T) (kafka.Message, error), decode func(kafka.Message) (T, error)) {
+	t.Helper()
+
+	msg, err := encode(event)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	schema, err := events.Latest(topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Topic != topic {
+		t.Errorf("topic = %q, want %q", msg.Topic, topic)
+	}
+	if string(msg.Key) != strconv.FormatInt(key, 10) {
+		t.Errorf("key = %q, want %d", msg.Key, key)
+	}
+	if got := events.Header(msg, events.HeaderContentType); got != schema.ContentType {
+		t.Errorf("content-type = %q, want %q", got, schema.ContentType)
+	}
+	if got := events.Header(msg, events.HeaderSchemaVersion); got != strconv.Itoa(schema.Version) {
+		t.Errorf("schema-version = %q, want %d", got, schema.Version)
+	}
+	if schema.Descriptor != nil && event.ProtoReflect().Descriptor().FullName() != schema.Descriptor.FullName() {
+		t.Errorf("producer writes %s, registry expects %s", event.ProtoReflect().Descriptor().FullName(), schema.Descriptor.FullName())
+	}
+
+	// Образец заполняет все поля схемы, иначе потерю нового поля не заметить
+	fields := event.ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if !event.ProtoReflect().Has(fields.Get(i)) && !zeroFields[fields.Get(i).FullName()] {
+			t.Errorf("sample %s leaves field %s empty", topic, fields.Get(i).Name())
+		}
+	}
+
+	decoded, err := decode(msg)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !proto.Equal(decoded, event) {
+		t.Errorf("decoded %v, want %v", decoded, event)
+	}
+}
+
+func decodeCreated(t *testing.T, msg kafka.Message, want *ordersv1.OrderCreated) {
+	t.Helper()
+	got, err := events.DecodeOrderCreated(msg)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("decoded %v, want %v", got, want)
+	}
+}
+
+func headers(version, contentType string) []kafka.Header {
+	h := []kafka.Header{{Key: events.HeaderSchemaVersion, Value: []byte(version)}}
+	if contentType != "" {
+		h = append(h, kafka.Header{Key: events.HeaderContentType, Value: []byte(contentType)})
+	}
+	return h
+}
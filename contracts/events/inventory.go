@@ -0,0 +1,82 @@
+package events
+
+import (
+	"time"
+
+	inventoryv1 "contracts/gen/inventory/v1"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func NewStockChangedMessage(event *inventoryv1.StockChanged) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicStockChanged, event.GetProductId(), event)
+}
+
+func DecodeStockChanged(msg kafka.Message) (*inventoryv1.StockChanged, error) {
+	event := &inventoryv1.StockChanged{}
+	if err := decodeProto(msg, TopicStockChanged, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func NewLowStockMessage(event *inventoryv1.LowStock) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicLowStock, event.GetProductId(), event)
+}
+
+func DecodeLowStock(msg kafka.Message) (*inventoryv1.LowStock, error) {
+	event := &inventoryv1.LowStock{}
+	if err := decodeProto(msg, TopicLowStock, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func NewOutOfStockMessage(event *inventoryv1.OutOfStock) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicOutOfStock, event.GetProductId(), event)
+}
+
+func DecodeOutOfStock(msg kafka.Message) (*inventoryv1.OutOfStock, error) {
+	event := &inventoryv1.OutOfStock{}
+	if err := decodeProto(msg, TopicOutOfStock, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func NewReservationFailedMessage(event *inventoryv1.ReservationFailed) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicReservationFailed, event.GetOrderId(), event)
+}
+
+func DecodeReservationFailed(msg kafka.Message) (*inventoryv1.ReservationFailed, error) {
+	event := &inventoryv1.ReservationFailed{}
+	if err := decodeProto(msg, TopicReservationFailed, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
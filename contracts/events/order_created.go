@@ -0,0 +1,88 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	ordersv1 "contracts/gen/orders/v1"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// orderCreatedV1 — JSON-формат schema-version 1.
+type orderCreatedV1 struct {
+	OrderID     int64  `json:"order_id"`
+	UserID      int64  `json:"user_id"`
+	Currency    string `json:"currency"`
+	TotalAmount int64  `json:"total_amount"`
+	Items       []struct {
+		ProductID int64 `json:"product_id"`
+		Quantity  int32 `json:"quantity"`
+		UnitPrice int64 `json:"unit_price"`
+	} `json:"items"`
+
+	// Самый ранний формат: одна позиция на заказ
+	ProductID int64 `json:"product_id,omitempty"`
+	Quantity  int32 `json:"quantity,omitempty"`
+}
+
+// NewOrderCreatedMessage encodes the event with the latest order.created schema.
+// EventID and OccurredAt are filled in when empty.
+func NewOrderCreatedMessage(event *ordersv1.OrderCreated) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+
+	return newProtoMessage(TopicOrderCreated, event.GetOrderId(), event)
+}
+
+// DecodeOrderCreated decodes any supported version of order.created.
+func DecodeOrderCreated(msg kafka.Message) (*ordersv1.OrderCreated, error) {
+	schema, err := schemaOf(TopicOrderCreated, Header(msg, HeaderSchemaVersion), Header(msg, HeaderContentType))
+	if err != nil {
+		return nil, err
+	}
+
+	switch schema.Version {
+	case 1:
+		return decodeOrderCreatedV1(msg.Value)
+	case 2:
+		event := &ordersv1.OrderCreated{}
+		if err := unmarshalProto(msg.Value, event); err != nil {
+			return nil, err
+		}
+		return event, nil
+	default:
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedSchema, TopicOrderCreated, schema.Version)
+	}
+}
+
+func decodeOrderCreatedV1(value []byte) (*ordersv1.OrderCreated, error) {
+	var v1 orderCreatedV1
+	if err := json.Unmarshal(value, &v1); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+
+	event := &ordersv1.OrderCreated{
+		OrderId:     v1.OrderID,
+		UserId:      v1.UserID,
+		Currency:    v1.Currency,
+		TotalAmount: v1.TotalAmount,
+	}
+	for _, item := range v1.Items {
+		event.Items = append(event.Items, &ordersv1.OrderItem{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+			UnitPrice: item.UnitPrice,
+		})
+	}
+	if len(event.Items) == 0 && v1.ProductID != 0 {
+		event.Items = []*ordersv1.OrderItem{{ProductId: v1.ProductID, Quantity: v1.Quantity}}
+	}
+	return event, nil
+}
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"time"
+
+	ordersv1 "contracts/gen/orders/v1"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func NewOrderPaidMessage(event *ordersv1.OrderPaid) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicOrderPaid, event.GetOrderId(), event)
+}
+
+func DecodeOrderPaid(msg kafka.Message) (*ordersv1.OrderPaid, error) {
+	event := &ordersv1.OrderPaid{}
+	if err := decodeProto(msg, TopicOrderPaid, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func NewOrderCancelledMessage(event *ordersv1.OrderCancelled) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicOrderCancelled, event.GetOrderId(), event)
+}
+
+func DecodeOrderCancelled(msg kafka.Message) (*ordersv1.OrderCancelled, error) {
+	event := &ordersv1.OrderCancelled{}
+	if err := decodeProto(msg, TopicOrderCancelled, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func NewOrderShippedMessage(event *ordersv1.OrderShipped) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicOrderShipped, event.GetOrderId(), event)
+}
+
+func DecodeOrderShipped(msg kafka.Message) (*ordersv1.OrderShipped, error) {
+	event := &ordersv1.OrderShipped{}
+	if err := decodeProto(msg, TopicOrderShipped, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func NewOrderDeliveredMessage(event *ordersv1.OrderDelivered) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicOrderDelivered, event.GetOrderId(), event)
+}
+
+func DecodeOrderDelivered(msg kafka.Message) (*ordersv1.OrderDelivered, error) {
+	event := &ordersv1.OrderDelivered{}
+	if err := decodeProto(msg, TopicOrderDelivered, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func NewOrderReturnReceivedMessage(event *ordersv1.OrderReturnReceived) (kafka.Message, error) {
+	if event.GetEventId() == "" {
+		event.EventId = NewEventID()
+	}
+	if event.GetOccurredAt() == nil {
+		event.OccurredAt = timestamppb.New(time.Now())
+	}
+	return newProtoMessage(TopicOrderReturnReceived, event.GetOrderId(), event)
+}
+
+func DecodeOrderReturnReceived(msg kafka.Message) (*ordersv1.OrderReturnReceived, error) {
+	event := &ordersv1.OrderReturnReceived{}
+	if err := decodeProto(msg, TopicOrderReturnReceived, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
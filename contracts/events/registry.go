@@ -0,0 +1,129 @@
+package events
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	inventoryv1 "contracts/gen/inventory/v1"
+	ordersv1 "contracts/gen/orders/v1"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Schema — одна версия формата сообщений топика.
+type Schema struct {
+	Subject     string
+	Version     int
+	ContentType string
+	// Descriptor задан только для Protobuf-версий.
+	Descriptor protoreflect.MessageDescriptor
+}
+
+// registry — локальный реестр схем. Новая версия добавляется в конец списка,
+// старые остаются, пока их умеют читать consumer'ы.
+var registry = map[string][]Schema{
+	TopicOrderCreated: {
+		// v1 — JSON без заголовков, как писал order-service до перехода на Protobuf
+		{Subject: TopicOrderCreated, Version: 1, ContentType: ContentTypeJSON},
+		{Subject: TopicOrderCreated, Version: 2, ContentType: ContentTypeProtobuf,
+			Descriptor: (&ordersv1.OrderCreated{}).ProtoReflect().Descriptor()},
+	},
+	TopicOrderPaid: {
+		{Subject: TopicOrderPaid, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&ordersv1.OrderPaid{}).ProtoReflect().Descriptor()},
+	},
+	TopicOrderCancelled: {
+		{Subject: TopicOrderCancelled, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&ordersv1.OrderCancelled{}).ProtoReflect().Descriptor()},
+	},
+	TopicOrderShipped: {
+		{Subject: TopicOrderShipped, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&ordersv1.OrderShipped{}).ProtoReflect().Descriptor()},
+	},
+	TopicOrderDelivered: {
+		{Subject: TopicOrderDelivered, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&ordersv1.OrderDelivered{}).ProtoReflect().Descriptor()},
+	},
+	TopicOrderReturnReceived: {
+		{Subject: TopicOrderReturnReceived, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&ordersv1.OrderReturnReceived{}).ProtoReflect().Descriptor()},
+	},
+	TopicStockChanged: {
+		{Subject: TopicStockChanged, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&inventoryv1.StockChanged{}).ProtoReflect().Descriptor()},
+	},
+	TopicLowStock: {
+		{Subject: TopicLowStock, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&inventoryv1.LowStock{}).ProtoReflect().Descriptor()},
+	},
+	TopicOutOfStock: {
+		{Subject: TopicOutOfStock, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&inventoryv1.OutOfStock{}).ProtoReflect().Descriptor()},
+	},
+	TopicReservationFailed: {
+		{Subject: TopicReservationFailed, Version: 1, ContentType: ContentTypeProtobuf,
+			Descriptor: (&inventoryv1.ReservationFailed{}).ProtoReflect().Descriptor()},
+	},
+}
+
+// Latest returns the version producers must write for the subject.
+func Latest(subject string) (Schema, error) {
+	schemas := registry[subject]
+	if len(schemas) == 0 {
+		return Schema{}, fmt.Errorf("%w: no schemas for subject %q", ErrUnsupportedSchema, subject)
+	}
+	return schemas[len(schemas)-1], nil
+}
+
+// Lookup returns a specific schema version of the subject.
+func Lookup(subject string, version int) (Schema, error) {
+	for _, s := range registry[subject] {
+		if s.Version == version {
+			return s, nil
+		}
+	}
+	return Schema{}, fmt.Errorf("%w: %s v%d", ErrUnsupportedSchema, subject, version)
+}
+
+// Subjects lists all registered subjects in sorted order.
+func Subjects() []string {
+	subjects := make([]string, 0, len(registry))
+	for s := range registry {
+		subjects = append(subjects, s)
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+// Versions lists the registered schema versions of the subject, oldest first.
+func Versions(subject string) []Schema {
+	return append([]Schema(nil), registry[subject]...)
+}
+
+// schemaOf resolves the schema of an incoming message from its headers.
+// Messages without a schema-version header are treated as version 1.
+func schemaOf(subject string, headerVersion, contentType string) (Schema, error) {
+	version := 1
+	if headerVersion != "" {
+		v, err := strconv.Atoi(headerVersion)
+		if err != nil {
+			return Schema{}, fmt.Errorf("%w: bad schema-version %q", ErrUnsupportedSchema, headerVersion)
+		}
+		version = v
+	}
+
+	schema, err := Lookup(subject, version)
+	if err != nil {
+		return Schema{}, err
+	}
+	if contentType != "" && contentType != schema.ContentType {
+		return Schema{}, fmt.Errorf("%w: %s v%d expects %s, got %s",
+			ErrUnsupportedSchema, subject, version, schema.ContentType, contentType)
+	}
+	return schema, nil
+}
+
+func versionHeader(s Schema) []byte {
+	return []byte(strconv.Itoa(s.Version))
+}
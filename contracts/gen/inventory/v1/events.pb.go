@@ -0,0 +1,515 @@
+// Контракт событий inventory-service. Правила эволюции те же, что у orders/v1:
+// поля не удаляются и не переиспользуются, номера удалённых полей — в reserved.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: inventory/v1/events.proto
+
+package inventoryv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// StockChanged публикуется в топик inventory.stock_changed после каждого изменения
+// остатка товара на складе. Несёт движение и состояние строки после него; по version
+// consumer отбрасывает устаревшие события.
+type StockChanged struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	WarehouseCode string                 `protobuf:"bytes,2,opt,name=warehouse_code,json=warehouseCode,proto3" json:"warehouse_code,omitempty"`
+	ProductId     int64                  `protobuf:"varint,3,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	// Тип движения из журнала: reserve, release, expire, deduct, restock, return,
+	// receive, damage, correction, set, transfer_out, transfer_in, import, import_undo.
+	MovementType    string `protobuf:"bytes,4,opt,name=movement_type,json=movementType,proto3" json:"movement_type,omitempty"`
+	QuantityDelta   int32  `protobuf:"varint,5,opt,name=quantity_delta,json=quantityDelta,proto3" json:"quantity_delta,omitempty"`
+	ReservedDelta   int32  `protobuf:"varint,6,opt,name=reserved_delta,json=reservedDelta,proto3" json:"reserved_delta,omitempty"`
+	QuarantineDelta int32  `protobuf:"varint,7,opt,name=quarantine_delta,json=quarantineDelta,proto3" json:"quarantine_delta,omitempty"`
+	Quantity        int32  `protobuf:"varint,8,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Reserved        int32  `protobuf:"varint,9,opt,name=reserved,proto3" json:"reserved,omitempty"`
+	// available = quantity - reserved.
+	Available  int32 `protobuf:"varint,10,opt,name=available,proto3" json:"available,omitempty"`
+	Quarantine int32 `protobuf:"varint,11,opt,name=quarantine,proto3" json:"quarantine,omitempty"`
+	Version    int64 `protobuf:"varint,12,opt,name=version,proto3" json:"version,omitempty"`
+	// 0, если движение не связано с заказом.
+	OrderId       int64                  `protobuf:"varint,13,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockChanged) Reset() {
+	*x = StockChanged{}
+	mi := &file_inventory_v1_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockChanged) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockChanged) ProtoMessage() {}
+
+func (x *StockChanged) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_v1_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockChanged.ProtoReflect.Descriptor instead.
+func (*StockChanged) Descriptor() ([]byte, []int) {
+	return file_inventory_v1_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StockChanged) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *StockChanged) GetWarehouseCode() string {
+	if x != nil {
+		return x.WarehouseCode
+	}
+	return ""
+}
+
+func (x *StockChanged) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *StockChanged) GetMovementType() string {
+	if x != nil {
+		return x.MovementType
+	}
+	return ""
+}
+
+func (x *StockChanged) GetQuantityDelta() int32 {
+	if x != nil {
+		return x.QuantityDelta
+	}
+	return 0
+}
+
+func (x *StockChanged) GetReservedDelta() int32 {
+	if x != nil {
+		return x.ReservedDelta
+	}
+	return 0
+}
+
+func (x *StockChanged) GetQuarantineDelta() int32 {
+	if x != nil {
+		return x.QuarantineDelta
+	}
+	return 0
+}
+
+func (x *StockChanged) GetQuantity() int32 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *StockChanged) GetReserved() int32 {
+	if x != nil {
+		return x.Reserved
+	}
+	return 0
+}
+
+func (x *StockChanged) GetAvailable() int32 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *StockChanged) GetQuarantine() int32 {
+	if x != nil {
+		return x.Quarantine
+	}
+	return 0
+}
+
+func (x *StockChanged) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *StockChanged) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *StockChanged) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+// LowStock публикуется в топик inventory.low_stock, когда свободный остаток товара
+// по всем складам опустился до порога дозаказа.
+type LowStock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	ProductId     int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Available     int32                  `protobuf:"varint,3,opt,name=available,proto3" json:"available,omitempty"`
+	Threshold     int32                  `protobuf:"varint,4,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LowStock) Reset() {
+	*x = LowStock{}
+	mi := &file_inventory_v1_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LowStock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LowStock) ProtoMessage() {}
+
+func (x *LowStock) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_v1_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LowStock.ProtoReflect.Descriptor instead.
+func (*LowStock) Descriptor() ([]byte, []int) {
+	return file_inventory_v1_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LowStock) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *LowStock) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *LowStock) GetAvailable() int32 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *LowStock) GetThreshold() int32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *LowStock) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+// OutOfStock публикуется в топик inventory.out_of_stock, когда свободного остатка
+// товара не осталось ни на одном складе.
+type OutOfStock struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	EventId   string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	ProductId int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Available int32                  `protobuf:"varint,3,opt,name=available,proto3" json:"available,omitempty"`
+	// Порог дозаказа товара; 0 — не задан.
+	Threshold     int32                  `protobuf:"varint,4,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutOfStock) Reset() {
+	*x = OutOfStock{}
+	mi := &file_inventory_v1_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutOfStock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutOfStock) ProtoMessage() {}
+
+func (x *OutOfStock) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_v1_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutOfStock.ProtoReflect.Descriptor instead.
+func (*OutOfStock) Descriptor() ([]byte, []int) {
+	return file_inventory_v1_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OutOfStock) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *OutOfStock) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *OutOfStock) GetAvailable() int32 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *OutOfStock) GetThreshold() int32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *OutOfStock) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+// ReservationFailed публикуется в топик inventory.reservation_failed, когда товар
+// нового заказа не удалось удержать: свободного остатка не хватило. order-service
+// отменяет такой заказ. Ключ сообщения — order_id.
+type ReservationFailed struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	EventId string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	OrderId int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// Чего не хватило, для статуса заказа и логов.
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReservationFailed) Reset() {
+	*x = ReservationFailed{}
+	mi := &file_inventory_v1_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReservationFailed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReservationFailed) ProtoMessage() {}
+
+func (x *ReservationFailed) ProtoReflect() protoreflect.Message {
+	mi := &file_inventory_v1_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReservationFailed.ProtoReflect.Descriptor instead.
+func (*ReservationFailed) Descriptor() ([]byte, []int) {
+	return file_inventory_v1_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReservationFailed) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ReservationFailed) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *ReservationFailed) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ReservationFailed) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+var File_inventory_v1_events_proto protoreflect.FileDescriptor
+
+const file_inventory_v1_events_proto_rawDesc = "" +
+	"\n" +
+	"\x19inventory/v1/events.proto\x12\finventory.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xf5\x03\n" +
+	"\fStockChanged\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12%\n" +
+	"\x0ewarehouse_code\x18\x02 \x01(\tR\rwarehouseCode\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x03 \x01(\x03R\tproductId\x12#\n" +
+	"\rmovement_type\x18\x04 \x01(\tR\fmovementType\x12%\n" +
+	"\x0equantity_delta\x18\x05 \x01(\x05R\rquantityDelta\x12%\n" +
+	"\x0ereserved_delta\x18\x06 \x01(\x05R\rreservedDelta\x12)\n" +
+	"\x10quarantine_delta\x18\a \x01(\x05R\x0fquarantineDelta\x12\x1a\n" +
+	"\bquantity\x18\b \x01(\x05R\bquantity\x12\x1a\n" +
+	"\breserved\x18\t \x01(\x05R\breserved\x12\x1c\n" +
+	"\tavailable\x18\n" +
+	" \x01(\x05R\tavailable\x12\x1e\n" +
+	"\n" +
+	"quarantine\x18\v \x01(\x05R\n" +
+	"quarantine\x12\x18\n" +
+	"\aversion\x18\f \x01(\x03R\aversion\x12\x19\n" +
+	"\border_id\x18\r \x01(\x03R\aorderId\x12;\n" +
+	"\voccurred_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"\xbd\x01\n" +
+	"\bLowStock\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x1c\n" +
+	"\tavailable\x18\x03 \x01(\x05R\tavailable\x12\x1c\n" +
+	"\tthreshold\x18\x04 \x01(\x05R\tthreshold\x12;\n" +
+	"\voccurred_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"\xbf\x01\n" +
+	"\n" +
+	"OutOfStock\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x1c\n" +
+	"\tavailable\x18\x03 \x01(\x05R\tavailable\x12\x1c\n" +
+	"\tthreshold\x18\x04 \x01(\x05R\tthreshold\x12;\n" +
+	"\voccurred_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\"\x9e\x01\n" +
+	"\x11ReservationFailed\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12;\n" +
+	"\voccurred_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAtB(Z&contracts/gen/inventory/v1;inventoryv1b\x06proto3"
+
+var (
+	file_inventory_v1_events_proto_rawDescOnce sync.Once
+	file_inventory_v1_events_proto_rawDescData []byte
+)
+
+func file_inventory_v1_events_proto_rawDescGZIP() []byte {
+	file_inventory_v1_events_proto_rawDescOnce.Do(func() {
+		file_inventory_v1_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_inventory_v1_events_proto_rawDesc), len(file_inventory_v1_events_proto_rawDesc)))
+	})
+	return file_inventory_v1_events_proto_rawDescData
+}
+
+var file_inventory_v1_events_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_inventory_v1_events_proto_goTypes = []any{
+	(*StockChanged)(nil),          // 0: inventory.v1.StockChanged
+	(*LowStock)(nil),              // 1: inventory.v1.LowStock
+	(*OutOfStock)(nil),            // 2: inventory.v1.OutOfStock
+	(*ReservationFailed)(nil),     // 3: inventory.v1.ReservationFailed
+	(*timestamppb.Timestamp)(nil), // 4: google.protobuf.Timestamp
+}
+var file_inventory_v1_events_proto_depIdxs = []int32{
+	4, // 0: inventory.v1.StockChanged.occurred_at:type_name -> google.protobuf.Timestamp
+	4, // 1: inventory.v1.LowStock.occurred_at:type_name -> google.protobuf.Timestamp
+	4, // 2: inventory.v1.OutOfStock.occurred_at:type_name -> google.protobuf.Timestamp
+	4, // 3: inventory.v1.ReservationFailed.occurred_at:type_name -> google.protobuf.Timestamp
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_inventory_v1_events_proto_init() }
+func file_inventory_v1_events_proto_init() {
+	if File_inventory_v1_events_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_inventory_v1_events_proto_rawDesc), len(file_inventory_v1_events_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_inventory_v1_events_proto_goTypes,
+		DependencyIndexes: file_inventory_v1_events_proto_depIdxs,
+		MessageInfos:      file_inventory_v1_events_proto_msgTypes,
+	}.Build()
+	File_inventory_v1_events_proto = out.File
+	file_inventory_v1_events_proto_goTypes = nil
+	file_inventory_v1_events_proto_depIdxs = nil
+}
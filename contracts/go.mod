@@ -0,0 +1,16 @@
+module contracts
+
+go 1.25.1
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/segmentio/kafka-go v0.4.49
+	go.opentelemetry.io/otel v1.38.0
+	google.golang.org/protobuf v1.36.9
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	go.opentelemetry.io/otel/trace v1.38.0 // indirect
+)
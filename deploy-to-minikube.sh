@@ -37,8 +37,7 @@ helm upgrade --install postgres-auth bitnami/postgresql \
   --set primary.resources.limits.cpu=500m \
   --set primary.resources.limits.memory=512Mi \
   --set primary.extendedConfiguration="max_connections = 100" \
-  --set primary.initdbScripts.create_monitor_user.sql="CREATE USER monitor_user WITH PASSWORD 'monitor_pass'; GRANT pg_monitor TO monitor_user;" \
-  --set-file primary.initdbScripts.auth-init.sql="$PROJECT_ROOT/init-sql/auth-init.sql"
+  --set primary.initdbScripts.create_monitor_user.sql="CREATE USER monitor_user WITH PASSWORD 'monitor_pass'; GRANT pg_monitor TO monitor_user;"
 
 helm upgrade --install postgres-orders bitnami/postgresql \
   --version 15.x.x \
@@ -50,8 +49,7 @@ helm upgrade --install postgres-orders bitnami/postgresql \
   --set primary.resources.limits.cpu=500m \
   --set primary.resources.limits.memory=512Mi \
   --set primary.extendedConfiguration="max_connections = 100" \
-  --set primary.initdbScripts.create_monitor_user.sql="CREATE USER monitor_user WITH PASSWORD 'monitor_pass'; GRANT pg_monitor TO monitor_user;" \
-  --set-file primary.initdbScripts.orders-init.sql="$PROJECT_ROOT/init-sql/orders-init.sql"
+  --set primary.initdbScripts.create_monitor_user.sql="CREATE USER monitor_user WITH PASSWORD 'monitor_pass'; GRANT pg_monitor TO monitor_user;"
 
 helm upgrade --install postgres-inventory bitnami/postgresql \
   --version 15.x.x \
@@ -63,8 +61,7 @@ helm upgrade --install postgres-inventory bitnami/postgresql \
   --set primary.resources.limits.cpu=500m \
   --set primary.resources.limits.memory=512Mi \
   --set primary.extendedConfiguration="max_connections = 100" \
-  --set primary.initdbScripts.create_monitor_user.sql="CREATE USER monitor_user WITH PASSWORD 'monitor_pass'; GRANT pg_monitor TO monitor_user;" \
-  --set-file primary.initdbScripts.inventory-init.sql="$PROJECT_ROOT/init-sql/inventory-init.sql"
+  --set primary.initdbScripts.create_monitor_user.sql="CREATE USER monitor_user WITH PASSWORD 'monitor_pass'; GRANT pg_monitor TO monitor_user;"
 
 # 6. Установка Redis
 echo "💾 Installing Redis..."
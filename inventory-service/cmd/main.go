@@ -10,20 +10,28 @@ import (
 	"syscall"
 	"time"
 
+	"inventory-service/internal/allocation"
 	"inventory-service/internal/config"
 	"inventory-service/internal/consumer"
+	"inventory-service/internal/handler"
+	invmw "inventory-service/internal/middleware"
 	"inventory-service/internal/repository"
 	"inventory-service/internal/service"
+	"inventory-service/internal/utils"
 
 	"github.com/go-pg/pg/v10"
 	"github.com/labstack/echo/v4"
 	echomw "github.com/labstack/echo/v4/middleware"
+	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
 func newOTelProvider(ctx context.Context, endpoint string) (*sdktrace.TracerProvider, error) {
@@ -40,9 +48,32 @@ func newOTelProvider(ctx context.Context, endpoint string) (*sdktrace.TracerProv
 		)),
 	)
 	otel.SetTracerProvider(tp)
+	// W3C traceparent + baggage: продолжаем трейсы order-service из заголовков Kafka
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
 	return tp, nil
 }
 
+// newMeterProvider exports metrics (e.g. skipped duplicate events) to the same collector.
+func newMeterProvider(ctx context.Context, endpoint string) (*sdkmetric.MeterProvider, error) {
+	exporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName("inventory-service"),
+		)),
+	)
+	otel.SetMeterProvider(mp)
+	return mp, nil
+}
+
 func main() {
 	cfg := config.Load()
 
@@ -56,6 +87,13 @@ func main() {
 	db := pg.Connect(&pgOpts)
 	defer db.Close()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// OpenTelemetry
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -70,21 +108,63 @@ func main() {
 		}
 	}()
 
+	mp, err := newMeterProvider(ctx, cfg.OtelExporterURL)
+	if err != nil {
+		log.Fatal("Failed to create OTel meter provider:", err)
+	}
+	defer mp.Shutdown(context.Background())
+
+	if cfg.AutoMigrate {
+		if err := runMigrate(ctx, db, []string{"up"}); err != nil {
+			log.Fatal("Failed to migrate DB:", err)
+		}
+	}
+
 	// Repository & Service
 	repo := repository.NewInventoryRepository(db)
-	invService := service.NewInventoryService(repo)
+	strategy, err := allocation.New(cfg.AllocationStrategy)
+	if err != nil {
+		log.Fatal("Invalid ALLOCATION_STRATEGY:", err)
+	}
+	invService := service.NewInventoryService(repo, cfg.ReservationTTL, strategy, cfg.DefaultWarehouse)
+	stockHandler := handler.NewStockHandler(service.NewStockService(repo, cfg.DefaultWarehouse))
+	importHandler := handler.NewStockImportHandler(service.NewStockImportService(repo, cfg.DefaultWarehouse, cfg.StockImportChunk, cfg.StockImportMaxRows))
 
-	// Предзаполним склад для демо (в реальности — отдельный сервис каталога)
-	repo.EnsureStock(ctx, 123, 100) // product_id=123, qty=100
+	utils.InitJWT(cfg.JWTSecret)
+
+	// Kafka: ретрай-топики, DLQ, повторная отправка из админки и события inventory.*
+	kafkaWriter := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.KafkaBrokers...),
+		Balancer:               &kafka.Hash{}, // ключ order_id или product_id: события одного заказа или товара в одной партиции
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+	}
+	defer kafkaWriter.Close()
+
+	retryPolicy := consumer.RetryPolicy{
+		Attempts:   cfg.KafkaRetryAttempts,
+		Backoff:    cfg.KafkaRetryBackoff,
+		MaxBackoff: cfg.KafkaRetryMaxBackoff,
+		Delays:     cfg.KafkaRetryDelays,
+	}
+	concurrency := consumer.Concurrency{Workers: cfg.KafkaWorkers, MaxInFlight: cfg.KafkaMaxInFlight}
+	kafkaConsumer := consumer.NewKafkaConsumer(cfg.KafkaBrokers, cfg.KafkaGroupID, cfg.KafkaTopics, invService, kafkaWriter, retryPolicy, concurrency)
+
+	// Алерты об остатках: Kafka всегда, вебхук — если задан ALERT_WEBHOOK_URL
+	var notifier service.AlertNotifier
+	if cfg.AlertWebhookURL != "" {
+		notifier = service.NewWebhookNotifier(cfg.AlertWebhookURL, cfg.AlertWebhookTimeout)
+	}
+	outboxPublisher := service.NewOutboxPublisher(repo, kafkaWriter, notifier, cfg.OutboxPollInterval, cfg.OutboxBatch)
 
-	// Kafka Consumer
-	kafkaConsumer := consumer.NewKafkaConsumer(cfg.KafkaBrokers, cfg.KafkaGroupID, cfg.KafkaTopic, invService)
+	deadLetterRepo := repository.NewDeadLetterRepository(db)
+	dlqArchiver := consumer.NewDeadLetterArchiver(cfg.KafkaBrokers, cfg.KafkaGroupID, cfg.KafkaTopics, deadLetterRepo, retryPolicy)
 
 	// Graceful shutdown
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
-	// Запуск HTTP-сервера (для health-check и метрик)
+	// Запуск HTTP-сервера (health-check и REST API остатков)
 	e := echo.New()
 	e.Use(echomw.LoggerWithConfig(echomw.LoggerConfig{
 		Format: `{"time":"${time_rfc3339}", "method":"${method}", "uri":"${uri}", "status":${status}, "latency":"${latency_human}", "ip":"${remote_ip}"}` + "\n",
@@ -96,6 +176,57 @@ func main() {
 		return c.JSON(200, map[string]string{"status": "ok", "service": "inventory"})
 	})
 
+	// Остатки: чтение — любой авторизованный пользователь, изменения — операторы склада
+	authMid := invmw.Auth()
+	roles := invmw.Roles{}.
+		Grant(invmw.RoleAdmin, cfg.AdminUserIDs...).
+		Grant(invmw.RoleOperator, cfg.OperatorUserIDs...)
+	operators := invmw.RequireRole(roles, invmw.RoleOperator, invmw.RoleAdmin)
+
+	e.GET("/stock", stockHandler.List, authMid)
+	e.GET("/stock/alerts", stockHandler.Alerts, authMid, operators)
+	e.POST("/stock/import", importHandler.Import, authMid, operators)
+	e.GET("/stock/imports/:id", importHandler.Get, authMid, operators)
+	e.POST("/stock/imports/:id/undo", importHandler.Undo, authMid, operators)
+	e.GET("/stock/export", importHandler.Export, authMid, operators)
+	e.GET("/stock/:productId", stockHandler.Get, authMid)
+	e.GET("/stock/:productId/history", stockHandler.History, authMid, operators)
+	e.PUT("/stock/:productId", stockHandler.Set, authMid, operators)
+	e.POST("/stock/:productId/adjust", stockHandler.Adjust, authMid, operators)
+	e.POST("/stock/:productId/transfer", stockHandler.Transfer, authMid, operators)
+	e.GET("/stock/:productId/threshold", stockHandler.GetThreshold, authMid, operators)
+	e.PUT("/stock/:productId/threshold", stockHandler.SetThreshold, authMid, operators)
+	e.DELETE("/stock/:productId/threshold", stockHandler.DeleteThreshold, authMid, operators)
+
+	// Склады: список — любой авторизованный пользователь, изменения — админы
+	admins := invmw.RequireRole(roles, invmw.RoleAdmin)
+	warehouseHandler := handler.NewWarehouseHandler(service.NewWarehouseService(repo))
+	e.GET("/warehouses", warehouseHandler.List, authMid)
+	e.POST("/warehouses", warehouseHandler.Create, authMid, admins)
+	e.PUT("/warehouses/:code", warehouseHandler.Update, authMid, admins)
+
+	// Каталог товаров: чтение без авторизации (витрина, проверка заказов в order-service), изменения — админы
+	catalogHandler := handler.NewCatalogHandler(service.NewCatalogService(repo))
+	e.GET("/products", catalogHandler.List)
+	e.GET("/products/:id", catalogHandler.Get)
+	e.POST("/products", catalogHandler.Create, authMid, admins)
+	e.PUT("/products/:id", catalogHandler.Update, authMid, admins)
+
+	// Сверка остатков с журналом движений: по расписанию и по запросу
+	reconciler := service.NewStockReconciler(repo, cfg.ReconcileInterval)
+	e.GET("/admin/stock/reconciliation", handler.NewReconciliationHandler(reconciler).Run, authMid, admins)
+
+	// Удержания под заказы: подтверждение без онлайн-оплаты
+	reservationHandler := handler.NewReservationHandler(invService)
+	e.GET("/reservations/:orderId", reservationHandler.List, authMid, operators)
+	e.POST("/reservations/:orderId/confirm", reservationHandler.Confirm, authMid, operators)
+
+	// DLQ: просмотр и повторная отправка сообщений, которые не удалось обработать
+	deadLetterHandler := handler.NewDeadLetterHandler(service.NewDeadLetterService(deadLetterRepo, kafkaWriter))
+	e.GET("/admin/dead-letters", deadLetterHandler.List, authMid, admins)
+	e.GET("/admin/dead-letters/:id", deadLetterHandler.Get, authMid, admins)
+	e.POST("/admin/dead-letters/:id/replay", deadLetterHandler.Replay, authMid, admins)
+
 	// Запуск в горутинах
 	go func() {
 		if err := e.Start(":8083"); err != nil && err != http.ErrServerClosed {
@@ -103,7 +234,13 @@ func main() {
 		}
 	}()
 
-	go kafkaConsumer.Start(ctx)
+	// consumerCtx останавливает чтение Kafka; прочитанное дорабатывается в Shutdown
+	consumerCtx, stopConsumers := context.WithCancel(ctx)
+	go kafkaConsumer.Start(consumerCtx)
+	go dlqArchiver.Start(consumerCtx)
+	go reconciler.Run(ctx)
+	go outboxPublisher.Run(ctx)
+	go service.NewReservationSweeper(repo, cfg.ReservationSweepInterval, cfg.ReservationSweepBatch, cfg.ProcessedEventsRetention).Run(ctx)
 
 	log.Println("Inventory service started")
 
@@ -111,11 +248,20 @@ func main() {
 	<-sigCh
 	log.Println("Shutting down...")
 
-	// Закрытие consumer
-	kafkaConsumer.Close()
+	// Закрытие consumer: дождаться обработки и коммита прочитанных сообщений.
+	// У каждого шага свой таймаут: долгий дренаж не съедает время HTTP сервера
+	stopConsumers()
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelDrain()
+	if err := kafkaConsumer.Shutdown(drainCtx); err != nil {
+		log.Printf("Kafka consumer shutdown: %v", err)
+	}
+	dlqArchiver.Close()
 
 	// Закрытие HTTP сервера
-	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancelShutdown()
-	e.Shutdown(ctxShutdown)
+	httpCtx, cancelHTTP := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelHTTP()
+	if err := e.Shutdown(httpCtx); err != nil {
+		log.Printf("HTTP server shutdown: %v", err)
+	}
 }
@@ -0,0 +1,164 @@
+// internal/allocation/allocation.go
+
+// Package allocation выбирает склады, с которых собирается заказ.
+//
+// Стратегия получает позиции заказа и свободный остаток товаров на активных складах
+// (строки уже заблокированы в транзакции удержания) и раскладывает каждую позицию
+// по складам. Если товара не хватает даже на всех складах, возвращается ErrUnavailable.
+package allocation
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"inventory-service/internal/model"
+)
+
+// Стратегии распределения (ALLOCATION_STRATEGY).
+const (
+	StrategySingle  = "single"  // весь заказ с одного склада, если получится; иначе split
+	StrategySplit   = "split"   // каждая позиция со складов по приоритету, сколько где есть
+	StrategyNearest = "nearest" // как single, но сначала склады региона доставки
+)
+
+var ErrUnavailable = errors.New("not enough stock in active warehouses")
+
+// Request — заказ, который нужно разложить по складам.
+type Request struct {
+	OrderID int64
+	Region  string            // регион доставки, может быть пустым
+	Lines   []model.StockLine // по одной на товар, без склада
+}
+
+// Candidate — свободный остаток товара на активном складе.
+type Candidate struct {
+	Warehouse model.Warehouse
+	ProductID int64
+	Available int
+}
+
+type Strategy interface {
+	// Allocate returns the order lines with a warehouse each; a product may be
+	// split into several lines from different warehouses.
+	Allocate(req Request, candidates []Candidate) ([]model.StockLine, error)
+}
+
+// New returns the strategy by its ALLOCATION_STRATEGY name.
+func New(name string) (Strategy, error) {
+	switch name {
+	case StrategySingle:
+		return Single{}, nil
+	case StrategySplit:
+		return Split{}, nil
+	case StrategyNearest:
+		return Nearest{}, nil
+	default:
+		return nil, fmt.Errorf("unknown allocation strategy %q", name)
+	}
+}
+
+// Single собирает заказ с одного склада — первого по приоритету, где хватает всех
+// позиций. Если такого нет, заказ делится между складами, как в Split.
+type Single struct{}
+
+func (Single) Allocate(req Request, candidates []Candidate) ([]model.StockLine, error) {
+	return singleOrSplit(req, newStockMap(candidates, byPriority))
+}
+
+// Split берёт каждую позицию со складов по приоритету: сколько есть на первом,
+// остальное со следующих.
+type Split struct{}
+
+func (Split) Allocate(req Request, candidates []Candidate) ([]model.StockLine, error) {
+	return split(req, newStockMap(candidates, byPriority))
+}
+
+// Nearest сначала пробует склады региона доставки, затем остальные; внутри —
+// по приоритету. Заказ по возможности собирается с одного склада.
+type Nearest struct{}
+
+func (Nearest) Allocate(req Request, candidates []Candidate) ([]model.StockLine, error) {
+	nearest := func(a, b model.Warehouse) int {
+		if sameA, sameB := a.Region == req.Region, b.Region == req.Region; sameA != sameB && req.Region != "" {
+			if sameA {
+				return -1
+			}
+			return 1
+		}
+		return byPriority(a, b)
+	}
+	return singleOrSplit(req, newStockMap(candidates, nearest))
+}
+
+func byPriority(a, b model.Warehouse) int {
+	return cmp.Or(cmp.Compare(a.Priority, b.Priority), cmp.Compare(a.Code, b.Code))
+}
+
+// stockMap — свободный остаток по складам в порядке предпочтения.
+type stockMap struct {
+	warehouses []string
+	available  map[string]map[int64]int // склад → товар → свободно
+}
+
+func newStockMap(candidates []Candidate, order func(a, b model.Warehouse) int) stockMap {
+	m := stockMap{available: make(map[string]map[int64]int)}
+	var warehouses []model.Warehouse
+	for _, c := range candidates {
+		byProduct, ok := m.available[c.Warehouse.Code]
+		if !ok {
+			byProduct = make(map[int64]int)
+			m.available[c.Warehouse.Code] = byProduct
+			warehouses = append(warehouses, c.Warehouse)
+		}
+		byProduct[c.ProductID] += max(c.Available, 0)
+	}
+	slices.SortFunc(warehouses, order)
+	for _, w := range warehouses {
+		m.warehouses = append(m.warehouses, w.Code)
+	}
+	return m
+}
+
+func singleOrSplit(req Request, m stockMap) ([]model.StockLine, error) {
+	for _, code := range m.warehouses {
+		fits := true
+		for _, line := range req.Lines {
+			if m.available[code][line.ProductID] < line.Quantity {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			lines := make([]model.StockLine, 0, len(req.Lines))
+			for _, line := range req.Lines {
+				lines = append(lines, model.StockLine{WarehouseCode: code, ProductID: line.ProductID, Quantity: line.Quantity})
+			}
+			return lines, nil
+		}
+	}
+	return split(req, m)
+}
+
+func split(req Request, m stockMap) ([]model.StockLine, error) {
+	var lines []model.StockLine
+	for _, line := range req.Lines {
+		need := line.Quantity
+		for _, code := range m.warehouses {
+			take := min(need, m.available[code][line.ProductID])
+			if take <= 0 {
+				continue
+			}
+			lines = append(lines, model.StockLine{WarehouseCode: code, ProductID: line.ProductID, Quantity: take})
+			need -= take
+			if need == 0 {
+				break
+			}
+		}
+		if need > 0 {
+			return nil, fmt.Errorf("%w: product %d is short by %d", ErrUnavailable, line.ProductID, need)
+		}
+	}
+	return lines, nil
+}
@@ -3,7 +3,9 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -12,10 +14,55 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 	DBName     string
+	// AutoMigrate применяет миграции при старте (см. `inventory-service migrate`).
+	AutoMigrate bool
+
+	JWTSecret string
+
+	// Удержание товара под неоплаченный заказ; не меньше ORDER_PENDING_TTL order-service
+	ReservationTTL           time.Duration
+	ReservationSweepInterval time.Duration
+	ReservationSweepBatch    int
+
+	// Склад для запросов без склада и возвратов, чей склад отгрузки неизвестен
+	DefaultWarehouse string
+	// Как раскладывать заказ по складам: single | split | nearest
+	AllocationStrategy string
+
+	// Как часто сверять stock с журналом stock_movements
+	ReconcileInterval time.Duration
+
+	// CSV-импорт: строк в одной транзакции и всего в файле
+	StockImportChunk   int
+	StockImportMaxRows int
+
+	// Публикация событий inventory.* из outbox_events
+	OutboxPollInterval time.Duration
+	OutboxBatch        int
+
+	// Куда слать алерты low_stock и out_of_stock; пусто — только Kafka
+	AlertWebhookURL     string
+	AlertWebhookTimeout time.Duration
+
+	// Сколько хранить processed_events; дольше, чем Kafka хранит сообщения
+	ProcessedEventsRetention time.Duration
+
+	AdminUserIDs    []int64
+	OperatorUserIDs []int64
 
 	KafkaBrokers []string
 	KafkaGroupID string
-	KafkaTopic   string
+	KafkaTopics  []string
+
+	// Параллельная обработка: воркеров на reader и окно прочитанных, но не обработанных сообщений
+	KafkaWorkers     int
+	KafkaMaxInFlight int
+
+	// Обработка упавших сообщений: попытки в процессе, затем ретрай-топики, затем <topic>.dlq
+	KafkaRetryAttempts   int
+	KafkaRetryBackoff    time.Duration
+	KafkaRetryMaxBackoff time.Duration
+	KafkaRetryDelays     []time.Duration
 
 	OtelExporterURL string
 }
@@ -29,6 +76,15 @@ func Load() *Config {
 		}
 	}
 
+	// order.created — удержание, order.paid — списание, order.cancelled и order.return_received — возврат на склад
+	kafkaTopics := []string{"order.created", "order.paid", "order.cancelled", "order.return_received"}
+	if topics := os.Getenv("KAFKA_TOPICS"); topics != "" {
+		kafkaTopics = []string{}
+		for _, t := range strings.Split(topics, ",") {
+			kafkaTopics = append(kafkaTopics, strings.TrimSpace(t))
+		}
+	}
+
 	return &Config{
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
@@ -36,9 +92,44 @@ func Load() *Config {
 		DBPassword: getEnv("DB_PASSWORD", "inventory_pass"),
 		DBName:     getEnv("DB_NAME", "inventory_db"),
 
+		AutoMigrate: getEnv("AUTO_MIGRATE", "false") == "true",
+
+		JWTSecret: getEnv("JWT_SECRET", "super-secret-jwt-key"),
+
+		ReservationTTL:           getDuration("RESERVATION_TTL", 45*time.Minute),
+		ReservationSweepInterval: getDuration("RESERVATION_SWEEP_INTERVAL", time.Minute),
+		ReservationSweepBatch:    getInt("RESERVATION_SWEEP_BATCH", 100),
+
+		DefaultWarehouse:   getEnv("DEFAULT_WAREHOUSE", "main"),
+		AllocationStrategy: getEnv("ALLOCATION_STRATEGY", "single"),
+
+		ReconcileInterval: getDuration("RECONCILE_INTERVAL", time.Hour),
+
+		StockImportChunk:   getInt("STOCK_IMPORT_CHUNK", 500),
+		StockImportMaxRows: getInt("STOCK_IMPORT_MAX_ROWS", 50000),
+
+		OutboxPollInterval: getDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatch:        getInt("OUTBOX_BATCH", 100),
+
+		AlertWebhookURL:     getEnv("ALERT_WEBHOOK_URL", ""),
+		AlertWebhookTimeout: getDuration("ALERT_WEBHOOK_TIMEOUT", 5*time.Second),
+
+		ProcessedEventsRetention: getDuration("PROCESSED_EVENTS_RETENTION", 30*24*time.Hour),
+
+		AdminUserIDs:    getInt64List("ADMIN_USER_IDS"),
+		OperatorUserIDs: getInt64List("OPERATOR_USER_IDS"),
+
 		KafkaBrokers: kafkaBrokers,
 		KafkaGroupID: getEnv("KAFKA_GROUP_ID", "inventory-group"),
-		KafkaTopic:   getEnv("KAFKA_TOPIC", "order.created"),
+		KafkaTopics:  kafkaTopics,
+
+		KafkaWorkers:     getInt("KAFKA_WORKERS", 8),
+		KafkaMaxInFlight: getInt("KAFKA_MAX_IN_FLIGHT", 256),
+
+		KafkaRetryAttempts:   getInt("KAFKA_RETRY_ATTEMPTS", 3),
+		KafkaRetryBackoff:    getDuration("KAFKA_RETRY_BACKOFF", 200*time.Millisecond),
+		KafkaRetryMaxBackoff: getDuration("KAFKA_RETRY_MAX_BACKOFF", 5*time.Second),
+		KafkaRetryDelays:     getDurationList("KAFKA_RETRY_DELAYS", []time.Duration{time.Minute, 10 * time.Minute}),
 
 		OtelExporterURL: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
 	}
@@ -50,3 +141,53 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+func getInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+// getDurationList parses a comma-separated list like "1m,10m". "none" means an empty
+// list; a malformed value falls back.
+func getDurationList(key string, fallback []time.Duration) []time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	if value == "none" {
+		return nil
+	}
+	var list []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return fallback
+		}
+		list = append(list, d)
+	}
+	return list
+}
+
+// getInt64List parses a comma-separated list of IDs; malformed entries are skipped.
+func getInt64List(key string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
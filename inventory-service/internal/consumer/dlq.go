@@ -0,0 +1,99 @@
+// internal/consumer/dlq.go
+package consumer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+
+	"contracts/events"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// DeadLetterArchiver сохраняет сообщения из <topic>.dlq в dead_letters,
+// где их смотрит и переотправляет админ (GET /admin/dead-letters).
+type DeadLetterArchiver struct {
+	reader *kafka.Reader
+	repo   *repository.DeadLetterRepository
+	retry  RetryPolicy
+}
+
+func NewDeadLetterArchiver(brokers []string, groupID string, topics []string, repo *repository.DeadLetterRepository, retry RetryPolicy) *DeadLetterArchiver {
+	dlqTopics := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		dlqTopics = append(dlqTopics, DLQTopic(topic))
+	}
+	return &DeadLetterArchiver{reader: newReader(brokers, groupID+".dlq", dlqTopics), repo: repo, retry: retry}
+}
+
+// Start archives DLQ messages until ctx is cancelled or the archiver is closed.
+// An offset is committed only after the message is saved.
+func (a *DeadLetterArchiver) Start(ctx context.Context) {
+	failures := 0
+	for {
+		msg, err := a.reader.FetchMessage(ctx)
+		if err == nil {
+			err = a.repo.Save(ctx, deadLetter(msg))
+			if err == nil {
+				err = a.reader.CommitMessages(ctx, msg)
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			failures++
+			log.Printf("Failed to archive DLQ message: %v", err)
+			if !sleep(ctx, a.retry.backoff(failures)) {
+				return
+			}
+			continue
+		}
+		failures = 0
+		log.Printf("Dead letter archived: topic=%s offset=%d", msg.Topic, msg.Offset)
+	}
+}
+
+func (a *DeadLetterArchiver) Close() error {
+	return a.reader.Close()
+}
+
+// deadLetter splits a DLQ message into the original message and the failure details.
+func deadLetter(msg kafka.Message) *model.DeadLetter {
+	partition, _ := strconv.Atoi(events.Header(msg, HeaderOriginalPartition))
+	offset, _ := strconv.ParseInt(events.Header(msg, HeaderOriginalOffset), 10, 64)
+	failedAt, err := time.Parse(time.RFC3339, events.Header(msg, HeaderFailedAt))
+	if err != nil {
+		failedAt = msg.Time
+	}
+
+	headers := []model.MessageHeader{}
+	for _, h := range msg.Headers {
+		if !isFailureHeader(h.Key) {
+			headers = append(headers, model.MessageHeader{Key: h.Key, Value: string(h.Value)})
+		}
+	}
+
+	return &model.DeadLetter{
+		Topic:        originalTopic(msg),
+		Partition:    partition,
+		Offset:       offset,
+		Key:          msg.Key,
+		Value:        msg.Value,
+		Headers:      headers,
+		Error:        events.Header(msg, HeaderError),
+		ErrorClass:   events.Header(msg, HeaderErrorClass),
+		Attempts:     previousAttempts(msg),
+		FailedAt:     failedAt.UTC(),
+		DLQTopic:     msg.Topic,
+		DLQPartition: msg.Partition,
+		DLQOffset:    msg.Offset,
+	}
+}
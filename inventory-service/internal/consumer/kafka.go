@@ -3,47 +3,342 @@ package consumer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"strconv"
+	"sync"
+	"time"
 
+	"inventory-service/internal/model"
 	"inventory-service/internal/service"
 
+	"contracts/events"
+
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "inventory-service.kafka"
+
+// KafkaConsumer читает топики заказов и по ретрай-топику на каждую задержку из RetryPolicy.
+// Offset коммитится только после обработки сообщения (или его переноса в ретрай-топик/DLQ),
+// поэтому после падения сообщение придёт ещё раз — повтор отсеют processed_events.
 type KafkaConsumer struct {
-	reader *kafka.Reader
-	svc    *service.InventoryService
+	// readers[0] — исходные топики, readers[i] — ретрай-топики с задержкой retry.Delays[i-1]
+	readers []*kafka.Reader
+	groupID string
+	svc     *service.InventoryService
+	writer  *kafka.Writer // публикует в ретрай-топики и DLQ
+	retry   RetryPolicy
+	conc    Concurrency
+
+	// work — контекст обработки: при остановке чтения уже прочитанное дорабатывается,
+	// abort прерывает обработку, если Shutdown не уложился в свой таймаут
+	work  context.Context
+	abort context.CancelFunc
+	done  chan struct{}
+
+	// duplicates — повторные доставки, пропущенные благодаря processed_events
+	duplicates metric.Int64Counter
 }
 
-func NewKafkaConsumer(brokers []string, groupID, topic string, svc *service.InventoryService) *KafkaConsumer {
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  brokers,
-		GroupID:  groupID,
-		Topic:    topic,
-		MinBytes: 10e3, // 10KB
-		MaxBytes: 10e6, // 10MB
+// Concurrency — параллельная обработка. Сообщения с общим товаром или заказом
+// (routingKeys) обрабатываются в порядке чтения, остальные — параллельно.
+type Concurrency struct {
+	Workers     int // воркеров на каждый reader
+	MaxInFlight int // прочитано, но ещё не обработано; когда окно заполнено, чтение ждёт
+}
+
+func NewKafkaConsumer(brokers []string, groupID string, topics []string, svc *service.InventoryService, writer *kafka.Writer, retry RetryPolicy, conc Concurrency) *KafkaConsumer {
+	readers := []*kafka.Reader{newReader(brokers, groupID, topics)}
+	for _, delay := range retry.Delays {
+		retryTopics := make([]string, 0, len(topics))
+		for _, topic := range topics {
+			retryTopics = append(retryTopics, RetryTopic(topic, delay))
+		}
+		readers = append(readers, newReader(brokers, groupID+".retry."+shortDuration(delay), retryTopics))
+	}
+	conc.Workers = max(conc.Workers, 1)
+	conc.MaxInFlight = max(conc.MaxInFlight, conc.Workers)
+
+	duplicates, err := otel.Meter(tracerName).Int64Counter("inventory.kafka.duplicate_events",
+		metric.WithDescription("Redelivered Kafka messages skipped because the event was already processed"),
+		metric.WithUnit("{message}"))
+	if err != nil {
+		log.Printf("Failed to create duplicate events counter: %v", err)
+	}
+
+	work, abort := context.WithCancel(context.Background())
+	return &KafkaConsumer{
+		readers: readers, groupID: groupID, svc: svc, writer: writer, retry: retry, conc: conc,
+		work: work, abort: abort, done: make(chan struct{}),
+		duplicates: duplicates,
+	}
+}
+
+func newReader(brokers []string, groupID string, topics []string) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupID:     groupID,
+		GroupTopics: topics,
+		MinBytes:    10e3, // 10KB
+		MaxBytes:    10e6, // 10MB
+		// CommitMessages копит offset'ы и отправляет их раз в секунду; Close досылает остаток
+		CommitInterval: time.Second,
 	})
-	return &KafkaConsumer{reader: reader, svc: svc}
 }
 
+// Start reads all topics until ctx is cancelled, then waits for the messages
+// already read to be handled. Use Shutdown to wait for it.
 func (c *KafkaConsumer) Start(ctx context.Context) {
 	log.Println("Starting Kafka consumer...")
+	defer close(c.done)
+
+	var wg sync.WaitGroup
+	for tier, reader := range c.readers {
+		p := newPipeline(c, reader, tier)
+		wg.Go(func() { p.run(ctx, c.work) })
+	}
+	wg.Wait()
+	log.Println("Kafka consumer stopped")
+}
+
+// Shutdown waits for Start to drain after its context is cancelled and closes the readers,
+// which sends the last commits. If ctx expires first, handling is interrupted: unfinished
+// messages stay uncommitted and are redelivered after the restart.
+func (c *KafkaConsumer) Shutdown(ctx context.Context) error {
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		log.Println("Kafka consumer did not drain in time, aborting in-flight messages")
+		c.abort()
+		<-c.done
+	}
+	return c.Close()
+}
+
+// pipeline — один reader: сообщения обрабатываются параллельно, в порядке чтения
+// внутри своих ключей; offset партиции коммитится, когда обработаны все сообщения до него.
+type pipeline struct {
+	c       *KafkaConsumer
+	reader  *kafka.Reader
+	tier    int
+	order   *keyOrder
+	workers chan struct{} // одновременно обрабатываемые сообщения
+	slots   chan struct{} // окно in-flight
+	offsets *offsetTracker
+}
+
+func newPipeline(c *KafkaConsumer, reader *kafka.Reader, tier int) *pipeline {
+	return &pipeline{
+		c: c, reader: reader, tier: tier,
+		order:   newKeyOrder(),
+		workers: make(chan struct{}, c.conc.Workers),
+		slots:   make(chan struct{}, c.conc.MaxInFlight),
+		offsets: newOffsetTracker(),
+	}
+}
+
+func (p *pipeline) run(fetchCtx, workCtx context.Context) {
+	var wg sync.WaitGroup
+	p.fetch(fetchCtx, func(msg kafka.Message) {
+		after, release := p.order.enqueue(routingKeys(msg))
+		wg.Go(func() {
+			defer release()
+			// Очередь по ключам ждём и при остановке: прочитанное дорабатывается по порядку
+			for _, prev := range after {
+				<-prev
+			}
+			p.workers <- struct{}{}
+			defer func() { <-p.workers }()
+			p.handle(workCtx, msg)
+		})
+	})
+	wg.Wait()
+}
+
+func (p *pipeline) fetch(ctx context.Context, dispatch func(kafka.Message)) {
+	failures := 0
 	for {
-		msg, err := c.reader.ReadMessage(ctx)
+		// Backpressure: пока окно занято, новые сообщения не читаем
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		msg, err := p.reader.FetchMessage(ctx)
 		if err != nil {
+			<-p.slots
+			// io.EOF — reader закрыт через Close
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			failures++
 			log.Printf("Error reading message: %v", err)
+			if !sleep(ctx, p.c.retry.backoff(failures)) {
+				return
+			}
 			continue
 		}
+		failures = 0
+
+		if p.tier > 0 {
+			// Ретрай-топик: ждём, пока с публикации пройдёт его задержка.
+			// При остановке сообщение не закоммичено и будет прочитано снова.
+			if !sleep(ctx, time.Until(msg.Time.Add(p.c.retry.Delays[p.tier-1]))) {
+				<-p.slots
+				return
+			}
+		}
+
+		p.offsets.fetched(msg)
+		dispatch(msg)
+	}
+}
+
+func (p *pipeline) handle(ctx context.Context, msg kafka.Message) {
+	defer func() { <-p.slots }()
+
+	if !p.c.process(ctx, msg, p.tier) {
+		return
+	}
+	if commit, ok := p.offsets.done(msg); ok {
+		if err := p.reader.CommitMessages(ctx, commit); err != nil {
+			log.Printf("Failed to commit offset: topic=%s partition=%d offset=%d: %v", commit.Topic, commit.Partition, commit.Offset, err)
+		}
+	}
+}
+
+// process continues the producer's trace from the message headers and wraps handling
+// in a consumer span. It reports whether the offset can be committed: the message was
+// handled or moved on to a retry topic or the DLQ. false means ctx was cancelled.
+func (c *KafkaConsumer) process(ctx context.Context, msg kafka.Message, tier int) bool {
+	topic := originalTopic(msg)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, events.NewHeaderCarrier(&msg))
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "process "+topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingOperationName("process"),
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+			semconv.MessagingKafkaOffset(int(msg.Offset)),
+			semconv.MessagingKafkaMessageKey(string(msg.Key)),
+			semconv.MessagingConsumerGroupName(c.groupID),
+			semconv.MessagingMessageBodySize(len(msg.Value)),
+			attribute.Int("inventory.retry_tier", tier),
+		),
+	)
+	defer span.End()
+
+	attempts := previousAttempts(msg)
+	var err error
+	for n := 1; ; n++ {
+		err = c.handle(ctx, topic, msg)
+		attempts++
+		if err == nil || errors.Is(err, service.ErrDuplicateEvent) ||
+			errorClass(err) == model.ErrorClassPermanent || n >= c.retry.Attempts {
+			break
+		}
+		log.Printf("Retrying message: topic=%s offset=%d attempt=%d: %v", msg.Topic, msg.Offset, n, err)
+		if !sleep(ctx, c.retry.backoff(n)) {
+			return false
+		}
+	}
+	span.SetAttributes(attribute.Int("inventory.attempts", attempts))
+
+	if errors.Is(err, service.ErrDuplicateEvent) {
+		log.Printf("Duplicate message skipped: topic=%s offset=%d", msg.Topic, msg.Offset)
+		span.SetAttributes(attribute.Bool("inventory.duplicate", true))
+		if c.duplicates != nil {
+			c.duplicates.Add(ctx, 1, metric.WithAttributes(semconv.MessagingDestinationName(topic)))
+		}
+		return true
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		log.Printf("Error handling message: topic=%s offset=%d: %v", msg.Topic, msg.Offset, err)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return c.forward(ctx, msg, tier, attempts, err)
+	}
+	log.Printf("Message processed: topic=%s offset=%d", msg.Topic, msg.Offset)
+	return true
+}
 
-		if err := c.svc.HandleOrderEvent(ctx, msg.Value); err != nil {
-			log.Printf("Error handling message: %v", err)
-			// В продакшене: логика повтора или DLQ
-		} else {
-			log.Printf("Message processed: offset=%d", msg.Offset)
+// handle decodes the message according to its original topic and passes it to the service.
+func (c *KafkaConsumer) handle(ctx context.Context, topic string, msg kafka.Message) error {
+	switch topic {
+	case events.TopicOrderCreated:
+		event, err := events.DecodeOrderCreated(msg)
+		if err != nil {
+			return err
+		}
+		return c.svc.HandleOrderEvent(ctx, event)
+	case events.TopicOrderPaid:
+		event, err := events.DecodeOrderPaid(msg)
+		if err != nil {
+			return err
+		}
+		return c.svc.HandleOrderPaid(ctx, event)
+	case events.TopicOrderCancelled:
+		event, err := events.DecodeOrderCancelled(msg)
+		if err != nil {
+			return err
+		}
+		return c.svc.HandleOrderCancelled(ctx, event)
+	case events.TopicOrderReturnReceived:
+		event, err := events.DecodeOrderReturnReceived(msg)
+		if err != nil {
+			return err
 		}
+		return c.svc.HandleReturnReceived(ctx, event)
+	default:
+		return fmt.Errorf("%w %q", errUnexpectedTopic, topic)
 	}
 }
 
+// forward moves a failed message to the next retry topic, or to <topic>.dlq after
+// the last one. Permanent errors go to the DLQ right away. Publishing is retried
+// until it succeeds: until then the partition's offset is not committed.
+func (c *KafkaConsumer) forward(ctx context.Context, msg kafka.Message, tier, attempts int, cause error) bool {
+	topic := originalTopic(msg)
+	class := errorClass(cause)
+	dest := DLQTopic(topic)
+	if class == model.ErrorClassRetryable && tier < len(c.retry.Delays) {
+		dest = RetryTopic(topic, c.retry.Delays[tier])
+	}
+
+	out := kafka.Message{Topic: dest, Key: msg.Key, Value: msg.Value, Headers: withFailure(msg, attempts, class, cause)}
+	for n := 1; ; n++ {
+		err := c.writer.WriteMessages(ctx, out)
+		if err == nil {
+			break
+		}
+		log.Printf("Failed to move message to %s: topic=%s offset=%d: %v", dest, msg.Topic, msg.Offset, err)
+		if !sleep(ctx, c.retry.backoff(n)) {
+			return false
+		}
+	}
+	log.Printf("Message moved to %s: topic=%s offset=%d attempts=%d", dest, msg.Topic, msg.Offset, attempts)
+	return true
+}
+
 func (c *KafkaConsumer) Close() error {
-	return c.reader.Close()
+	var errs []error
+	for _, reader := range c.readers {
+		errs = append(errs, reader.Close())
+	}
+	return errors.Join(errs...)
 }
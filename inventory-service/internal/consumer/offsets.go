@@ -0,0 +1,71 @@
+// internal/consumer/offsets.go
+package consumer
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type partitionKey struct {
+	topic     string
+	partition int
+}
+
+// offsetTracker решает, какой offset можно коммитить, когда сообщения одной партиции
+// обрабатываются параллельно: только тот, до которого обработано всё.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[partitionKey]*partitionOffsets
+}
+
+type partitionOffsets struct {
+	pending []int64 // в порядке чтения, то есть по возрастанию
+	done    map[int64]kafka.Message
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[partitionKey]*partitionOffsets)}
+}
+
+// fetched registers a message before it is handed to a worker. An offset that is not
+// above the last one means the partition is read again after a rebalance: the old
+// state is dropped, so results of messages from before it are ignored.
+func (t *offsetTracker) fetched(msg kafka.Message) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := partitionKey{msg.Topic, msg.Partition}
+	p, ok := t.partitions[key]
+	if !ok || (len(p.pending) > 0 && msg.Offset <= p.pending[len(p.pending)-1]) {
+		p = &partitionOffsets{done: make(map[int64]kafka.Message)}
+		t.partitions[key] = p
+	}
+	p.pending = append(p.pending, msg.Offset)
+}
+
+// done marks the message handled and returns the last message of the partition
+// that can be committed, if the handled prefix has grown.
+func (t *offsetTracker) done(msg kafka.Message) (kafka.Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	p, ok := t.partitions[partitionKey{msg.Topic, msg.Partition}]
+	if !ok || len(p.pending) == 0 || msg.Offset < p.pending[0] {
+		return kafka.Message{}, false
+	}
+	p.done[msg.Offset] = msg
+
+	var commit kafka.Message
+	committable := false
+	for len(p.pending) > 0 {
+		m, ok := p.done[p.pending[0]]
+		if !ok {
+			break
+		}
+		delete(p.done, p.pending[0])
+		p.pending = p.pending[1:]
+		commit, committable = m, true
+	}
+	return commit, committable
+}
@@ -0,0 +1,152 @@
+// internal/consumer/retry.go
+package consumer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/service"
+
+	"contracts/events"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Заголовки, которые consumer добавляет к сообщению при переносе в ретрай-топик или DLQ.
+// Исходные заголовки (trace context, content-type, schema-version) сохраняются как есть.
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderAttempts          = "x-attempts" // сколько раз сообщение пытались обработать
+	HeaderError             = "x-error"
+	HeaderErrorClass        = "x-error-class" // retryable | permanent
+	HeaderFailedAt          = "x-failed-at"   // RFC 3339, последняя неудача
+)
+
+var failureHeaders = []string{
+	HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset,
+	HeaderAttempts, HeaderError, HeaderErrorClass, HeaderFailedAt,
+}
+
+// RetryPolicy — что делать с сообщением, которое не удалось обработать.
+// Сначала Attempts попыток в процессе с экспоненциальной паузой, затем по очереди
+// ретрай-топики с задержками Delays (order.created.retry.1m, ...), затем <topic>.dlq.
+type RetryPolicy struct {
+	Attempts   int           // попыток в процессе на каждом уровне, включая первую
+	Backoff    time.Duration // пауза перед второй попыткой, дальше удваивается
+	MaxBackoff time.Duration
+	Delays     []time.Duration
+}
+
+// backoff returns the pause after the n-th failed attempt (n >= 1).
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, p.MaxBackoff)
+}
+
+// RetryTopic: order.created, 1m → order.created.retry.1m.
+func RetryTopic(topic string, delay time.Duration) string {
+	return topic + ".retry." + shortDuration(delay)
+}
+
+func DLQTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+// shortDuration drops zero units from the end: 1m0s → 1m, 1h0m0s → 1h, 1h30m0s → 1h30m.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+var errUnexpectedTopic = errors.New("unexpected topic")
+
+// errorClass: permanent — сообщение не обработается никогда (битое, неизвестная схема,
+// событие без товаров), такие сразу уходят в DLQ. Остальное (недоступна БД) повторяется.
+// Нехватка товара для нового заказа или для оплаченного с истёкшим удержанием сюда
+// не доходит: заказ отклоняется (RejectOrder).
+// order.paid/order.cancelled, обогнавшие свой order.created (ErrOrderNotReserved), повторяются.
+func errorClass(err error) string {
+	switch {
+	case errors.Is(err, events.ErrMalformedMessage),
+		errors.Is(err, events.ErrUnsupportedSchema),
+		errors.Is(err, service.ErrInvalidEvent),
+		errors.Is(err, errUnexpectedTopic):
+		return model.ErrorClassPermanent
+	default:
+		return model.ErrorClassRetryable
+	}
+}
+
+// originalTopic — топик, в который сообщение опубликовал order-service.
+func originalTopic(msg kafka.Message) string {
+	if topic := events.Header(msg, HeaderOriginalTopic); topic != "" {
+		return topic
+	}
+	return msg.Topic
+}
+
+// previousAttempts — сколько попыток было до ретрай-топика; 0 для нового сообщения.
+func previousAttempts(msg kafka.Message) int {
+	n, _ := strconv.Atoi(events.Header(msg, HeaderAttempts))
+	return n
+}
+
+// withFailure returns the headers of msg with the failure details replaced.
+// The x-original-* headers are set on the first failure and kept afterwards.
+func withFailure(msg kafka.Message, attempts int, class string, cause error) []kafka.Header {
+	headers := []kafka.Header{
+		{Key: HeaderOriginalTopic, Value: []byte(originalTopic(msg))},
+		{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+	}
+	if events.Header(msg, HeaderOriginalTopic) != "" {
+		headers = headers[:0]
+		for _, key := range []string{HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset} {
+			headers = append(headers, kafka.Header{Key: key, Value: []byte(events.Header(msg, key))})
+		}
+	}
+
+	for _, h := range msg.Headers {
+		if !isFailureHeader(h.Key) {
+			headers = append(headers, h)
+		}
+	}
+	return append(headers,
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderErrorClass, Value: []byte(class)},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+}
+
+func isFailureHeader(key string) bool {
+	return slices.Contains(failureHeaders, key)
+}
+
+// sleep waits for d; false if ctx was cancelled first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,100 @@
+// internal/consumer/routing.go
+package consumer
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+
+	"contracts/events"
+	ordersv1 "contracts/gen/orders/v1"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// routingKeys — ключи, в пределах которых сохраняется порядок обработки: каждый
+// товар сообщения и его заказ. Товар — чтобы события, меняющие остаток одного
+// товара, применялись в порядке чтения; заказ — чтобы order.paid (в нём нет
+// позиций) не обгонял order.created того же заказа. Сообщение, которое не
+// удалось разобрать, упорядочивается по ключу Kafka, а без ключа — по партиции.
+func routingKeys(msg kafka.Message) []string {
+	var (
+		orderID int64
+		items   []*ordersv1.OrderItem
+	)
+	switch originalTopic(msg) {
+	case events.TopicOrderCreated:
+		if event, err := events.DecodeOrderCreated(msg); err == nil {
+			orderID, items = event.GetOrderId(), event.GetItems()
+		}
+	case events.TopicOrderPaid:
+		if event, err := events.DecodeOrderPaid(msg); err == nil {
+			orderID = event.GetOrderId()
+		}
+	case events.TopicOrderCancelled:
+		if event, err := events.DecodeOrderCancelled(msg); err == nil {
+			orderID, items = event.GetOrderId(), event.GetItems()
+		}
+	case events.TopicOrderReturnReceived:
+		if event, err := events.DecodeOrderReturnReceived(msg); err == nil {
+			orderID, items = event.GetOrderId(), event.GetItems()
+		}
+	}
+
+	if orderID == 0 {
+		if len(msg.Key) > 0 {
+			return []string{"key:" + string(msg.Key)}
+		}
+		return []string{"partition:" + msg.Topic + ":" + strconv.Itoa(msg.Partition)}
+	}
+
+	keys := make([]string, 0, len(items)+1)
+	keys = append(keys, "order:"+strconv.FormatInt(orderID, 10))
+	for _, item := range items {
+		keys = append(keys, "product:"+strconv.FormatInt(item.GetProductId(), 10))
+	}
+	return keys
+}
+
+// keyOrder выстраивает сообщения с общими ключами в очередь: сообщение ждёт
+// все прочитанные раньше него сообщения, у которых есть хоть один общий ключ.
+// Сообщения без общих ключей не ждут друг друга.
+type keyOrder struct {
+	mu    sync.Mutex
+	tails map[string]chan struct{} // последнее сообщение по ключу; закрыт, когда оно обработано
+}
+
+func newKeyOrder() *keyOrder {
+	return &keyOrder{tails: make(map[string]chan struct{})}
+}
+
+// enqueue ставит сообщение с ключами keys в конец очередей. Вызывающий ждёт
+// каналы из after, обрабатывает сообщение и вызывает release.
+func (o *keyOrder) enqueue(keys []string) (after []chan struct{}, release func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	done := make(chan struct{})
+	for _, key := range keys {
+		tail, ok := o.tails[key]
+		// tail == done — ключ повторился в этом же сообщении
+		if ok && tail != done && !slices.Contains(after, tail) {
+			after = append(after, tail)
+		}
+		o.tails[key] = done
+	}
+
+	release = func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+
+		close(done)
+		for _, key := range keys {
+			// Следующего сообщения с этим ключом нет — ключ больше не нужен
+			if o.tails[key] == done {
+				delete(o.tails, key)
+			}
+		}
+	}
+	return after, release
+}
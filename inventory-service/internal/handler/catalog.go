@@ -0,0 +1,180 @@
+// internal/handler/catalog.go
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultProductLimit = 50
+	maxProductLimit     = 200
+)
+
+type CatalogHandler struct {
+	catalogService *service.CatalogService
+}
+
+func NewCatalogHandler(catalogService *service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{catalogService: catalogService}
+}
+
+type productRequest struct {
+	ID          int64  `json:"id"` // только при создании: товар, который уже есть в остатках
+	SKU         string `json:"sku"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Price       int64  `json:"price"`
+	Currency    string `json:"currency"`
+	Active      *bool  `json:"active"` // по умолчанию true
+}
+
+func (r *productRequest) product() *model.Product {
+	p := &model.Product{
+		ID: r.ID, SKU: strings.TrimSpace(r.SKU), Name: strings.TrimSpace(r.Name), Description: r.Description,
+		Price: r.Price, Currency: r.Currency, Active: true,
+	}
+	if r.Active != nil {
+		p.Active = *r.Active
+	}
+	return p
+}
+
+// List: ?ids=1,2,3 — товары по id (отсутствующие — в "missing"), иначе поиск
+// ?q=...&active=true&limit=50&before_id=123 (курсор из next_before_id).
+func (h *CatalogHandler) List(c echo.Context) error {
+	if c.QueryParam("ids") != "" {
+		return h.listByIDs(c)
+	}
+
+	filter := model.ProductFilter{Query: strings.TrimSpace(c.QueryParam("q")), Limit: defaultProductLimit}
+	if len(filter.Query) > 255 {
+		return echo.NewHTTPError(http.StatusBadRequest, "q is too long")
+	}
+	if s := c.QueryParam("active"); s != "" {
+		active, err := strconv.ParseBool(s)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "active must be true or false")
+		}
+		filter.Active = &active
+	}
+	if s := c.QueryParam("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxProductLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxProductLimit))
+		}
+		filter.Limit = limit
+	}
+	if s := c.QueryParam("before_id"); s != "" {
+		var err error
+		if filter.BeforeID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid before_id")
+		}
+	}
+
+	products, err := h.catalogService.Search(c.Request().Context(), filter)
+	if err != nil {
+		return stockError(err)
+	}
+
+	resp := map[string]any{"products": products}
+	if products == nil {
+		resp["products"] = []model.Product{}
+	}
+	if len(products) == filter.Limit {
+		resp["next_before_id"] = products[len(products)-1].ID
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *CatalogHandler) listByIDs(c echo.Context) error {
+	var ids []int64
+	for _, s := range strings.Split(c.QueryParam("ids"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid product id %q", s))
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "ids must not be empty")
+	}
+	if len(ids) > maxBatchIDs {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("at most %d ids per request", maxBatchIDs))
+	}
+
+	products, err := h.catalogService.GetMany(c.Request().Context(), ids)
+	if err != nil {
+		return stockError(err)
+	}
+
+	missing := []int64{}
+	for _, id := range ids {
+		if !slices.ContainsFunc(products, func(p model.Product) bool { return p.ID == id }) {
+			missing = append(missing, id)
+		}
+	}
+	if products == nil {
+		products = []model.Product{}
+	}
+	return c.JSON(http.StatusOK, map[string]any{"products": products, "missing": missing})
+}
+
+func (h *CatalogHandler) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	p, err := h.catalogService.Get(c.Request().Context(), id)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *CatalogHandler) Create(c echo.Context) error {
+	req := new(productRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	p := req.product()
+	if err := h.catalogService.Create(c.Request().Context(), p); err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusCreated, p)
+}
+
+// Update заменяет SKU, название, описание, цену, валюту и признак active товара.
+func (h *CatalogHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	req := new(productRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	p := req.product()
+	p.ID = id
+	if err := h.catalogService.Update(c.Request().Context(), p); err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, p)
+}
@@ -0,0 +1,93 @@
+// internal/handler/dead_letter.go
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultDeadLetterLimit = 50
+	maxDeadLetterLimit     = 200
+)
+
+// DeadLetterHandler — сообщения Kafka, которые не удалось обработать (админы).
+type DeadLetterHandler struct {
+	dlService *service.DeadLetterService
+}
+
+func NewDeadLetterHandler(dlService *service.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{dlService: dlService}
+}
+
+// List: ?topic=order.created&replayed=false&limit=50&before_id=123 (курсор из next_before_id).
+func (h *DeadLetterHandler) List(c echo.Context) error {
+	filter := model.DeadLetterFilter{Topic: c.QueryParam("topic"), Limit: defaultDeadLetterLimit}
+	if s := c.QueryParam("replayed"); s != "" {
+		replayed, err := strconv.ParseBool(s)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "replayed must be true or false")
+		}
+		filter.Replayed = &replayed
+	}
+	if s := c.QueryParam("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxDeadLetterLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 200")
+		}
+		filter.Limit = limit
+	}
+	if s := c.QueryParam("before_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid before_id")
+		}
+		filter.BeforeID = id
+	}
+
+	letters, err := h.dlService.List(c.Request().Context(), filter)
+	if err != nil {
+		return stockError(err)
+	}
+
+	resp := map[string]any{"dead_letters": letters}
+	if letters == nil {
+		resp["dead_letters"] = []model.DeadLetter{}
+	}
+	if len(letters) == filter.Limit {
+		resp["next_before_id"] = letters[len(letters)-1].ID
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (h *DeadLetterHandler) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	dl, err := h.dlService.Get(c.Request().Context(), id)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, dl)
+}
+
+// Replay отправляет сообщение в исходный топик ещё раз, например после исправления данных.
+func (h *DeadLetterHandler) Replay(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	dl, err := h.dlService.Replay(c.Request().Context(), id)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, dl)
+}
@@ -0,0 +1,31 @@
+// internal/handler/reconciliation.go
+package handler
+
+import (
+	"net/http"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReconciliationHandler — сверка остатков с журналом движений по запросу (админы).
+type ReconciliationHandler struct {
+	reconciler *service.StockReconciler
+}
+
+func NewReconciliationHandler(reconciler *service.StockReconciler) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciler: reconciler}
+}
+
+func (h *ReconciliationHandler) Run(c echo.Context) error {
+	found, err := h.reconciler.Reconcile(c.Request().Context())
+	if err != nil {
+		return stockError(err)
+	}
+	if found == nil {
+		found = []model.StockDiscrepancy{}
+	}
+	return c.JSON(http.StatusOK, map[string]any{"discrepancies": found})
+}
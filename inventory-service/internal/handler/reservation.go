@@ -0,0 +1,49 @@
+// internal/handler/reservation.go
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"inventory-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ReservationHandler — удержания товара под заказы (операторы склада).
+type ReservationHandler struct {
+	invService *service.InventoryService
+}
+
+func NewReservationHandler(invService *service.InventoryService) *ReservationHandler {
+	return &ReservationHandler{invService: invService}
+}
+
+func (h *ReservationHandler) List(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("orderId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	reservations, err := h.invService.OrderReservations(c.Request().Context(), orderID)
+	if err != nil {
+		return stockError(err)
+	}
+	if len(reservations) == 0 {
+		return echo.ErrNotFound
+	}
+	return c.JSON(http.StatusOK, reservations)
+}
+
+// Confirm списывает удержанный товар, не дожидаясь order.paid.
+func (h *ReservationHandler) Confirm(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("orderId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	if _, err := h.invService.ConfirmOrder(c.Request().Context(), orderID); err != nil {
+		return stockError(err)
+	}
+	return h.List(c)
+}
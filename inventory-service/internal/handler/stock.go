@@ -0,0 +1,330 @@
+// internal/handler/stock.go
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+	"inventory-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxBatchIDs — сколько товаров можно запросить одним GET /stock?ids=.
+const maxBatchIDs = 200
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 500
+)
+
+type StockHandler struct {
+	stockService *service.StockService
+}
+
+func NewStockHandler(stockService *service.StockService) *StockHandler {
+	return &StockHandler{stockService: stockService}
+}
+
+type adjustRequest struct {
+	Warehouse string `json:"warehouse"` // пусто — склад по умолчанию (DEFAULT_WAREHOUSE)
+	Type      string `json:"type"`      // receive | damage | correction
+	Quantity  int    `json:"quantity"`  // для correction — дельта со знаком
+	Reason    string `json:"reason"`
+	Note      string `json:"note"`
+}
+
+type setStockRequest struct {
+	Warehouse string `json:"warehouse"`
+	Quantity  int    `json:"quantity"`
+	Version   int64  `json:"version"` // версия из GET; 0 — товар ещё не заведён
+	Reason    string `json:"reason"`
+	Note      string `json:"note"`
+}
+
+type transferRequest struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Quantity int    `json:"quantity"`
+	Reason   string `json:"reason"`
+	Note     string `json:"note"`
+}
+
+type transferResponse struct {
+	From     *model.Stock         `json:"from"`
+	To       *model.Stock         `json:"to"`
+	Transfer *model.StockTransfer `json:"transfer"`
+}
+
+type thresholdRequest struct {
+	LowStock int `json:"low_stock"`
+}
+
+type adjustResponse struct {
+	Stock      *model.Stock           `json:"stock"`
+	Adjustment *model.StockAdjustment `json:"adjustment"`
+}
+
+func (h *StockHandler) Get(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	// ?as_of=2025-10-01T00:00:00Z — остаток на момент времени, восстановленный по журналу движений
+	if s := c.QueryParam("as_of"); s != "" {
+		at, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "as_of must be an RFC 3339 timestamp")
+		}
+		stock, err := h.stockService.AsOf(c.Request().Context(), productID, at)
+		if err != nil {
+			return stockError(err)
+		}
+		return c.JSON(http.StatusOK, stock)
+	}
+
+	stock, err := h.stockService.Get(c.Request().Context(), productID)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, stock)
+}
+
+// History: ?warehouse=main&from=...&to=...&limit=50&before_id=123 (курсор из next_before_id).
+// У каждого движения — остаток склада сразу после него.
+func (h *StockHandler) History(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	filter := model.StockHistoryFilter{ProductID: productID, WarehouseCode: c.QueryParam("warehouse"), Limit: defaultHistoryLimit}
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if s := c.QueryParam(name); s != "" {
+			if *dst, err = time.Parse(time.RFC3339, s); err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, name+" must be an RFC 3339 timestamp")
+			}
+		}
+	}
+	if s := c.QueryParam("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxHistoryLimit {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit))
+		}
+		filter.Limit = limit
+	}
+	if s := c.QueryParam("before_id"); s != "" {
+		if filter.BeforeID, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid before_id")
+		}
+	}
+
+	entries, err := h.stockService.History(c.Request().Context(), filter)
+	if err != nil {
+		return stockError(err)
+	}
+
+	resp := map[string]any{"movements": entries}
+	if entries == nil {
+		resp["movements"] = []model.StockHistoryEntry{}
+	}
+	if len(entries) == filter.Limit {
+		resp["next_before_id"] = entries[len(entries)-1].ID
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// List returns stock for ?ids=1,2,3 with per-warehouse rows. Products that are not
+// stocked anywhere are listed in "missing".
+func (h *StockHandler) List(c echo.Context) error {
+	var productIDs []int64
+	for _, s := range strings.Split(c.QueryParam("ids"), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid product id %q", s))
+		}
+		if !slices.Contains(productIDs, id) {
+			productIDs = append(productIDs, id)
+		}
+	}
+	if len(productIDs) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "ids is required")
+	}
+	if len(productIDs) > maxBatchIDs {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("at most %d ids per request", maxBatchIDs))
+	}
+
+	stocks, err := h.stockService.GetMany(c.Request().Context(), productIDs)
+	if err != nil {
+		return stockError(err)
+	}
+
+	missing := []int64{}
+	for _, id := range productIDs {
+		if !slices.ContainsFunc(stocks, func(s model.ProductStock) bool { return s.ProductID == id }) {
+			missing = append(missing, id)
+		}
+	}
+	if stocks == nil {
+		stocks = []model.ProductStock{}
+	}
+	return c.JSON(http.StatusOK, map[string]any{"stocks": stocks, "missing": missing})
+}
+
+// Adjust — приёмка, списание брака или корректировка после инвентаризации (оператор).
+func (h *StockHandler) Adjust(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	req := new(adjustRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	adj := &model.StockAdjustment{WarehouseCode: req.Warehouse, ProductID: productID, Type: req.Type, Reason: req.Reason, Note: req.Note, UserID: userID}
+	stock, err := h.stockService.Adjust(c.Request().Context(), adj, req.Quantity)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, adjustResponse{Stock: stock, Adjustment: adj})
+}
+
+// Set выставляет абсолютный остаток (оператор). Конкурентное изменение → 409,
+// клиент перечитывает остаток и повторяет с новой версией.
+func (h *StockHandler) Set(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	req := new(setStockRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	adj := &model.StockAdjustment{WarehouseCode: req.Warehouse, ProductID: productID, Reason: req.Reason, Note: req.Note, UserID: userID}
+	stock, err := h.stockService.Set(c.Request().Context(), adj, req.Quantity, req.Version)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, adjustResponse{Stock: stock, Adjustment: adj})
+}
+
+// Transfer перемещает свободный товар между складами (оператор).
+func (h *StockHandler) Transfer(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	req := new(transferRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	t := &model.StockTransfer{
+		ProductID: productID, FromWarehouse: req.From, ToWarehouse: req.To, Quantity: req.Quantity,
+		Reason: req.Reason, Note: req.Note, UserID: userID,
+	}
+	from, to, err := h.stockService.Transfer(c.Request().Context(), t)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, transferResponse{From: from, To: to, Transfer: t})
+}
+
+func (h *StockHandler) GetThreshold(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	t, err := h.stockService.GetThreshold(c.Request().Context(), productID)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, t)
+}
+
+// SetThreshold задаёт порог дозаказа товара (оператор).
+func (h *StockHandler) SetThreshold(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	req := new(thresholdRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	t := &model.StockThreshold{ProductID: productID, LowStock: req.LowStock, UserID: userID}
+	if err := h.stockService.SetThreshold(c.Request().Context(), t); err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, t)
+}
+
+func (h *StockHandler) DeleteThreshold(c echo.Context) error {
+	productID, err := strconv.ParseInt(c.Param("productId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	if err := h.stockService.DeleteThreshold(c.Request().Context(), productID); err != nil {
+		return stockError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Alerts — товары, которых сейчас мало (low) или нет (out).
+func (h *StockHandler) Alerts(c echo.Context) error {
+	alerts, err := h.stockService.Alerts(c.Request().Context())
+	if err != nil {
+		return stockError(err)
+	}
+	if alerts == nil {
+		alerts = []model.StockAlert{}
+	}
+	return c.JSON(http.StatusOK, map[string]any{"alerts": alerts})
+}
+
+func stockError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidAdjustment), errors.Is(err, service.ErrInvalidWarehouse),
+		errors.Is(err, service.ErrInvalidThreshold), errors.Is(err, service.ErrInvalidImport),
+		errors.Is(err, service.ErrInvalidProduct):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, repository.ErrOrderNotReserved):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrVersionConflict), errors.Is(err, repository.ErrAlreadyUndone):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
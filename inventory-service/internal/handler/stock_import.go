@@ -0,0 +1,134 @@
+// internal/handler/stock_import.go
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportSize — предельный размер загружаемого CSV.
+const maxImportSize = 10 << 20
+
+// StockImportHandler — загрузка и выгрузка остатков в CSV (операторы).
+type StockImportHandler struct {
+	importService *service.StockImportService
+}
+
+func NewStockImportHandler(importService *service.StockImportService) *StockImportHandler {
+	return &StockImportHandler{importService: importService}
+}
+
+// Import принимает CSV телом запроса (Content-Type: text/csv) или файлом "file"
+// в multipart/form-data. ?dry_run=true — только предпросмотр: что станет с каждой
+// строкой и какие строки с ошибками.
+func (h *StockImportHandler) Import(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	dryRun := false
+	if s := c.QueryParam("dry_run"); s != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(s); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "dry_run must be true or false")
+		}
+	}
+
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxImportSize)
+	imp := &model.StockImport{UserID: userID}
+	var body io.Reader = req.Body
+	if strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return echo.ErrBadRequest
+		}
+		defer f.Close()
+		body, imp.Filename = f, fh.Filename
+	}
+
+	rows, err := h.importService.Import(req.Context(), body, imp, dryRun)
+	if errors.Is(err, service.ErrInvalidImport) && rows != nil {
+		return c.JSON(http.StatusUnprocessableEntity, map[string]any{"message": err.Error(), "errors": rowErrors(rows)})
+	}
+	if err != nil {
+		return stockError(err)
+	}
+
+	if dryRun {
+		errs := rowErrors(rows)
+		return c.JSON(http.StatusOK, map[string]any{"dry_run": true, "valid": len(errs) == 0, "rows": rows, "errors": errs})
+	}
+	if imp.Status == model.ImportPartial {
+		// Часть пачек применена: импорт можно откатить или догрузить оставшиеся строки
+		return c.JSON(http.StatusConflict, map[string]any{"message": imp.Error, "import": imp})
+	}
+	return c.JSON(http.StatusCreated, map[string]any{"import": imp})
+}
+
+func rowErrors(rows []model.StockImportRow) []model.StockImportRow {
+	errs := []model.StockImportRow{}
+	for _, row := range rows {
+		if row.Error != "" {
+			errs = append(errs, row)
+		}
+	}
+	return errs
+}
+
+func (h *StockImportHandler) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	imp, err := h.importService.Get(c.Request().Context(), id)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, imp)
+}
+
+// Undo откатывает импорт по журналу движений.
+func (h *StockImportHandler) Undo(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	imp, err := h.importService.Undo(c.Request().Context(), id, userID)
+	if err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, imp)
+}
+
+// Export отдаёт остатки потоком в формате импорта: ?warehouse=main — один склад.
+func (h *StockImportHandler) Export(c echo.Context) error {
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	resp.Header().Set(echo.HeaderContentDisposition, `attachment; filename="stock.csv"`)
+	if err := h.importService.Export(c.Request().Context(), resp, c.QueryParam("warehouse")); err != nil {
+		if resp.Committed {
+			// Заголовки уже ушли — клиент получит оборванный файл
+			log.Printf("Stock export failed: %v", err)
+			return nil
+		}
+		resp.Header().Del(echo.HeaderContentDisposition)
+		return stockError(err)
+	}
+	return nil
+}
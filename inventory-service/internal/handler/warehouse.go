@@ -0,0 +1,74 @@
+// internal/handler/warehouse.go
+package handler
+
+import (
+	"net/http"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WarehouseHandler struct {
+	warehouseService *service.WarehouseService
+}
+
+func NewWarehouseHandler(warehouseService *service.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{warehouseService: warehouseService}
+}
+
+type warehouseRequest struct {
+	Code     string `json:"code"` // только при создании
+	Name     string `json:"name"`
+	Region   string `json:"region"`
+	Priority int    `json:"priority"`
+	Active   *bool  `json:"active"` // по умолчанию true
+}
+
+func (r *warehouseRequest) warehouse() *model.Warehouse {
+	w := &model.Warehouse{Code: r.Code, Name: r.Name, Region: r.Region, Priority: r.Priority, Active: true}
+	if r.Active != nil {
+		w.Active = *r.Active
+	}
+	return w
+}
+
+func (h *WarehouseHandler) List(c echo.Context) error {
+	warehouses, err := h.warehouseService.List(c.Request().Context())
+	if err != nil {
+		return stockError(err)
+	}
+	if warehouses == nil {
+		warehouses = []model.Warehouse{}
+	}
+	return c.JSON(http.StatusOK, warehouses)
+}
+
+func (h *WarehouseHandler) Create(c echo.Context) error {
+	req := new(warehouseRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	w := req.warehouse()
+	if err := h.warehouseService.Create(c.Request().Context(), w); err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusCreated, w)
+}
+
+// Update заменяет название, регион, приоритет и признак active склада.
+func (h *WarehouseHandler) Update(c echo.Context) error {
+	req := new(warehouseRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	w := req.warehouse()
+	w.Code = c.Param("code")
+	if err := h.warehouseService.Update(c.Request().Context(), w); err != nil {
+		return stockError(err)
+	}
+	return c.JSON(http.StatusOK, w)
+}
@@ -0,0 +1,32 @@
+// internal/middleware/auth.go
+package middleware
+
+import (
+	"strings"
+
+	"inventory-service/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Auth проверяет JWT от auth-service и кладёт user_id в контекст запроса.
+func Auth() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			authHeader := c.Request().Header.Get("Authorization")
+			if authHeader == "" {
+				return echo.ErrUnauthorized
+			}
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				return echo.ErrUnauthorized
+			}
+			userID, err := utils.ValidateToken(parts[1])
+			if err != nil {
+				return echo.ErrUnauthorized
+			}
+			c.Set("user_id", userID)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// internal/middleware/roles.go
+package middleware
+
+import (
+	"slices"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator" // оператор склада: корректирует остатки
+)
+
+// Roles — роли пользователей. JWT от auth-service ролей не содержит,
+// поэтому они задаются в конфиге списками user_id (ADMIN_USER_IDS, OPERATOR_USER_IDS).
+type Roles map[int64][]string
+
+// Grant adds role to every user in userIDs.
+func (r Roles) Grant(role string, userIDs ...int64) Roles {
+	for _, id := range userIDs {
+		if !slices.Contains(r[id], role) {
+			r[id] = append(r[id], role)
+		}
+	}
+	return r
+}
+
+// Has reports whether the user holds any of the roles.
+func (r Roles) Has(userID int64, roles ...string) bool {
+	for _, role := range roles {
+		if slices.Contains(r[userID], role) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole lets the request through only if the authenticated user holds one of
+// the roles. It must run after the auth middleware that sets "user_id".
+func RequireRole(roles Roles, allowed ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, _ := c.Get("user_id").(int64)
+			if !roles.Has(userID, allowed...) {
+				return echo.ErrForbidden
+			}
+			return next(c)
+		}
+	}
+}
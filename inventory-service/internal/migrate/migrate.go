@@ -0,0 +1,90 @@
+// internal/migrate/migrate.go
+
+// Package migrate — миграции inventory-service: файлы migrations/ и общий мигратор
+// pkg/migrate с драйвером go-pg.
+package migrate
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"time"
+
+	"pkg/migrate"
+
+	"github.com/go-pg/pg/v10"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// lockName — имя advisory lock; одинаковое у всех реплик сервиса.
+const lockName = "inventory-service.migrate"
+
+func New(db *pg.DB) (*migrate.Migrator, error) {
+	return migrate.New(&pgDriver{db: db}, lockName, migrationsFS, "migrations")
+}
+
+// pgDriver — migrate.Driver поверх go-pg; плейсхолдеры "?" go-pg понимает сам.
+type pgDriver struct {
+	db *pg.DB
+}
+
+func (d *pgDriver) Session(ctx context.Context) (migrate.Session, error) {
+	return &pgSession{conn: d.db.Conn()}, nil
+}
+
+// Lock takes pg_advisory_lock, which belongs to the session.
+func (d *pgDriver) Lock(ctx context.Context, name string) (migrate.Session, error) {
+	s := &pgSession{conn: d.db.Conn()}
+	if err := s.Exec(ctx, `SELECT pg_advisory_lock(hashtext(?))`, name); err != nil {
+		return nil, errors.Join(err, s.conn.Close())
+	}
+	s.unlock = func(ctx context.Context) error {
+		return s.Exec(ctx, `SELECT pg_advisory_unlock(hashtext(?))`, name)
+	}
+	return s, nil
+}
+
+type pgSession struct {
+	conn   *pg.Conn
+	unlock func(ctx context.Context) error
+}
+
+func (s *pgSession) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := s.conn.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (s *pgSession) Applied(ctx context.Context) (map[int64]time.Time, error) {
+	var rows []struct {
+		Version   int64
+		AppliedAt time.Time
+	}
+	if _, err := s.conn.QueryContext(ctx, &rows, `SELECT version, applied_at FROM schema_migrations`); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
+
+func (s *pgSession) InTx(ctx context.Context, fn func(exec func(query string, args ...any) error) error) error {
+	return s.conn.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		return fn(func(query string, args ...any) error {
+			_, err := tx.ExecContext(ctx, query, args...)
+			return err
+		})
+	})
+}
+
+func (s *pgSession) Close(ctx context.Context) error {
+	var err error
+	if s.unlock != nil {
+		err = s.unlock(ctx)
+	}
+	return errors.Join(err, s.conn.Close())
+}
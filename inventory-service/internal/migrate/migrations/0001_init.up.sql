@@ -1,3 +1,5 @@
+-- Исходная схема (бывший init-sql/inventory-init.sql). На базе, созданной им, миграция
+-- ничего не меняет; дальнейшие изменения схемы — только следующими миграциями.
 CREATE TABLE IF NOT EXISTS stock (
     product_id BIGINT PRIMARY KEY,
     quantity INT NOT NULL CHECK (quantity >= 0)
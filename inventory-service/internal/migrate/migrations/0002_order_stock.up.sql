@@ -0,0 +1,7 @@
+-- Что списано под каждый заказ: по order.cancelled возвращается ровно это
+CREATE TABLE IF NOT EXISTS order_stock (
+    order_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (order_id, product_id)
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS return_restocks;
+ALTER TABLE stock DROP COLUMN IF EXISTS quarantine;
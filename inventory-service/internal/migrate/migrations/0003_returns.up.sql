@@ -0,0 +1,8 @@
+-- возвращённый товар, ждущий проверки; не продаётся
+ALTER TABLE stock ADD COLUMN IF NOT EXISTS quarantine INT NOT NULL DEFAULT 0 CHECK (quarantine >= 0);
+
+-- Применённые возвраты (order.return_received) — защита от повторной доставки события
+CREATE TABLE IF NOT EXISTS return_restocks (
+    return_id BIGINT PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
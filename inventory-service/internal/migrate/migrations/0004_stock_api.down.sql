@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_adjustments;
+ALTER TABLE stock DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE stock DROP COLUMN IF EXISTS version;
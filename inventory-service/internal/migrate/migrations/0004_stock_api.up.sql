@@ -0,0 +1,18 @@
+-- version растёт при каждом изменении строки: PUT /stock/:productId передаёт
+-- ожидаемую версию (оптимистичная блокировка)
+ALTER TABLE stock ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE stock ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Ручные корректировки остатков: кто, сколько и с каким кодом причины
+CREATE TABLE IF NOT EXISTS stock_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    delta INT NOT NULL,
+    quantity_after INT NOT NULL,
+    reason VARCHAR(64) NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_stock_adjustments_product ON stock_adjustments (product_id, id);
@@ -0,0 +1,15 @@
+-- reserved — сумма удержаний со статусом held; продавать можно quantity - reserved
+ALTER TABLE stock ADD COLUMN IF NOT EXISTS reserved INT NOT NULL DEFAULT 0 CHECK (reserved >= 0);
+
+-- Удержание товара под заказ: held → committed (оплата) | released (отмена) | expired (TTL)
+CREATE TABLE IF NOT EXISTS reservations (
+    order_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'held',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (order_id, product_id)
+);
+CREATE INDEX IF NOT EXISTS idx_reservations_held_expiry ON reservations (expires_at) WHERE status = 'held';
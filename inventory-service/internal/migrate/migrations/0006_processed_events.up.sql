@@ -0,0 +1,11 @@
+-- Обработанные события Kafka. Пишется в той же транзакции, что и изменение остатков,
+-- поэтому повторная доставка сообщения ничего не меняет.
+-- event_key — "<topic>:<order_id>" (или return_id для возвратов): order-service может
+-- переопубликовать событие с новым event_id, а у заказа каждое событие бывает один раз.
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_key VARCHAR(128) PRIMARY KEY,
+    event_id VARCHAR(64) NOT NULL DEFAULT '',
+    topic VARCHAR(128) NOT NULL,
+    processed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON processed_events (processed_at);
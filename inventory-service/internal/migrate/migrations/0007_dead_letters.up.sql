@@ -0,0 +1,23 @@
+-- Сообщения из DLQ-топиков (<topic>.dlq) для просмотра и повторной отправки админом
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id BIGSERIAL PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    source_partition INT NOT NULL,
+    source_offset BIGINT NOT NULL,
+    key BYTEA,
+    value BYTEA NOT NULL,
+    headers JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL,
+    error_class VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL,
+    failed_at TIMESTAMP NOT NULL,
+    dlq_topic VARCHAR(255) NOT NULL,
+    dlq_partition INT NOT NULL,
+    dlq_offset BIGINT NOT NULL,
+    replay_count INT NOT NULL DEFAULT 0,
+    replayed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- повторная доставка из DLQ не создаёт вторую запись
+    UNIQUE (dlq_topic, dlq_partition, dlq_offset)
+);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_topic ON dead_letters (topic, id);
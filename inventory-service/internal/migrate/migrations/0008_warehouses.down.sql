@@ -0,0 +1,51 @@
+-- Остатки всех складов складываются в одну строку на товар
+DROP TABLE IF EXISTS stock_transfers;
+ALTER TABLE stock_adjustments DROP COLUMN warehouse_code;
+
+UPDATE stock s
+SET quantity = t.quantity, reserved = t.reserved, quarantine = t.quarantine
+FROM (
+    SELECT product_id, SUM(quantity) AS quantity, SUM(reserved) AS reserved, SUM(quarantine) AS quarantine,
+        MIN(warehouse_code) AS warehouse_code
+    FROM stock GROUP BY product_id
+) t
+WHERE s.product_id = t.product_id AND s.warehouse_code = t.warehouse_code;
+DELETE FROM stock s
+WHERE s.warehouse_code <> (SELECT MIN(x.warehouse_code) FROM stock x WHERE x.product_id = s.product_id);
+ALTER TABLE stock DROP CONSTRAINT stock_pkey;
+ALTER TABLE stock DROP COLUMN warehouse_code;
+ALTER TABLE stock ADD PRIMARY KEY (product_id);
+DROP INDEX IF EXISTS idx_stock_product;
+
+UPDATE order_stock s
+SET quantity = t.quantity
+FROM (
+    SELECT order_id, product_id, SUM(quantity) AS quantity, MIN(warehouse_code) AS warehouse_code
+    FROM order_stock GROUP BY order_id, product_id
+) t
+WHERE s.order_id = t.order_id AND s.product_id = t.product_id AND s.warehouse_code = t.warehouse_code;
+DELETE FROM order_stock s
+WHERE s.warehouse_code <> (
+    SELECT MIN(x.warehouse_code) FROM order_stock x WHERE x.order_id = s.order_id AND x.product_id = s.product_id
+);
+ALTER TABLE order_stock DROP CONSTRAINT order_stock_pkey;
+ALTER TABLE order_stock DROP COLUMN warehouse_code;
+ALTER TABLE order_stock ADD PRIMARY KEY (order_id, product_id);
+
+-- У split-заказа остаётся удержание с первого склада, суммарное по товару
+UPDATE reservations s
+SET quantity = t.quantity
+FROM (
+    SELECT order_id, product_id, SUM(quantity) AS quantity, MIN(warehouse_code) AS warehouse_code
+    FROM reservations GROUP BY order_id, product_id
+) t
+WHERE s.order_id = t.order_id AND s.product_id = t.product_id AND s.warehouse_code = t.warehouse_code;
+DELETE FROM reservations s
+WHERE s.warehouse_code <> (
+    SELECT MIN(x.warehouse_code) FROM reservations x WHERE x.order_id = s.order_id AND x.product_id = s.product_id
+);
+ALTER TABLE reservations DROP CONSTRAINT reservations_pkey;
+ALTER TABLE reservations DROP COLUMN warehouse_code;
+ALTER TABLE reservations ADD PRIMARY KEY (order_id, product_id);
+
+DROP TABLE IF EXISTS warehouses;
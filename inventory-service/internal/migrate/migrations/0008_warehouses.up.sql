@@ -0,0 +1,50 @@
+-- Склады. Остатки, удержания и списания ведутся по (склад, товар);
+-- всё, что было до появления складов, переезжает на склад main.
+CREATE TABLE IF NOT EXISTS warehouses (
+    code VARCHAR(32) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    -- регион из адреса доставки, для стратегии nearest
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    -- меньше — раньше при распределении заказа
+    priority INT NOT NULL DEFAULT 100,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO warehouses (code, name, priority) VALUES ('main', 'Main warehouse', 0)
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE stock ADD COLUMN warehouse_code VARCHAR(32) NOT NULL DEFAULT 'main' REFERENCES warehouses (code);
+ALTER TABLE stock ALTER COLUMN warehouse_code DROP DEFAULT;
+ALTER TABLE stock DROP CONSTRAINT stock_pkey;
+ALTER TABLE stock ADD PRIMARY KEY (warehouse_code, product_id);
+CREATE INDEX IF NOT EXISTS idx_stock_product ON stock (product_id);
+
+-- Склад, который собирает позицию заказа; при split shipment товар может ехать с нескольких
+ALTER TABLE reservations ADD COLUMN warehouse_code VARCHAR(32) NOT NULL DEFAULT 'main' REFERENCES warehouses (code);
+ALTER TABLE reservations ALTER COLUMN warehouse_code DROP DEFAULT;
+ALTER TABLE reservations DROP CONSTRAINT reservations_pkey;
+ALTER TABLE reservations ADD PRIMARY KEY (order_id, warehouse_code, product_id);
+
+ALTER TABLE order_stock ADD COLUMN warehouse_code VARCHAR(32) NOT NULL DEFAULT 'main' REFERENCES warehouses (code);
+ALTER TABLE order_stock ALTER COLUMN warehouse_code DROP DEFAULT;
+ALTER TABLE order_stock DROP CONSTRAINT order_stock_pkey;
+ALTER TABLE order_stock ADD PRIMARY KEY (order_id, warehouse_code, product_id);
+
+ALTER TABLE stock_adjustments ADD COLUMN warehouse_code VARCHAR(32) NOT NULL DEFAULT 'main';
+ALTER TABLE stock_adjustments ALTER COLUMN warehouse_code DROP DEFAULT;
+
+-- Перемещения товара между складами
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    from_warehouse VARCHAR(32) NOT NULL REFERENCES warehouses (code),
+    to_warehouse VARCHAR(32) NOT NULL REFERENCES warehouses (code),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    reason VARCHAR(64) NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (from_warehouse <> to_warehouse)
+);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_product ON stock_transfers (product_id, id);
@@ -0,0 +1,27 @@
+-- Журнал движений товара: каждое изменение quantity, reserved и quarantine пишется
+-- сюда в той же транзакции. Сумма дельт по (складу, товару) равна строке stock.
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    warehouse_code VARCHAR(32) NOT NULL,
+    product_id BIGINT NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    quantity_delta INT NOT NULL DEFAULT 0,
+    reserved_delta INT NOT NULL DEFAULT 0,
+    quarantine_delta INT NOT NULL DEFAULT 0,
+    order_id BIGINT,
+    -- adjustment:<id>, transfer:<id>, return:<id>
+    reference VARCHAR(64) NOT NULL DEFAULT '',
+    user_id BIGINT,
+    -- с часовым поясом: GET /stock/:id?as_of= сравнивает с временем клиента
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product ON stock_movements (product_id, warehouse_code, id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_order ON stock_movements (order_id) WHERE order_id IS NOT NULL;
+
+-- Журнал только дополняется
+CREATE OR REPLACE RULE stock_movements_no_update AS ON UPDATE TO stock_movements DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE stock_movements_no_delete AS ON DELETE TO stock_movements DO INSTEAD NOTHING;
+
+-- История начинается с текущих остатков
+INSERT INTO stock_movements (warehouse_code, product_id, type, quantity_delta, reserved_delta, quarantine_delta)
+SELECT warehouse_code, product_id, 'opening', quantity, reserved, quarantine FROM stock;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS stock_alerts;
+DROP TABLE IF EXISTS stock_thresholds;
@@ -0,0 +1,33 @@
+-- Порог дозаказа товара: inventory.low_stock, когда свободный остаток по всем складам
+-- опускается до low_stock
+CREATE TABLE IF NOT EXISTS stock_thresholds (
+    product_id BIGINT PRIMARY KEY,
+    low_stock INT NOT NULL CHECK (low_stock >= 0),
+    user_id BIGINT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Последний известный уровень остатка товара (ok, low, out). Алерт публикуется только
+-- при переходе на уровень хуже, поэтому повторные списания не дают повторных алертов.
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    product_id BIGINT PRIMARY KEY,
+    level VARCHAR(8) NOT NULL,
+    available INT NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+INSERT INTO stock_alerts (product_id, level, available)
+SELECT product_id, CASE WHEN SUM(quantity - reserved) <= 0 THEN 'out' ELSE 'ok' END, SUM(quantity - reserved)
+FROM stock GROUP BY product_id
+ON CONFLICT (product_id) DO NOTHING;
+
+-- Transactional outbox: события пишутся в той же транзакции, что и изменение остатка,
+-- и публикуются в Kafka фоновым процессом; опубликованные строки удаляются.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    -- stock_changed, low_stock, out_of_stock → топики inventory.*
+    type VARCHAR(20) NOT NULL,
+    message_key VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
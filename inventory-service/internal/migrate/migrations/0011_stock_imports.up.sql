@@ -0,0 +1,16 @@
+-- Импорт остатков из CSV. Движения импорта пишутся в stock_movements с reference
+-- import:<id>, по ним импорт и откатывается.
+CREATE TABLE IF NOT EXISTS stock_imports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    row_count INT NOT NULL,
+    applied_rows INT NOT NULL DEFAULT 0,
+    -- applied | partial (пачка упала посреди импорта) | undone
+    status VARCHAR(10) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    undone_at TIMESTAMP,
+    undone_by BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_reference ON stock_movements (reference) WHERE reference <> '';
@@ -0,0 +1,16 @@
+-- Каталог товаров: SKU, цена и признак active. product_id в остатках, удержаниях
+-- и заказах — это products.id; order-service сверяет с каталогом позиции и цены заказа.
+-- Товары, которые уже есть в остатках, заводит в каталоге 0014_catalog_backfill.
+CREATE TABLE IF NOT EXISTS products (
+    id BIGSERIAL PRIMARY KEY,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    -- в минимальных единицах валюты, как unit_price в заказе
+    price BIGINT NOT NULL CHECK (price >= 0),
+    currency CHAR(3) NOT NULL,
+    -- неактивный товар виден в каталоге, но не продаётся
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
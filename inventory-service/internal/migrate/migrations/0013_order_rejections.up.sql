@@ -0,0 +1,8 @@
+-- Заказы, товар которых не удалось удержать: order-service получает
+-- inventory.reservation_failed и отменяет их. Повторы order.created, а также
+-- order.paid и order.cancelled таких заказов — no-op.
+CREATE TABLE IF NOT EXISTS order_rejections (
+    order_id BIGINT PRIMARY KEY,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
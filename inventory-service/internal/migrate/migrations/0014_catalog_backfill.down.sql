@@ -0,0 +1,3 @@
+-- Удаляются только заглушки, которые администратор не трогал
+DELETE FROM products
+WHERE sku = 'LEGACY-' || id AND price = 0 AND currency = 'XXX' AND NOT active;
@@ -0,0 +1,12 @@
+-- Товары, которые уже есть в остатках, заводятся в каталоге под своим id.
+-- Цены у них нет, поэтому они неактивны, с ценой 0 и валютой XXX («не задана»):
+-- order-service отклоняет такие позиции, пока администратор не задаст цену и
+-- не включит товар (PUT /products/:id). Так CATALOG_URL можно включать сразу,
+-- а заказы на товар без цены не проходят молча.
+INSERT INTO products (id, sku, name, price, currency, active)
+SELECT DISTINCT product_id, 'LEGACY-' || product_id, 'Product ' || product_id, 0, 'XXX', FALSE
+FROM stock
+ON CONFLICT DO NOTHING;
+
+-- Следующий товар без явного id получит номер после заведённых
+SELECT setval(pg_get_serial_sequence('products', 'id'), max(id)) FROM products;
@@ -0,0 +1,75 @@
+// internal/model/alert.go
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Уровни свободного остатка товара по всем складам.
+const (
+	StockLevelOK  = "ok"
+	StockLevelLow = "low" // не больше порога дозаказа
+	StockLevelOut = "out" // свободного остатка нет
+)
+
+// StockLevelOf returns the level of a product with the given available quantity
+// and reorder threshold (0 — no threshold, only out-of-stock is tracked).
+func StockLevelOf(available, threshold int) string {
+	switch {
+	case available <= 0:
+		return StockLevelOut
+	case available <= threshold:
+		return StockLevelLow
+	default:
+		return StockLevelOK
+	}
+}
+
+// WorseStockLevel reports whether level a is worse than b.
+func WorseStockLevel(a, b string) bool {
+	rank := map[string]int{StockLevelOK: 0, StockLevelLow: 1, StockLevelOut: 2}
+	return rank[a] > rank[b]
+}
+
+// StockThreshold — порог дозаказа товара.
+type StockThreshold struct {
+	ProductID int64     `pg:"product_id,pk" json:"product_id"`
+	LowStock  int       `pg:"low_stock,use_zero" json:"low_stock"`
+	UserID    int64     `pg:"user_id" json:"user_id"`
+	UpdatedAt time.Time `pg:"updated_at" json:"updated_at"`
+}
+
+// StockAlert — текущий уровень остатка товара; он же тело алертов low_stock и out_of_stock.
+type StockAlert struct {
+	ProductID int64     `pg:"product_id,pk" json:"product_id"`
+	Level     string    `pg:"level" json:"level"`
+	Available int       `pg:"available,use_zero" json:"available"`
+	Threshold int       `pg:"threshold,use_zero" json:"threshold"` // 0 — порог не задан
+	ChangedAt time.Time `pg:"changed_at" json:"changed_at"`
+}
+
+// Типы событий в outbox; OutboxPublisher публикует их в топики inventory.*.
+const (
+	EventStockChanged      = "stock_changed"
+	EventLowStock          = "low_stock"
+	EventOutOfStock        = "out_of_stock"
+	EventReservationFailed = "reservation_failed"
+)
+
+// OutboxEvent — событие, записанное в одной транзакции с изменением остатка
+// и ещё не опубликованное.
+type OutboxEvent struct {
+	ID        int64           `pg:"id,pk"`
+	EventID   string          `pg:"event_id"`
+	Type      string          `pg:"type"`
+	Key       string          `pg:"message_key"`
+	Payload   json.RawMessage `pg:"payload"`
+	CreatedAt time.Time       `pg:"created_at"`
+}
+
+// StockChange — тело события stock_changed: движение и строка stock после него.
+type StockChange struct {
+	Movement StockMovement `json:"movement"`
+	Stock    Stock         `json:"stock"`
+}
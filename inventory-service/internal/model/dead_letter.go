@@ -0,0 +1,47 @@
+// internal/model/dead_letter.go
+package model
+
+import "time"
+
+// Классы ошибок обработки сообщения Kafka.
+const (
+	ErrorClassRetryable = "retryable" // сбой БД, нехватка товара — повторы могут помочь
+	ErrorClassPermanent = "permanent" // битое сообщение — сразу в DLQ
+)
+
+// MessageHeader — заголовок сообщения Kafka в JSON.
+type MessageHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// DeadLetter — сообщение, которое не удалось обработать ни сразу, ни из ретрай-топиков.
+type DeadLetter struct {
+	ID         int64           `pg:"id,pk" json:"id"`
+	Topic      string          `pg:"topic" json:"topic"` // исходный топик
+	Partition  int             `pg:"source_partition,use_zero" json:"partition"`
+	Offset     int64           `pg:"source_offset,use_zero" json:"offset"`
+	Key        []byte          `pg:"key" json:"key"`
+	Value      []byte          `pg:"value" json:"value"`
+	Headers    []MessageHeader `pg:"headers,type:jsonb" json:"headers"`
+	Error      string          `pg:"error" json:"error"`
+	ErrorClass string          `pg:"error_class" json:"error_class"`
+	Attempts   int             `pg:"attempts,use_zero" json:"attempts"`
+	FailedAt   time.Time       `pg:"failed_at" json:"failed_at"`
+
+	DLQTopic     string `pg:"dlq_topic" json:"dlq_topic"`
+	DLQPartition int    `pg:"dlq_partition,use_zero" json:"-"`
+	DLQOffset    int64  `pg:"dlq_offset,use_zero" json:"-"`
+
+	ReplayCount int        `pg:"replay_count,use_zero" json:"replay_count"`
+	ReplayedAt  *time.Time `pg:"replayed_at" json:"replayed_at,omitempty"`
+	CreatedAt   time.Time  `pg:"created_at" json:"created_at"`
+}
+
+// DeadLetterFilter — фильтр списка DLQ для админки.
+type DeadLetterFilter struct {
+	Topic    string
+	Replayed *bool // nil — все
+	BeforeID int64 // курсор: записи с id < BeforeID
+	Limit    int
+}
@@ -0,0 +1,52 @@
+// internal/model/import.go
+package model
+
+import "time"
+
+// Режимы строки CSV-импорта.
+const (
+	ImportModeSet = "set" // quantity — новый остаток
+	ImportModeAdd = "add" // quantity — дельта со знаком
+)
+
+// Статусы импорта.
+const (
+	ImportApplied = "applied"
+	ImportPartial = "partial" // часть пачек применена, затем ошибка; откатить можно применённое
+	ImportUndone  = "undone"
+)
+
+// ImportCSVHeader — колонки CSV импорта и экспорта остатков.
+var ImportCSVHeader = []string{"product_id", "warehouse", "quantity", "mode"}
+
+// StockImportRow — строка CSV и её эффект: остаток до и после, либо ошибка.
+type StockImportRow struct {
+	Line          int    `json:"line"` // номер строки в файле, заголовок — 1
+	ProductID     int64  `json:"product_id"`
+	WarehouseCode string `json:"warehouse"`
+	Quantity      int    `json:"quantity"`
+	Mode          string `json:"mode"`
+	Before        int    `json:"before"`
+	After         int    `json:"after"`
+	Reserved      int    `json:"reserved"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Delta — на сколько строка меняет quantity.
+func (r *StockImportRow) Delta() int {
+	return r.After - r.Before
+}
+
+// StockImport — применённый CSV-импорт; его движения в журнале — reference import:<id>.
+type StockImport struct {
+	ID          int64      `pg:"id,pk" json:"id"`
+	UserID      int64      `pg:"user_id" json:"user_id"`
+	Filename    string     `pg:"filename,use_zero" json:"filename,omitempty"`
+	Rows        int        `pg:"row_count,use_zero" json:"rows"`
+	AppliedRows int        `pg:"applied_rows,use_zero" json:"applied_rows"`
+	Status      string     `pg:"status" json:"status"`
+	Error       string     `pg:"error,use_zero" json:"error,omitempty"`
+	CreatedAt   time.Time  `pg:"created_at" json:"created_at"`
+	UndoneAt    *time.Time `pg:"undone_at" json:"undone_at,omitempty"`
+	UndoneBy    int64      `pg:"undone_by" json:"undone_by,omitempty"`
+}
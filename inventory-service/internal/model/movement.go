@@ -0,0 +1,64 @@
+// internal/model/movement.go
+package model
+
+import "time"
+
+// Типы движений в журнале stock_movements. Ручные корректировки пишутся с типом
+// корректировки: receive, damage, correction, set.
+const (
+	MovementOpening     = "opening"      // остаток на момент появления журнала
+	MovementReserve     = "reserve"      // удержание под заказ, reserved+
+	MovementRelease     = "release"      // заказ отменён до оплаты, reserved-
+	MovementExpire      = "expire"       // удержание снято по TTL, reserved-
+	MovementDeduct      = "deduct"       // заказ оплачен, quantity- (и reserved-, если было удержание)
+	MovementRestock     = "restock"      // оплаченный заказ отменён, quantity+
+	MovementReturn      = "return"       // принятый возврат, quantity+ или quarantine+
+	MovementTransferOut = "transfer_out" // перемещение на другой склад, quantity-
+	MovementTransferIn  = "transfer_in"  // перемещение с другого склада, quantity+
+	MovementImport      = "import"       // строка CSV-импорта, quantity±
+	MovementImportUndo  = "import_undo"  // откат импорта, quantity∓
+)
+
+// StockMovement — запись журнала движений: на сколько изменились поля строки stock.
+type StockMovement struct {
+	ID              int64     `pg:"id,pk" json:"id"`
+	WarehouseCode   string    `pg:"warehouse_code" json:"warehouse"`
+	ProductID       int64     `pg:"product_id" json:"product_id"`
+	Type            string    `pg:"type" json:"type"`
+	QuantityDelta   int       `pg:"quantity_delta,use_zero" json:"quantity_delta"`
+	ReservedDelta   int       `pg:"reserved_delta,use_zero" json:"reserved_delta"`
+	QuarantineDelta int       `pg:"quarantine_delta,use_zero" json:"quarantine_delta"`
+	OrderID         int64     `pg:"order_id" json:"order_id,omitempty"`
+	Reference       string    `pg:"reference,use_zero" json:"reference,omitempty"`
+	UserID          int64     `pg:"user_id" json:"user_id,omitempty"`
+	CreatedAt       time.Time `pg:"created_at" json:"created_at"`
+}
+
+// StockHistoryEntry — движение и остаток склада сразу после него.
+type StockHistoryEntry struct {
+	StockMovement
+	QuantityAfter   int `pg:"quantity_after" json:"quantity_after"`
+	ReservedAfter   int `pg:"reserved_after" json:"reserved_after"`
+	QuarantineAfter int `pg:"quarantine_after" json:"quarantine_after"`
+}
+
+// StockHistoryFilter — фильтр GET /stock/:productId/history.
+type StockHistoryFilter struct {
+	ProductID     int64
+	WarehouseCode string    // пусто — все склады
+	From, To      time.Time // нулевые — без ограничения
+	BeforeID      int64     // курсор: записи с id < BeforeID
+	Limit         int
+}
+
+// StockDiscrepancy — строка stock, не совпавшая с суммой журнала.
+type StockDiscrepancy struct {
+	WarehouseCode    string `pg:"warehouse_code" json:"warehouse"`
+	ProductID        int64  `pg:"product_id" json:"product_id"`
+	Quantity         int    `pg:"quantity" json:"quantity"`
+	LedgerQuantity   int    `pg:"ledger_quantity" json:"ledger_quantity"`
+	Reserved         int    `pg:"reserved" json:"reserved"`
+	LedgerReserved   int    `pg:"ledger_reserved" json:"ledger_reserved"`
+	Quarantine       int    `pg:"quarantine" json:"quarantine"`
+	LedgerQuarantine int    `pg:"ledger_quarantine" json:"ledger_quarantine"`
+}
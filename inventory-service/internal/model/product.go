@@ -0,0 +1,25 @@
+// internal/model/product.go
+package model
+
+import "time"
+
+// Product — товар каталога. ID — тот же product_id, что в остатках и заказах.
+type Product struct {
+	ID          int64     `pg:"id,pk" json:"id"`
+	SKU         string    `pg:"sku" json:"sku"`
+	Name        string    `pg:"name" json:"name"`
+	Description string    `pg:"description,use_zero" json:"description"`
+	Price       int64     `pg:"price,use_zero" json:"price"` // в минимальных единицах валюты
+	Currency    string    `pg:"currency" json:"currency"`
+	Active      bool      `pg:"active,use_zero" json:"active"` // неактивный товар не продаётся
+	CreatedAt   time.Time `pg:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `pg:"updated_at" json:"updated_at"`
+}
+
+// ProductFilter — фильтр поиска GET /products.
+type ProductFilter struct {
+	Query    string // подстрока названия или префикс SKU, без учёта регистра
+	Active   *bool  // nil — все товары
+	BeforeID int64  // курсор: товары с id < BeforeID
+	Limit    int
+}
@@ -1,7 +1,153 @@
 // internal/model/stock.go
 package model
 
+import (
+	"encoding/json"
+	"time"
+)
+
+// Stock — остаток товара на одном складе.
 type Stock struct {
-	ProductID int64 `pg:"product_id,pk"`
-	Quantity  int   `pg:"quantity,notnull"`
+	tableName struct{} `pg:"stock"`
+
+	WarehouseCode string    `pg:"warehouse_code,pk" json:"warehouse"`
+	ProductID     int64     `pg:"product_id,pk" json:"product_id"`
+	Quantity      int       `pg:"quantity,notnull,use_zero" json:"quantity"`
+	Reserved      int       `pg:"reserved,notnull,use_zero" json:"reserved"`     // удержано под неоплаченные заказы
+	Quarantine    int       `pg:"quarantine,notnull,use_zero" json:"quarantine"` // возвращённый товар, ждущий проверки; не продаётся
+	Version       int64     `pg:"version,notnull" json:"version"`                // растёт при каждом изменении строки
+	UpdatedAt     time.Time `pg:"updated_at" json:"updated_at"`
+}
+
+// Available — available-to-promise: остаток на складе минус удержания.
+func (s *Stock) Available() int {
+	return s.Quantity - s.Reserved
+}
+
+// MarshalJSON adds the available quantity to the stored fields.
+func (s Stock) MarshalJSON() ([]byte, error) {
+	type stock Stock
+	return json.Marshal(struct {
+		stock
+		Available int `json:"available"`
+	}{stock(s), s.Available()})
+}
+
+// ProductStock — остаток товара по всем складам: суммы и строки складов.
+type ProductStock struct {
+	ProductID  int64   `json:"product_id"`
+	Quantity   int     `json:"quantity"`
+	Reserved   int     `json:"reserved"`
+	Available  int     `json:"available"`
+	Quarantine int     `json:"quarantine"`
+	Warehouses []Stock `json:"warehouses"`
+}
+
+// GroupStock folds per-warehouse rows into one ProductStock per product, keeping the row order.
+func GroupStock(rows []Stock) []ProductStock {
+	var products []ProductStock
+	index := make(map[int64]int)
+	for _, row := range rows {
+		i, ok := index[row.ProductID]
+		if !ok {
+			i = len(products)
+			index[row.ProductID] = i
+			products = append(products, ProductStock{ProductID: row.ProductID})
+		}
+		p := &products[i]
+		p.Quantity += row.Quantity
+		p.Reserved += row.Reserved
+		p.Available += row.Available()
+		p.Quarantine += row.Quarantine
+		p.Warehouses = append(p.Warehouses, row)
+	}
+	return products
+}
+
+// StockLine — позиция заказа. WarehouseCode пуст, пока склад не выбран стратегией распределения.
+type StockLine struct {
+	WarehouseCode string `pg:"warehouse_code"`
+	ProductID     int64  `pg:"product_id"`
+	Quantity      int    `pg:"quantity"`
+}
+
+// Warehouse — склад, с которого собираются заказы.
+type Warehouse struct {
+	Code      string    `pg:"code,pk" json:"code"`
+	Name      string    `pg:"name" json:"name"`
+	Region    string    `pg:"region,use_zero" json:"region"`
+	Priority  int       `pg:"priority,use_zero" json:"priority"` // меньше — раньше при распределении
+	Active    bool      `pg:"active,use_zero" json:"active"`     // неактивный склад не участвует в распределении
+	CreatedAt time.Time `pg:"created_at" json:"created_at"`
+	UpdatedAt time.Time `pg:"updated_at" json:"updated_at"`
+}
+
+// StockTransfer — перемещение товара между складами.
+type StockTransfer struct {
+	ID            int64     `pg:"id,pk" json:"id"`
+	ProductID     int64     `pg:"product_id" json:"product_id"`
+	FromWarehouse string    `pg:"from_warehouse" json:"from"`
+	ToWarehouse   string    `pg:"to_warehouse" json:"to"`
+	Quantity      int       `pg:"quantity" json:"quantity"`
+	Reason        string    `pg:"reason" json:"reason"`
+	Note          string    `pg:"note,use_zero" json:"note,omitempty"`
+	UserID        int64     `pg:"user_id" json:"user_id"`
+	CreatedAt     time.Time `pg:"created_at" json:"created_at"`
+}
+
+// Типы ручных корректировок остатков.
+const (
+	AdjustmentReceive    = "receive"    // приёмка поставки, +quantity
+	AdjustmentDamage     = "damage"     // списание брака, -quantity
+	AdjustmentCorrection = "correction" // инвентаризация, delta любого знака
+	AdjustmentSet        = "set"        // PUT /stock/:productId, абсолютное значение
+)
+
+// StockAdjustment — запись журнала ручных корректировок.
+type StockAdjustment struct {
+	ID            int64     `pg:"id,pk" json:"id"`
+	WarehouseCode string    `pg:"warehouse_code" json:"warehouse"`
+	ProductID     int64     `pg:"product_id" json:"product_id"`
+	Type          string    `pg:"type" json:"type"`
+	Delta         int       `pg:"delta,use_zero" json:"delta"`
+	QuantityAfter int       `pg:"quantity_after,use_zero" json:"quantity_after"`
+	Reason        string    `pg:"reason" json:"reason"`
+	Note          string    `pg:"note,use_zero" json:"note,omitempty"`
+	UserID        int64     `pg:"user_id" json:"user_id"`
+	CreatedAt     time.Time `pg:"created_at" json:"created_at"`
+}
+
+// Статусы удержания товара под заказ.
+const (
+	ReservationHeld      = "held"      // удержан до оплаты или expires_at
+	ReservationCommitted = "committed" // заказ оплачен, товар списан
+	ReservationReleased  = "released"  // заказ отменён до оплаты
+	ReservationExpired   = "expired"   // удержание снято по TTL
+)
+
+// Reservation — удержание товара под заказ.
+type Reservation struct {
+	OrderID       int64     `pg:"order_id,pk" json:"order_id"`
+	WarehouseCode string    `pg:"warehouse_code,pk" json:"warehouse"`
+	ProductID     int64     `pg:"product_id,pk" json:"product_id"`
+	Quantity      int       `pg:"quantity" json:"quantity"`
+	Status        string    `pg:"status" json:"status"`
+	ExpiresAt     time.Time `pg:"expires_at" json:"expires_at"`
+	CreatedAt     time.Time `pg:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `pg:"updated_at" json:"updated_at"`
+}
+
+// OrderRejection — заказ, товар которого не удалось удержать.
+type OrderRejection struct {
+	OrderID   int64     `pg:"order_id,pk" json:"order_id"`
+	Reason    string    `pg:"reason" json:"reason"`
+	CreatedAt time.Time `pg:"created_at" json:"created_at"`
+}
+
+// EventRef — обработанное событие Kafka; Key задаёт, какие доставки считаются одним событием.
+// Пустой Key — изменение не из Kafka (например, из REST API), без дедупликации.
+type EventRef struct {
+	Key   string
+	ID    string
+	Topic string
 }
@@ -0,0 +1,161 @@
+// internal/repository/alerts.go
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// enqueue пишет событие в outbox в транзакции tx; опубликует его OutboxPublisher.
+// key — ключ сообщения Kafka: product_id, у событий заказа — order_id.
+func enqueue(ctx context.Context, tx *pg.Tx, eventType string, key int64, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO outbox_events (type, message_key, payload) VALUES (?, ?, ?)`,
+		eventType, strconv.FormatInt(key, 10), string(data))
+	return err
+}
+
+// checkStockLevel пересчитывает уровень свободного остатка товара по всем складам и,
+// если он стал хуже, ставит в outbox low_stock или out_of_stock. Строка stock_alerts
+// блокируется до конца транзакции, поэтому параллельные изменения одного товара
+// не поднимут один алерт дважды.
+func checkStockLevel(ctx context.Context, tx *pg.Tx, productID int64) error {
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO stock_alerts (product_id, level, available) VALUES (?, ?, 0)
+        ON CONFLICT (product_id) DO NOTHING`, productID, model.StockLevelOK); err != nil {
+		return err
+	}
+
+	var alert model.StockAlert
+	if _, err := tx.QueryOneContext(ctx, &alert, `
+        SELECT a.product_id, a.level, COALESCE(t.low_stock, 0) AS threshold
+        FROM stock_alerts a
+        LEFT JOIN stock_thresholds t ON t.product_id = a.product_id
+        WHERE a.product_id = ?
+        FOR UPDATE OF a`, productID); err != nil {
+		return err
+	}
+	// Остаток читается после блокировки: изменения, закоммиченные пока мы ждали, уже видны
+	if _, err := tx.QueryOneContext(ctx, pg.Scan(&alert.Available), `
+        SELECT COALESCE(SUM(quantity - reserved), 0) FROM stock WHERE product_id = ?`, productID); err != nil {
+		return err
+	}
+
+	previous := alert.Level
+	alert.Level = model.StockLevelOf(alert.Available, alert.Threshold)
+	if alert.Level == previous {
+		return nil
+	}
+	if _, err := tx.QueryOneContext(ctx, pg.Scan(&alert.ChangedAt), `
+        UPDATE stock_alerts SET level = ?, available = ?, changed_at = now()
+        WHERE product_id = ?
+        RETURNING changed_at`,
+		alert.Level, alert.Available, productID); err != nil {
+		return err
+	}
+
+	if !model.WorseStockLevel(alert.Level, previous) {
+		return nil // товар пополнили — алерт не нужен, достаточно stock_changed
+	}
+	eventType := model.EventLowStock
+	if alert.Level == model.StockLevelOut {
+		eventType = model.EventOutOfStock
+	}
+	return enqueue(ctx, tx, eventType, productID, alert)
+}
+
+// GetThreshold возвращает порог дозаказа товара или ErrNotFound.
+func (r *InventoryRepository) GetThreshold(ctx context.Context, productID int64) (*model.StockThreshold, error) {
+	t := new(model.StockThreshold)
+	err := r.db.ModelContext(ctx, t).Where("product_id = ?", productID).Select()
+	if err == pg.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// SetThreshold задаёт порог дозаказа и сразу пересчитывает уровень остатка: если товара
+// уже не больше нового порога, low_stock уходит сразу.
+func (r *InventoryRepository) SetThreshold(ctx context.Context, t *model.StockThreshold) error {
+	return r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if _, err := tx.QueryOneContext(ctx, pg.Scan(&t.UpdatedAt), `
+            INSERT INTO stock_thresholds (product_id, low_stock, user_id)
+            VALUES (?, ?, ?)
+            ON CONFLICT (product_id) DO UPDATE
+            SET low_stock = EXCLUDED.low_stock, user_id = EXCLUDED.user_id, updated_at = now()
+            RETURNING updated_at`,
+			t.ProductID, t.LowStock, t.UserID); err != nil {
+			return err
+		}
+		return checkStockLevel(ctx, tx, t.ProductID)
+	})
+}
+
+// DeleteThreshold снимает порог дозаказа; дальше отслеживается только отсутствие товара.
+func (r *InventoryRepository) DeleteThreshold(ctx context.Context, productID int64) error {
+	return r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM stock_thresholds WHERE product_id = ?`, productID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return ErrNotFound
+		}
+		return checkStockLevel(ctx, tx, productID)
+	})
+}
+
+// ListStockAlerts возвращает товары, у которых остаток сейчас ниже нормы, от новых к старым.
+func (r *InventoryRepository) ListStockAlerts(ctx context.Context) ([]model.StockAlert, error) {
+	var alerts []model.StockAlert
+	_, err := r.db.QueryContext(ctx, &alerts, `
+        SELECT a.product_id, a.level, a.available, COALESCE(t.low_stock, 0) AS threshold, a.changed_at
+        FROM stock_alerts a
+        LEFT JOIN stock_thresholds t ON t.product_id = a.product_id
+        WHERE a.level <> ?
+        ORDER BY a.changed_at DESC, a.product_id`, model.StockLevelOK)
+	return alerts, err
+}
+
+// PublishOutbox захватывает до limit неопубликованных событий в порядке записи, передаёт
+// их publish и удаляет, если publish успешен. Реплики публикуют параллельно: захваченные
+// другими строки пропускаются (SKIP LOCKED). Возвращает число опубликованных событий.
+func (r *InventoryRepository) PublishOutbox(ctx context.Context, limit int, publish func([]model.OutboxEvent) error) (int, error) {
+	var events []model.OutboxEvent
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := tx.QueryContext(ctx, &events, `
+            SELECT * FROM outbox_events
+            ORDER BY id
+            LIMIT ?
+            FOR UPDATE SKIP LOCKED`, limit)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		if err := publish(events); err != nil {
+			return err
+		}
+
+		ids := make([]int64, 0, len(events))
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM outbox_events WHERE id IN (?)`, pg.In(ids))
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
@@ -0,0 +1,72 @@
+// internal/repository/dead_letter.go
+package repository
+
+import (
+	"context"
+
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type DeadLetterRepository struct {
+	db *pg.DB
+}
+
+func NewDeadLetterRepository(db *pg.DB) *DeadLetterRepository {
+	return &DeadLetterRepository{db: db}
+}
+
+// Save сохраняет сообщение из DLQ. Повторная доставка того же сообщения — no-op.
+func (r *DeadLetterRepository) Save(ctx context.Context, dl *model.DeadLetter) error {
+	_, err := r.db.ModelContext(ctx, dl).
+		ExcludeColumn("id", "replay_count", "replayed_at", "created_at").
+		OnConflict("(dlq_topic, dlq_partition, dlq_offset) DO NOTHING").
+		Insert()
+	return err
+}
+
+// List возвращает записи от новых к старым.
+func (r *DeadLetterRepository) List(ctx context.Context, filter model.DeadLetterFilter) ([]model.DeadLetter, error) {
+	var letters []model.DeadLetter
+	q := r.db.ModelContext(ctx, &letters).Order("id DESC").Limit(filter.Limit)
+	if filter.Topic != "" {
+		q = q.Where("topic = ?", filter.Topic)
+	}
+	if filter.Replayed != nil {
+		if *filter.Replayed {
+			q = q.Where("replayed_at IS NOT NULL")
+		} else {
+			q = q.Where("replayed_at IS NULL")
+		}
+	}
+	if filter.BeforeID > 0 {
+		q = q.Where("id < ?", filter.BeforeID)
+	}
+	if err := q.Select(); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+func (r *DeadLetterRepository) Get(ctx context.Context, id int64) (*model.DeadLetter, error) {
+	dl := new(model.DeadLetter)
+	err := r.db.ModelContext(ctx, dl).Where("id = ?", id).Select()
+	if err == pg.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dl, nil
+}
+
+// MarkReplayed отмечает, что сообщение отправлено в исходный топик ещё раз.
+func (r *DeadLetterRepository) MarkReplayed(ctx context.Context, dl *model.DeadLetter) error {
+	_, err := r.db.QueryOneContext(ctx, pg.Scan(&dl.ReplayCount, &dl.ReplayedAt), `
+        UPDATE dead_letters
+        SET replay_count = replay_count + 1, replayed_at = now()
+        WHERE id = ?
+        RETURNING replay_count, replayed_at`, dl.ID)
+	return err
+}
@@ -0,0 +1,14 @@
+// internal/repository/errors.go
+package repository
+
+import "errors"
+
+var (
+	ErrNotFound          = errors.New("not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrVersionConflict   = errors.New("stock was changed concurrently")
+	ErrDuplicateEvent    = errors.New("event was already processed")
+	ErrAlreadyExists     = errors.New("already exists")
+	ErrAlreadyUndone     = errors.New("already undone")
+	ErrOrderNotReserved  = errors.New("order.created of the order is not processed yet")
+)
@@ -0,0 +1,249 @@
+// internal/repository/import.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// PreviewImport заполняет у строк без ошибок остаток до и после импорта по текущим
+// остаткам и ставит ошибку строкам, после которых quantity стала бы меньше удержанного.
+// Остатки читаются пачками по chunkSize товаров.
+func (r *InventoryRepository) PreviewImport(ctx context.Context, rows []model.StockImportRow, chunkSize int) error {
+	var productIDs []int64
+	seen := make(map[int64]bool)
+	for _, row := range rows {
+		if row.Error == "" && !seen[row.ProductID] {
+			seen[row.ProductID] = true
+			productIDs = append(productIDs, row.ProductID)
+		}
+	}
+
+	current := make(map[stockKey]model.Stock, len(rows))
+	for start := 0; start < len(productIDs); start += chunkSize {
+		stocks, err := r.GetStocks(ctx, productIDs[start:min(start+chunkSize, len(productIDs))])
+		if err != nil {
+			return err
+		}
+		for _, s := range stocks {
+			current[stockKey{s.WarehouseCode, s.ProductID}] = s
+		}
+	}
+
+	for i := range rows {
+		if rows[i].Error == "" {
+			evaluateImportRow(&rows[i], current[stockKey{rows[i].WarehouseCode, rows[i].ProductID}])
+		}
+	}
+	return nil
+}
+
+type stockKey struct {
+	warehouse string
+	product   int64
+}
+
+// evaluateImportRow считает остаток после строки относительно current
+// (нулевой Stock — товара на складе нет).
+func evaluateImportRow(row *model.StockImportRow, current model.Stock) {
+	row.Before, row.Reserved = current.Quantity, current.Reserved
+	row.After = row.Quantity
+	if row.Mode == model.ImportModeAdd {
+		row.After = current.Quantity + row.Quantity
+	}
+	if row.After < current.Reserved {
+		row.Error = fmt.Sprintf("quantity %d is below the %d reserved units", row.After, current.Reserved)
+	}
+}
+
+// ImportStock применяет проверенные строки импорта пачками по chunkSize, каждая пачка —
+// в своей транзакции: строки stock блокируются, остаток пересчитывается под блокировкой,
+// изменения пишутся одним upsert, движения — в журнал с reference import:<id>.
+// Если пачка не применилась, предыдущие остаются: импорт получает статус partial
+// и текст ошибки, а откатить можно уже применённое.
+func (r *InventoryRepository) ImportStock(ctx context.Context, imp *model.StockImport, rows []model.StockImportRow, chunkSize int) error {
+	imp.Status = model.ImportPartial // до конца последней пачки
+	if _, err := r.db.ModelContext(ctx, imp).Returning("*").Insert(); err != nil {
+		return err
+	}
+
+	for start := 0; start < len(rows); start += chunkSize {
+		chunk := rows[start:min(start+chunkSize, len(rows))]
+		err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+			return importChunk(ctx, tx, imp, chunk)
+		})
+		if err != nil {
+			imp.Error = fmt.Sprintf("rows %d-%d: %v", start+1, start+len(chunk), err)
+			_, err = r.db.ModelContext(ctx, imp).Column("error").WherePK().Update()
+			return err
+		}
+		imp.AppliedRows += len(chunk)
+		if _, err := r.db.ModelContext(ctx, imp).Column("applied_rows").WherePK().Update(); err != nil {
+			return err
+		}
+	}
+
+	imp.Status = model.ImportApplied
+	_, err := r.db.ModelContext(ctx, imp).Column("status").WherePK().Update()
+	return err
+}
+
+func importChunk(ctx context.Context, tx *pg.Tx, imp *model.StockImport, rows []model.StockImportRow) error {
+	// Блокируем строки в порядке (склад, товар), как при удержании заказов
+	rows = append([]model.StockImportRow(nil), rows...)
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].WarehouseCode != rows[j].WarehouseCode {
+			return rows[i].WarehouseCode < rows[j].WarehouseCode
+		}
+		return rows[i].ProductID < rows[j].ProductID
+	})
+	keys := make([][]any, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, []any{row.WarehouseCode, row.ProductID})
+	}
+
+	var locked []model.Stock
+	if _, err := tx.QueryContext(ctx, &locked, `
+        SELECT * FROM stock
+        WHERE (warehouse_code, product_id) IN (?)
+        ORDER BY warehouse_code, product_id
+        FOR UPDATE`, pg.In(keys)); err != nil {
+		return err
+	}
+	current := make(map[stockKey]model.Stock, len(locked))
+	for _, s := range locked {
+		current[stockKey{s.WarehouseCode, s.ProductID}] = s
+	}
+
+	var upserts []model.Stock
+	var changed []model.StockImportRow
+	for _, row := range rows {
+		evaluateImportRow(&row, current[stockKey{row.WarehouseCode, row.ProductID}])
+		if row.Error != "" {
+			return fmt.Errorf("%w: line %d: %s", ErrInsufficientStock, row.Line, row.Error)
+		}
+		if row.Delta() == 0 {
+			continue
+		}
+		upserts = append(upserts, model.Stock{WarehouseCode: row.WarehouseCode, ProductID: row.ProductID, Quantity: row.After})
+		changed = append(changed, row)
+	}
+	if len(upserts) == 0 {
+		return nil
+	}
+
+	if _, err := tx.ModelContext(ctx, &upserts).
+		OnConflict("(warehouse_code, product_id) DO UPDATE").
+		Set("quantity = EXCLUDED.quantity, version = stock.version + 1, updated_at = now()").
+		Insert(); err != nil {
+		return err
+	}
+
+	ref := reference("import", imp.ID)
+	for _, row := range changed {
+		if err := recordMovement(ctx, tx, model.StockMovement{
+			WarehouseCode: row.WarehouseCode, ProductID: row.ProductID, Type: model.MovementImport,
+			QuantityDelta: row.Delta(), Reference: ref, UserID: imp.UserID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *InventoryRepository) GetImport(ctx context.Context, id int64) (*model.StockImport, error) {
+	imp := new(model.StockImport)
+	err := r.db.ModelContext(ctx, imp).Where("id = ?", id).Select()
+	if err == pg.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// UndoImport откатывает импорт одной транзакцией: вычитает из остатков суммы его движений
+// по журналу. Изменения после импорта (заказы, корректировки) сохраняются; если после
+// отката quantity стала бы меньше удержанного, не откатывается ничего.
+func (r *InventoryRepository) UndoImport(ctx context.Context, id, userID int64) (*model.StockImport, error) {
+	imp := new(model.StockImport)
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		err := tx.ModelContext(ctx, imp).Where("id = ?", id).For("UPDATE").Select()
+		if err == pg.ErrNoRows {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if imp.Status == model.ImportUndone {
+			return fmt.Errorf("%w: import %d", ErrAlreadyUndone, id)
+		}
+
+		ref := reference("import", id)
+		var lines []model.StockLine
+		if _, err := tx.QueryContext(ctx, &lines, `
+            SELECT warehouse_code, product_id, SUM(quantity_delta) AS quantity
+            FROM stock_movements
+            WHERE reference = ? AND type = ?
+            GROUP BY warehouse_code, product_id
+            ORDER BY warehouse_code, product_id`,
+			ref, model.MovementImport); err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			if line.Quantity == 0 {
+				continue
+			}
+			res, err := tx.ExecContext(ctx, `
+                UPDATE stock
+                SET quantity = quantity - ?, version = version + 1, updated_at = now()
+                WHERE warehouse_code = ? AND product_id = ? AND quantity - ? >= reserved`,
+				line.Quantity, line.WarehouseCode, line.ProductID, line.Quantity)
+			if err != nil {
+				return err
+			}
+			if res.RowsAffected() == 0 {
+				return fmt.Errorf("%w for product %d in warehouse %s: %d units cannot be taken back",
+					ErrInsufficientStock, line.ProductID, line.WarehouseCode, line.Quantity)
+			}
+			if err := recordMovement(ctx, tx, model.StockMovement{
+				WarehouseCode: line.WarehouseCode, ProductID: line.ProductID, Type: model.MovementImportUndo,
+				QuantityDelta: -line.Quantity, Reference: ref, UserID: userID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.QueryOneContext(ctx, imp, `
+            UPDATE stock_imports SET status = ?, undone_at = now(), undone_by = ?
+            WHERE id = ?
+            RETURNING *`,
+			model.ImportUndone, userID, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return imp, nil
+}
+
+// ExportStock пишет остатки в w как CSV в формате импорта (mode = set), не загружая
+// их в память: COPY отдаёт строки потоком. warehouse пуст — все склады.
+func (r *InventoryRepository) ExportStock(ctx context.Context, w io.Writer, warehouse string) error {
+	_, err := r.db.WithContext(ctx).CopyTo(w, `
+        COPY (
+            SELECT product_id, warehouse_code AS warehouse, quantity, 'set' AS mode
+            FROM stock
+            WHERE ?0 = '' OR warehouse_code = ?0
+            ORDER BY product_id, warehouse_code
+        ) TO STDOUT WITH (FORMAT csv, HEADER)`, warehouse)
+	return err
+}
@@ -0,0 +1,120 @@
+// internal/repository/movements.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// recordMovement дописывает m в журнал stock_movements в транзакции tx — той же,
+// что меняет строку stock, — поэтому вызывается после изменения строки. Заодно ставит
+// в outbox stock_changed с новым состоянием строки и пересчитывает уровень остатка товара.
+func recordMovement(ctx context.Context, tx *pg.Tx, m model.StockMovement) error {
+	_, err := tx.QueryOneContext(ctx, pg.Scan(&m.ID, &m.CreatedAt), `
+        INSERT INTO stock_movements
+            (warehouse_code, product_id, type, quantity_delta, reserved_delta, quarantine_delta, order_id, reference, user_id)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+        RETURNING id, created_at`,
+		m.WarehouseCode, m.ProductID, m.Type, m.QuantityDelta, m.ReservedDelta, m.QuarantineDelta,
+		nullID(m.OrderID), m.Reference, nullID(m.UserID))
+	if err != nil {
+		return err
+	}
+
+	change := model.StockChange{Movement: m}
+	if _, err := tx.QueryOneContext(ctx, &change.Stock, `
+        SELECT * FROM stock WHERE warehouse_code = ? AND product_id = ?`,
+		m.WarehouseCode, m.ProductID); err != nil {
+		return err
+	}
+	if err := enqueue(ctx, tx, model.EventStockChanged, m.ProductID, change); err != nil {
+		return err
+	}
+	return checkStockLevel(ctx, tx, m.ProductID)
+}
+
+func nullID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+func reference(kind string, id int64) string {
+	return fmt.Sprintf("%s:%d", kind, id)
+}
+
+// StockHistory возвращает движения товара от новых к старым с остатком склада после каждого.
+func (r *InventoryRepository) StockHistory(ctx context.Context, filter model.StockHistoryFilter) ([]model.StockHistoryEntry, error) {
+	var entries []model.StockHistoryEntry
+	_, err := r.db.QueryContext(ctx, &entries, `
+        SELECT * FROM (
+            SELECT m.*,
+                SUM(quantity_delta) OVER w AS quantity_after,
+                SUM(reserved_delta) OVER w AS reserved_after,
+                SUM(quarantine_delta) OVER w AS quarantine_after
+            FROM stock_movements m
+            WHERE product_id = ?0 AND (?1 = '' OR warehouse_code = ?1)
+            WINDOW w AS (PARTITION BY warehouse_code ORDER BY id)
+        ) h
+        WHERE (?2::timestamptz IS NULL OR created_at >= ?2)
+          AND (?3::timestamptz IS NULL OR created_at < ?3)
+          AND (?4 = 0 OR id < ?4)
+        ORDER BY id DESC
+        LIMIT ?5`,
+		filter.ProductID, filter.WarehouseCode, nullTime(filter.From), nullTime(filter.To), filter.BeforeID, filter.Limit)
+	return entries, err
+}
+
+// StockAsOf восстанавливает остатки товара по складам на момент at из журнала.
+func (r *InventoryRepository) StockAsOf(ctx context.Context, productID int64, at time.Time) ([]model.Stock, error) {
+	var stocks []model.Stock
+	_, err := r.db.QueryContext(ctx, &stocks, `
+        SELECT warehouse_code, product_id,
+            SUM(quantity_delta) AS quantity,
+            SUM(reserved_delta) AS reserved,
+            SUM(quarantine_delta) AS quarantine,
+            MAX(created_at) AS updated_at
+        FROM stock_movements
+        WHERE product_id = ? AND created_at <= ?
+        GROUP BY warehouse_code, product_id
+        ORDER BY warehouse_code`,
+		productID, at)
+	return stocks, err
+}
+
+// Reconcile сравнивает строки stock с суммой журнала и возвращает расхождения.
+// Один запрос — один снимок данных, поэтому параллельные изменения не дают ложных срабатываний.
+func (r *InventoryRepository) Reconcile(ctx context.Context) ([]model.StockDiscrepancy, error) {
+	var discrepancies []model.StockDiscrepancy
+	_, err := r.db.QueryContext(ctx, &discrepancies, `
+        SELECT COALESCE(s.warehouse_code, l.warehouse_code) AS warehouse_code,
+            COALESCE(s.product_id, l.product_id) AS product_id,
+            COALESCE(s.quantity, 0) AS quantity, COALESCE(l.quantity, 0) AS ledger_quantity,
+            COALESCE(s.reserved, 0) AS reserved, COALESCE(l.reserved, 0) AS ledger_reserved,
+            COALESCE(s.quarantine, 0) AS quarantine, COALESCE(l.quarantine, 0) AS ledger_quarantine
+        FROM stock s
+        FULL JOIN (
+            SELECT warehouse_code, product_id,
+                SUM(quantity_delta) AS quantity, SUM(reserved_delta) AS reserved, SUM(quarantine_delta) AS quarantine
+            FROM stock_movements
+            GROUP BY warehouse_code, product_id
+        ) l ON l.warehouse_code = s.warehouse_code AND l.product_id = s.product_id
+        WHERE COALESCE(s.quantity, 0) <> COALESCE(l.quantity, 0)
+           OR COALESCE(s.reserved, 0) <> COALESCE(l.reserved, 0)
+           OR COALESCE(s.quarantine, 0) <> COALESCE(l.quarantine, 0)
+        ORDER BY 1, 2`)
+	return discrepancies, err
+}
+
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
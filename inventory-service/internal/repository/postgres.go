@@ -18,42 +18,284 @@ func NewInventoryRepository(db *pg.DB) *InventoryRepository {
 	return &InventoryRepository{db: db}
 }
 
-func (r *InventoryRepository) GetStock(ctx context.Context, productID int64) (*model.Stock, error) {
-	stock := &model.Stock{ProductID: productID}
-	err := r.db.Model(stock).Where("product_id = ?", productID).Select()
-	if err != nil {
-		if err == pg.ErrNoRows {
-			return nil, nil // товар ещё не заведён
+// GetStock возвращает остатки товара по складам; пусто — товар ещё не заведён.
+func (r *InventoryRepository) GetStock(ctx context.Context, productID int64) ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := r.db.ModelContext(ctx, &stocks).
+		Where("product_id = ?", productID).
+		Order("warehouse_code").
+		Select()
+	return stocks, err
+}
+
+// CancelOrder — компенсация отмены заказа: снимает удержания неоплаченного заказа
+// и возвращает на склад всё, что было списано под оплаченный.
+// Заказ, удержания которого уже сняты или который был отклонён, — no-op (false);
+// заказ, чей order.created ещё не обработан, — ErrOrderNotReserved (повтор).
+func (r *InventoryRepository) CancelOrder(ctx context.Context, event model.EventRef, orderID int64) (bool, error) {
+	returned := false
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := markProcessed(ctx, tx, event); err != nil {
+			return err
+		}
+
+		var held []model.StockLine
+		_, err := tx.QueryContext(ctx, &held, `
+            UPDATE reservations SET status = ?, updated_at = now()
+            WHERE order_id = ? AND status = ?
+            RETURNING warehouse_code, product_id, quantity`,
+			model.ReservationReleased, orderID, model.ReservationHeld)
+		if err != nil {
+			return err
+		}
+		if err := unreserve(ctx, tx, held); err != nil {
+			return err
+		}
+		for _, line := range held {
+			if err := recordMovement(ctx, tx, model.StockMovement{
+				WarehouseCode: line.WarehouseCode, ProductID: line.ProductID, Type: model.MovementRelease,
+				ReservedDelta: -line.Quantity, OrderID: orderID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		var deducted []model.StockLine
+		_, err = tx.QueryContext(ctx, &deducted, `
+            DELETE FROM order_stock
+            WHERE order_id = ?
+            RETURNING warehouse_code, product_id, quantity`, orderID)
+		if err != nil {
+			return err
+		}
+		for _, line := range mergeLines(deducted) {
+			if _, err := tx.ExecContext(ctx, `
+                UPDATE stock
+                SET quantity = quantity + ?, version = version + 1, updated_at = now()
+                WHERE warehouse_code = ? AND product_id = ?`,
+				line.Quantity, line.WarehouseCode, line.ProductID); err != nil {
+				return err
+			}
+			if err := recordMovement(ctx, tx, model.StockMovement{
+				WarehouseCode: line.WarehouseCode, ProductID: line.ProductID, Type: model.MovementRestock,
+				QuantityDelta: line.Quantity, OrderID: orderID,
+			}); err != nil {
+				return err
+			}
+		}
+		returned = len(held) > 0 || len(deducted) > 0
+		if !returned {
+			return requireKnownOrder(ctx, tx, orderID)
+		}
+		return nil
+	})
+	return returned, err
+}
+
+// RestockReturn кладёт принятый возврат в остатки или, если quarantine, в карантин —
+// на склад, с которого товар заказа был отгружен, иначе на склад из позиции.
+// Каждый возврат применяется один раз: повтор события — no-op (false).
+func (r *InventoryRepository) RestockReturn(ctx context.Context, event model.EventRef, orderID, returnID int64, lines []model.StockLine, quarantine bool) (bool, error) {
+	restocked := false
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := markProcessed(ctx, tx, event); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `
+            INSERT INTO return_restocks (return_id) VALUES (?)
+            ON CONFLICT (return_id) DO NOTHING`, returnID)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return nil // уже применён
+		}
+
+		// При split shipment товар шёл с нескольких складов — берём тот, откуда больше
+		var shipped []model.StockLine
+		if _, err := tx.QueryContext(ctx, &shipped, `
+            SELECT DISTINCT ON (product_id) warehouse_code, product_id, quantity
+            FROM order_stock
+            WHERE order_id = ?
+            ORDER BY product_id, quantity DESC, warehouse_code`, orderID); err != nil {
+			return err
+		}
+		for i := range lines {
+			for _, s := range shipped {
+				if s.ProductID == lines[i].ProductID {
+					lines[i].WarehouseCode = s.WarehouseCode
+				}
+			}
+		}
+
+		for _, line := range mergeLines(lines) {
+			quantity, quarantined := line.Quantity, 0
+			if quarantine {
+				quantity, quarantined = 0, line.Quantity
+			}
+			if _, err := tx.ExecContext(ctx, `
+                INSERT INTO stock (warehouse_code, product_id, quantity, quarantine)
+                VALUES (?, ?, ?, ?)
+                ON CONFLICT (warehouse_code, product_id) DO UPDATE
+                SET quantity = stock.quantity + EXCLUDED.quantity,
+                    quarantine = stock.quarantine + EXCLUDED.quarantine,
+                    version = stock.version + 1,
+                    updated_at = now()`,
+				line.WarehouseCode, line.ProductID, quantity, quarantined); err != nil {
+				return err
+			}
+			if err := recordMovement(ctx, tx, model.StockMovement{
+				WarehouseCode: line.WarehouseCode, ProductID: line.ProductID, Type: model.MovementReturn,
+				QuantityDelta: quantity, QuarantineDelta: quarantined, OrderID: orderID,
+				Reference: reference("return", returnID),
+			}); err != nil {
+				return err
+			}
 		}
+		restocked = true
+		return nil
+	})
+	return restocked, err
+}
+
+// GetStocks возвращает остатки заведённых товаров из productIDs по складам,
+// по возрастанию product_id.
+func (r *InventoryRepository) GetStocks(ctx context.Context, productIDs []int64) ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := r.db.ModelContext(ctx, &stocks).
+		WhereIn("product_id IN (?)", productIDs).
+		Order("product_id", "warehouse_code").
+		Select()
+	return stocks, err
+}
+
+// AdjustStock меняет остаток товара на складе adj.WarehouseCode на adj.Delta и пишет adj
+// в журнал корректировок. Приёмка заводит товар на складе, если его там ещё нет;
+// опуститься ниже удержанного остаток не может.
+func (r *InventoryRepository) AdjustStock(ctx context.Context, adj *model.StockAdjustment) (*model.Stock, error) {
+	stock := new(model.Stock)
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		var err error
+		if adj.Delta > 0 {
+			_, err = tx.QueryOneContext(ctx, stock, `
+                INSERT INTO stock (warehouse_code, product_id, quantity)
+                VALUES (?, ?, ?)
+                ON CONFLICT (warehouse_code, product_id) DO UPDATE
+                SET quantity = stock.quantity + EXCLUDED.quantity,
+                    version = stock.version + 1,
+                    updated_at = now()
+                RETURNING *`,
+				adj.WarehouseCode, adj.ProductID, adj.Delta)
+		} else {
+			_, err = tx.QueryOneContext(ctx, stock, `
+                UPDATE stock
+                SET quantity = quantity + ?, version = version + 1, updated_at = now()
+                WHERE warehouse_code = ? AND product_id = ? AND quantity + ? >= reserved
+                RETURNING *`,
+				adj.Delta, adj.WarehouseCode, adj.ProductID, adj.Delta)
+			if err == pg.ErrNoRows {
+				return r.missingOr(ctx, tx, adj.WarehouseCode, adj.ProductID, ErrInsufficientStock)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		adj.QuantityAfter = stock.Quantity
+		return insertAdjustment(ctx, tx, adj)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return stock, nil
 }
 
-func (r *InventoryRepository) DeductStock(ctx context.Context, productID int64, quantity int) error {
-	// Используем UPDATE с проверкой, чтобы избежать отрицательных остатков
-	res, err := r.db.ExecContext(ctx, `
-        UPDATE stock
-        SET quantity = quantity - ?
-        WHERE product_id = ? AND quantity >= ?`,
-		quantity, productID, quantity)
+// SetStock выставляет абсолютный остаток товара на складе, если версия строки всё ещё
+// version (оптимистичная блокировка). version == 0 — товар заводится на складе впервые.
+// Разница со старым остатком пишется в журнал как adj.
+func (r *InventoryRepository) SetStock(ctx context.Context, quantity int, version int64, adj *model.StockAdjustment) (*model.Stock, error) {
+	stock := new(model.Stock)
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		before := 0
+		if version == 0 {
+			_, err := tx.QueryOneContext(ctx, stock, `
+                INSERT INTO stock (warehouse_code, product_id, quantity)
+                VALUES (?, ?, ?)
+                ON CONFLICT (warehouse_code, product_id) DO NOTHING
+                RETURNING *`,
+				adj.WarehouseCode, adj.ProductID, quantity)
+			if err == pg.ErrNoRows {
+				return ErrVersionConflict // товар уже заведён
+			}
+			if err != nil {
+				return err
+			}
+		} else {
+			var current model.Stock
+			_, err := tx.QueryOneContext(ctx, &current, `
+                SELECT * FROM stock WHERE warehouse_code = ? AND product_id = ? FOR UPDATE`,
+				adj.WarehouseCode, adj.ProductID)
+			if err == pg.ErrNoRows {
+				return ErrNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if current.Version != version {
+				return ErrVersionConflict
+			}
+			if quantity < current.Reserved {
+				return fmt.Errorf("%w: %d units of product %d are reserved", ErrInsufficientStock, current.Reserved, adj.ProductID)
+			}
+			before = current.Quantity
 
+			if _, err := tx.QueryOneContext(ctx, stock, `
+                UPDATE stock
+                SET quantity = ?, version = version + 1, updated_at = now()
+                WHERE warehouse_code = ? AND product_id = ?
+                RETURNING *`,
+				quantity, adj.WarehouseCode, adj.ProductID); err != nil {
+				return err
+			}
+		}
+
+		adj.Delta = quantity - before
+		adj.QuantityAfter = quantity
+		return insertAdjustment(ctx, tx, adj)
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return stock, nil
+}
 
-	if res.RowsAffected() == 0 {
-		return fmt.Errorf("insufficient stock for product %d", productID)
+// missingOr возвращает ErrNotFound, если товар не заведён на складе, иначе err.
+func (r *InventoryRepository) missingOr(ctx context.Context, tx *pg.Tx, warehouseCode string, productID int64, err error) error {
+	exists, qErr := tx.ModelContext(ctx, (*model.Stock)(nil)).
+		Where("warehouse_code = ? AND product_id = ?", warehouseCode, productID).
+		Exists()
+	if qErr != nil {
+		return qErr
 	}
-
-	return nil
+	if !exists {
+		return ErrNotFound
+	}
+	return err
 }
 
-func (r *InventoryRepository) EnsureStock(ctx context.Context, productID int64, initialQty int) error {
-	_, err := r.db.ExecContext(ctx, `
-        INSERT INTO stock (product_id, quantity)
-        VALUES (?, ?)
-        ON CONFLICT (product_id) DO NOTHING`,
-		productID, initialQty)
-	return err
+// insertAdjustment пишет корректировку в stock_adjustments и движение в журнал.
+func insertAdjustment(ctx context.Context, tx *pg.Tx, adj *model.StockAdjustment) error {
+	_, err := tx.QueryOneContext(ctx, pg.Scan(&adj.ID, &adj.CreatedAt), `
+        INSERT INTO stock_adjustments (warehouse_code, product_id, type, delta, quantity_after, reason, note, user_id)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        RETURNING id, created_at`,
+		adj.WarehouseCode, adj.ProductID, adj.Type, adj.Delta, adj.QuantityAfter, adj.Reason, adj.Note, adj.UserID)
+	if err != nil {
+		return err
+	}
+	return recordMovement(ctx, tx, model.StockMovement{
+		WarehouseCode: adj.WarehouseCode, ProductID: adj.ProductID, Type: adj.Type,
+		QuantityDelta: adj.Delta, Reference: reference("adjustment", adj.ID), UserID: adj.UserID,
+	})
 }
@@ -0,0 +1,44 @@
+// internal/repository/processed_events.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// markProcessed записывает событие в processed_events в транзакции tx, до изменения
+// остатков. Если событие уже обработано, возвращает ErrDuplicateEvent, и транзакция
+// откатывается, ничего не изменив.
+func markProcessed(ctx context.Context, tx *pg.Tx, event model.EventRef) error {
+	if event.Key == "" {
+		return nil
+	}
+	res, err := tx.ExecContext(ctx, `
+        INSERT INTO processed_events (event_key, event_id, topic)
+        VALUES (?, ?, ?)
+        ON CONFLICT (event_key) DO NOTHING`,
+		event.Key, event.ID, event.Topic)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return ErrDuplicateEvent
+	}
+	return nil
+}
+
+// PurgeProcessedEvents удаляет записи старше olderThan: так давно сообщения Kafka уже не хранит.
+func (r *InventoryRepository) PurgeProcessedEvents(ctx context.Context, olderThan time.Duration) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+        DELETE FROM processed_events
+        WHERE processed_at < now() - ? * interval '1 millisecond'`,
+		olderThan.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,103 @@
+// internal/repository/product.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// likeEscaper экранирует спецсимволы LIKE в поисковой строке.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchProducts ищет по подстроке названия или префиксу SKU, новые товары — первыми.
+func (r *InventoryRepository) SearchProducts(ctx context.Context, filter model.ProductFilter) ([]model.Product, error) {
+	var active any
+	if filter.Active != nil {
+		active = *filter.Active
+	}
+
+	var products []model.Product
+	_, err := r.db.QueryContext(ctx, &products, `
+        SELECT * FROM products
+        WHERE (?0 = '' OR name ILIKE '%' || ?0 || '%' OR sku ILIKE ?0 || '%')
+          AND (?1::boolean IS NULL OR active = ?1)
+          AND (?2 = 0 OR id < ?2)
+        ORDER BY id DESC
+        LIMIT ?3`,
+		likeEscaper.Replace(filter.Query), active, filter.BeforeID, filter.Limit)
+	return products, err
+}
+
+func (r *InventoryRepository) GetProduct(ctx context.Context, id int64) (*model.Product, error) {
+	p := new(model.Product)
+	err := r.db.ModelContext(ctx, p).Where("id = ?", id).Select()
+	if err == pg.ErrNoRows {
+		return nil, fmt.Errorf("product %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// GetProducts возвращает найденные товары из ids по возрастанию id; отсутствующих просто нет в ответе.
+func (r *InventoryRepository) GetProducts(ctx context.Context, ids []int64) ([]model.Product, error) {
+	var products []model.Product
+	err := r.db.ModelContext(ctx, &products).Where("id IN (?)", pg.In(ids)).Order("id").Select()
+	return products, err
+}
+
+// CreateProduct заводит товар. Если p.ID задан (товар уже есть в остатках и заказах),
+// он сохраняется как есть, а последовательность id сдвигается за него.
+func (r *InventoryRepository) CreateProduct(ctx context.Context, p *model.Product) error {
+	var id any
+	if p.ID != 0 {
+		id = p.ID
+	}
+
+	return r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := tx.QueryOneContext(ctx, p, `
+            INSERT INTO products (id, sku, name, description, price, currency, active)
+            VALUES (COALESCE(?, nextval(pg_get_serial_sequence('products', 'id'))), ?, ?, ?, ?, ?, ?)
+            ON CONFLICT DO NOTHING
+            RETURNING *`,
+			id, p.SKU, p.Name, p.Description, p.Price, p.Currency, p.Active)
+		if err == pg.ErrNoRows {
+			return fmt.Errorf("product with id %d or sku %s: %w", p.ID, p.SKU, ErrAlreadyExists)
+		}
+		if err != nil || id == nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+            SELECT setval(pg_get_serial_sequence('products', 'id'), max(id)) FROM products`)
+		return err
+	})
+}
+
+// UpdateProduct меняет всё, кроме id.
+func (r *InventoryRepository) UpdateProduct(ctx context.Context, p *model.Product) error {
+	_, err := r.db.QueryOneContext(ctx, p, `
+        UPDATE products
+        SET sku = ?, name = ?, description = ?, price = ?, currency = ?, active = ?, updated_at = now()
+        WHERE id = ?
+        RETURNING *`,
+		p.SKU, p.Name, p.Description, p.Price, p.Currency, p.Active, p.ID)
+	if err == pg.ErrNoRows {
+		return fmt.Errorf("product %d: %w", p.ID, ErrNotFound)
+	}
+	if isUniqueViolation(err) {
+		return fmt.Errorf("product with sku %s: %w", p.SKU, ErrAlreadyExists)
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr pg.Error
+	return errors.As(err, &pgErr) && pgErr.Field('C') == "23505"
+}
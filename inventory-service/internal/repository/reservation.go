@@ -0,0 +1,334 @@
+// internal/repository/reservation.go
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"inventory-service/internal/allocation"
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+// ReserveOrder раскладывает заказ по складам стратегией strategy и удерживает товар
+// на ttl: reserved растёт, quantity не меняется. Либо удерживаются все позиции, либо
+// ни одной. Возвращает удержанные позиции со складами; повтор заказа — no-op (nil).
+func (r *InventoryRepository) ReserveOrder(ctx context.Context, event model.EventRef, req allocation.Request, ttl time.Duration, strategy allocation.Strategy) ([]model.StockLine, error) {
+	var reserved []model.StockLine
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := markProcessed(ctx, tx, event); err != nil {
+			return err
+		}
+
+		var seen bool
+		_, err := tx.QueryOneContext(ctx, pg.Scan(&seen), `
+            SELECT EXISTS (SELECT 1 FROM reservations WHERE order_id = ?)
+                OR EXISTS (SELECT 1 FROM order_stock WHERE order_id = ?)`,
+			req.OrderID, req.OrderID)
+		if err != nil {
+			return err
+		}
+		if seen {
+			return nil
+		}
+
+		req.Lines = mergeLines(req.Lines)
+		candidates, err := lockCandidates(ctx, tx, req.Lines)
+		if err != nil {
+			return err
+		}
+		lines, err := strategy.Allocate(req, candidates)
+		if errors.Is(err, allocation.ErrUnavailable) {
+			return fmt.Errorf("%w: %w", ErrInsufficientStock, err)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, line := range mergeLines(lines) {
+			res, err := tx.ExecContext(ctx, `
+                UPDATE stock
+                SET reserved = reserved + ?, version = version + 1, updated_at = now()
+                WHERE warehouse_code = ? AND product_id = ? AND quantity - reserved >= ?`,
+				line.Quantity, line.WarehouseCode, line.ProductID, line.Quantity)
+			if err != nil {
+				return err
+			}
+			if res.RowsAffected() == 0 {
+				return fmt.Errorf("%w for product %d in warehouse %s", ErrInsufficientStock, line.ProductID, line.WarehouseCode)
+			}
+
+			if _, err := tx.ExecContext(ctx, `
+                INSERT INTO reservations (order_id, warehouse_code, product_id, quantity, status, expires_at)
+                VALUES (?, ?, ?, ?, ?, now() + ? * interval '1 millisecond')`,
+				req.OrderID, line.WarehouseCode, line.ProductID, line.Quantity, model.ReservationHeld, ttl.Milliseconds()); err != nil {
+				return err
+			}
+			if err := recordMovement(ctx, tx, model.StockMovement{
+				WarehouseCode: line.WarehouseCode, ProductID: line.ProductID, Type: model.MovementReserve,
+				ReservedDelta: line.Quantity, OrderID: req.OrderID,
+			}); err != nil {
+				return err
+			}
+			reserved = append(reserved, line)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reserved, nil
+}
+
+// RejectOrder отмечает, что товар заказа не удалось удержать, и ставит в outbox
+// reservation_failed — по нему order-service отменит заказ. Событие, на котором не
+// хватило товара (order.created или order.paid), записывается обработанным в той же
+// транзакции. Повтор — ErrDuplicateEvent.
+func (r *InventoryRepository) RejectOrder(ctx context.Context, event model.EventRef, orderID int64, reason string) error {
+	return r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := markProcessed(ctx, tx, event); err != nil {
+			return err
+		}
+
+		rejection := model.OrderRejection{OrderID: orderID, Reason: reason}
+		res, err := tx.QueryContext(ctx, pg.Scan(&rejection.CreatedAt), `
+            INSERT INTO order_rejections (order_id, reason) VALUES (?, ?)
+            ON CONFLICT (order_id) DO NOTHING
+            RETURNING created_at`, orderID, reason)
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected() == 0 {
+			return nil // уже отклонён
+		}
+		return enqueue(ctx, tx, model.EventReservationFailed, orderID, rejection)
+	})
+}
+
+// lockCandidates блокирует строки stock с товарами заказа на активных складах
+// и возвращает их свободный остаток. Строки блокируются в порядке (склад, товар),
+// чтобы параллельные заказы не ловили deadlock.
+func lockCandidates(ctx context.Context, tx *pg.Tx, lines []model.StockLine) ([]allocation.Candidate, error) {
+	productIDs := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		productIDs = append(productIDs, line.ProductID)
+	}
+
+	var rows []struct {
+		model.Warehouse
+		ProductID int64
+		Available int
+	}
+	_, err := tx.QueryContext(ctx, &rows, `
+        SELECT w.*, s.product_id, s.quantity - s.reserved AS available
+        FROM stock s
+        JOIN warehouses w ON w.code = s.warehouse_code
+        WHERE s.product_id IN (?) AND w.active
+        ORDER BY s.warehouse_code, s.product_id
+        FOR UPDATE OF s`, pg.In(productIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]allocation.Candidate, 0, len(rows))
+	for _, row := range rows {
+		candidates = append(candidates, allocation.Candidate{Warehouse: row.Warehouse, ProductID: row.ProductID, Available: row.Available})
+	}
+	return candidates, nil
+}
+
+// CommitOrder списывает удержанный под оплаченный заказ товар с тех складов, где он
+// удержан; списанное попадает в order_stock, откуда его вернёт CancelOrder. Если
+// удержание уже снято по TTL, товар списывается из свободного остатка того же склада,
+// когда его хватает, а когда нет — ErrInsufficientStock, и вся транзакция откатывается.
+// Повтор события, уже списанный, снятый или отклонённый заказ — no-op (false).
+// Заказ, о котором ещё ничего не известно (order.created ждёт повтора), —
+// ErrOrderNotReserved: событие не отмечается обработанным и придёт снова.
+func (r *InventoryRepository) CommitOrder(ctx context.Context, event model.EventRef, orderID int64) (bool, error) {
+	committed := false
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		if err := markProcessed(ctx, tx, event); err != nil {
+			return err
+		}
+
+		var reservations []model.Reservation
+		_, err := tx.QueryContext(ctx, &reservations, `
+            SELECT * FROM reservations
+            WHERE order_id = ? AND status IN (?, ?)
+            ORDER BY warehouse_code, product_id
+            FOR UPDATE`,
+			orderID, model.ReservationHeld, model.ReservationExpired)
+		if err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			return requireKnownOrder(ctx, tx, orderID)
+		}
+
+		for _, res := range reservations {
+			movement := model.StockMovement{
+				WarehouseCode: res.WarehouseCode, ProductID: res.ProductID, Type: model.MovementDeduct,
+				QuantityDelta: -res.Quantity, ReservedDelta: -res.Quantity, OrderID: orderID,
+			}
+			query := `
+                UPDATE stock
+                SET quantity = quantity - ?, reserved = reserved - ?, version = version + 1, updated_at = now()
+                WHERE warehouse_code = ? AND product_id = ?`
+			args := []any{res.Quantity, res.Quantity, res.WarehouseCode, res.ProductID}
+			if res.Status == model.ReservationExpired {
+				// Удержание снято по TTL: товар мог уйти другим заказам, проверяем свободный остаток
+				query = `
+                UPDATE stock
+                SET quantity = quantity - ?, version = version + 1, updated_at = now()
+                WHERE warehouse_code = ? AND product_id = ? AND quantity - reserved >= ?`
+				args = []any{res.Quantity, res.WarehouseCode, res.ProductID, res.Quantity}
+				movement.ReservedDelta = 0
+			}
+			result, err := tx.ExecContext(ctx, query, args...)
+			if err != nil {
+				return err
+			}
+			if result.RowsAffected() == 0 {
+				return fmt.Errorf("%w for product %d in warehouse %s: reservation of order %d expired",
+					ErrInsufficientStock, res.ProductID, res.WarehouseCode, orderID)
+			}
+
+			if _, err := tx.ExecContext(ctx, `
+                INSERT INTO order_stock (order_id, warehouse_code, product_id, quantity)
+                VALUES (?, ?, ?, ?)`,
+				orderID, res.WarehouseCode, res.ProductID, res.Quantity); err != nil {
+				return err
+			}
+			if err := recordMovement(ctx, tx, movement); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE reservations SET status = ?, updated_at = now()
+            WHERE order_id = ? AND status IN (?, ?)`,
+			model.ReservationCommitted, orderID, model.ReservationHeld, model.ReservationExpired); err != nil {
+			return err
+		}
+		committed = true
+		return nil
+	})
+	return committed, err
+}
+
+// requireKnownOrder возвращает ErrOrderNotReserved, если по заказу нет ни удержаний
+// (в любом статусе), ни списаний, ни отказа: его order.created ещё не обработан.
+// Транзакция откатывается вместе с отметкой события, и событие повторяется.
+func requireKnownOrder(ctx context.Context, tx *pg.Tx, orderID int64) error {
+	var known bool
+	_, err := tx.QueryOneContext(ctx, pg.Scan(&known), `
+        SELECT EXISTS (SELECT 1 FROM reservations WHERE order_id = ?)
+            OR EXISTS (SELECT 1 FROM order_stock WHERE order_id = ?)
+            OR EXISTS (SELECT 1 FROM order_rejections WHERE order_id = ?)`,
+		orderID, orderID, orderID)
+	if err != nil {
+		return err
+	}
+	if !known {
+		return fmt.Errorf("%w: order %d", ErrOrderNotReserved, orderID)
+	}
+	return nil
+}
+
+// ReleaseExpired снимает до limit удержаний, у которых истёк TTL, и возвращает их.
+// Реплики могут работать параллельно: захваченные другими строки пропускаются (SKIP LOCKED).
+func (r *InventoryRepository) ReleaseExpired(ctx context.Context, limit int) ([]model.Reservation, error) {
+	var expired []model.Reservation
+	err := r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		_, err := tx.QueryContext(ctx, &expired, `
+            SELECT * FROM reservations
+            WHERE status = ? AND expires_at <= now()
+            ORDER BY expires_at
+            LIMIT ?
+            FOR UPDATE SKIP LOCKED`,
+			model.ReservationHeld, limit)
+		if err != nil || len(expired) == 0 {
+			return err
+		}
+
+		lines := make([]model.StockLine, 0, len(expired))
+		for _, res := range expired {
+			if _, err := tx.ExecContext(ctx, `
+                UPDATE reservations SET status = ?, updated_at = now()
+                WHERE order_id = ? AND warehouse_code = ? AND product_id = ?`,
+				model.ReservationExpired, res.OrderID, res.WarehouseCode, res.ProductID); err != nil {
+				return err
+			}
+			lines = append(lines, model.StockLine{WarehouseCode: res.WarehouseCode, ProductID: res.ProductID, Quantity: res.Quantity})
+		}
+		if err := unreserve(ctx, tx, lines); err != nil {
+			return err
+		}
+
+		for _, res := range expired {
+			if err := recordMovement(ctx, tx, model.StockMovement{
+				WarehouseCode: res.WarehouseCode, ProductID: res.ProductID, Type: model.MovementExpire,
+				ReservedDelta: -res.Quantity, OrderID: res.OrderID,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return expired, nil
+}
+
+// OrderReservations возвращает удержания заказа в любом статусе.
+func (r *InventoryRepository) OrderReservations(ctx context.Context, orderID int64) ([]model.Reservation, error) {
+	var reservations []model.Reservation
+	err := r.db.ModelContext(ctx, &reservations).
+		Where("order_id = ?", orderID).
+		Order("warehouse_code", "product_id").
+		Select()
+	return reservations, err
+}
+
+// unreserve уменьшает reserved на снятые удержания.
+func unreserve(ctx context.Context, tx *pg.Tx, lines []model.StockLine) error {
+	for _, line := range mergeLines(lines) {
+		if _, err := tx.ExecContext(ctx, `
+            UPDATE stock
+            SET reserved = reserved - ?, version = version + 1, updated_at = now()
+            WHERE warehouse_code = ? AND product_id = ?`,
+			line.Quantity, line.WarehouseCode, line.ProductID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeLines складывает позиции одного товара на одном складе и сортирует их
+// по (склад, товар) — в порядке блокировки строк stock.
+func mergeLines(lines []model.StockLine) []model.StockLine {
+	type key struct {
+		warehouse string
+		product   int64
+	}
+	merged := make(map[key]int, len(lines))
+	for _, line := range lines {
+		merged[key{line.WarehouseCode, line.ProductID}] += line.Quantity
+	}
+	result := make([]model.StockLine, 0, len(merged))
+	for k, quantity := range merged {
+		result = append(result, model.StockLine{WarehouseCode: k.warehouse, ProductID: k.product, Quantity: quantity})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].WarehouseCode != result[j].WarehouseCode {
+			return result[i].WarehouseCode < result[j].WarehouseCode
+		}
+		return result[i].ProductID < result[j].ProductID
+	})
+	return result
+}
@@ -0,0 +1,127 @@
+// internal/repository/warehouse.go
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"inventory-service/internal/model"
+
+	"github.com/go-pg/pg/v10"
+)
+
+func (r *InventoryRepository) ListWarehouses(ctx context.Context) ([]model.Warehouse, error) {
+	var warehouses []model.Warehouse
+	err := r.db.ModelContext(ctx, &warehouses).Order("priority", "code").Select()
+	return warehouses, err
+}
+
+func (r *InventoryRepository) GetWarehouse(ctx context.Context, code string) (*model.Warehouse, error) {
+	w := new(model.Warehouse)
+	err := r.db.ModelContext(ctx, w).Where("code = ?", code).Select()
+	if err == pg.ErrNoRows {
+		return nil, fmt.Errorf("warehouse %s: %w", code, ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (r *InventoryRepository) CreateWarehouse(ctx context.Context, w *model.Warehouse) error {
+	_, err := r.db.QueryOneContext(ctx, w, `
+        INSERT INTO warehouses (code, name, region, priority, active)
+        VALUES (?, ?, ?, ?, ?)
+        ON CONFLICT (code) DO NOTHING
+        RETURNING *`,
+		w.Code, w.Name, w.Region, w.Priority, w.Active)
+	if err == pg.ErrNoRows {
+		return fmt.Errorf("warehouse %s: %w", w.Code, ErrAlreadyExists)
+	}
+	return err
+}
+
+// UpdateWarehouse меняет всё, кроме кода.
+func (r *InventoryRepository) UpdateWarehouse(ctx context.Context, w *model.Warehouse) error {
+	_, err := r.db.QueryOneContext(ctx, w, `
+        UPDATE warehouses
+        SET name = ?, region = ?, priority = ?, active = ?, updated_at = now()
+        WHERE code = ?
+        RETURNING *`,
+		w.Name, w.Region, w.Priority, w.Active, w.Code)
+	if err == pg.ErrNoRows {
+		return fmt.Errorf("warehouse %s: %w", w.Code, ErrNotFound)
+	}
+	return err
+}
+
+// TransferStock перемещает свободный (не удержанный) товар между складами и пишет
+// перемещение в stock_transfers. Возвращает остатки обоих складов после перемещения.
+func (r *InventoryRepository) TransferStock(ctx context.Context, t *model.StockTransfer) (from, to *model.Stock, err error) {
+	from, to = new(model.Stock), new(model.Stock)
+	err = r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		// Строки блокируются в порядке кода склада, как при удержании заказов
+		first, second := t.FromWarehouse, t.ToWarehouse
+		if second < first {
+			first, second = second, first
+		}
+		for _, code := range []string{first, second} {
+			if _, err := tx.ExecContext(ctx, `
+                SELECT 1 FROM stock WHERE warehouse_code = ? AND product_id = ? FOR UPDATE`,
+				code, t.ProductID); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.QueryOneContext(ctx, from, `
+            UPDATE stock
+            SET quantity = quantity - ?, version = version + 1, updated_at = now()
+            WHERE warehouse_code = ? AND product_id = ? AND quantity - reserved >= ?
+            RETURNING *`,
+			t.Quantity, t.FromWarehouse, t.ProductID, t.Quantity)
+		if err == pg.ErrNoRows {
+			return r.missingOr(ctx, tx, t.FromWarehouse, t.ProductID,
+				fmt.Errorf("%w in warehouse %s for product %d", ErrInsufficientStock, t.FromWarehouse, t.ProductID))
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.QueryOneContext(ctx, to, `
+            INSERT INTO stock (warehouse_code, product_id, quantity)
+            VALUES (?, ?, ?)
+            ON CONFLICT (warehouse_code, product_id) DO UPDATE
+            SET quantity = stock.quantity + EXCLUDED.quantity,
+                version = stock.version + 1,
+                updated_at = now()
+            RETURNING *`,
+			t.ToWarehouse, t.ProductID, t.Quantity); err != nil {
+			return err
+		}
+
+		_, err = tx.QueryOneContext(ctx, pg.Scan(&t.ID, &t.CreatedAt), `
+            INSERT INTO stock_transfers (product_id, from_warehouse, to_warehouse, quantity, reason, note, user_id)
+            VALUES (?, ?, ?, ?, ?, ?, ?)
+            RETURNING id, created_at`,
+			t.ProductID, t.FromWarehouse, t.ToWarehouse, t.Quantity, t.Reason, t.Note, t.UserID)
+		if err != nil {
+			return err
+		}
+
+		ref := reference("transfer", t.ID)
+		if err := recordMovement(ctx, tx, model.StockMovement{
+			WarehouseCode: t.FromWarehouse, ProductID: t.ProductID, Type: model.MovementTransferOut,
+			QuantityDelta: -t.Quantity, Reference: ref, UserID: t.UserID,
+		}); err != nil {
+			return err
+		}
+		return recordMovement(ctx, tx, model.StockMovement{
+			WarehouseCode: t.ToWarehouse, ProductID: t.ProductID, Type: model.MovementTransferIn,
+			QuantityDelta: t.Quantity, Reference: ref, UserID: t.UserID,
+		})
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
@@ -0,0 +1,77 @@
+// internal/service/catalog.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+)
+
+var ErrInvalidProduct = errors.New("invalid product")
+
+var (
+	skuRe      = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+	currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// CatalogService — каталог товаров: поиск и карточки для всех, правка — админы.
+// order-service сверяет с ним товары и цены заказа.
+type CatalogService struct {
+	repo *repository.InventoryRepository
+}
+
+func NewCatalogService(repo *repository.InventoryRepository) *CatalogService {
+	return &CatalogService{repo: repo}
+}
+
+func (s *CatalogService) Search(ctx context.Context, filter model.ProductFilter) ([]model.Product, error) {
+	return s.repo.SearchProducts(ctx, filter)
+}
+
+func (s *CatalogService) Get(ctx context.Context, id int64) (*model.Product, error) {
+	return s.repo.GetProduct(ctx, id)
+}
+
+func (s *CatalogService) GetMany(ctx context.Context, ids []int64) ([]model.Product, error) {
+	return s.repo.GetProducts(ctx, ids)
+}
+
+func (s *CatalogService) Create(ctx context.Context, p *model.Product) error {
+	if p.ID < 0 {
+		return fmt.Errorf("%w: id must be positive", ErrInvalidProduct)
+	}
+	if err := validateProduct(p); err != nil {
+		return err
+	}
+	return s.repo.CreateProduct(ctx, p)
+}
+
+func (s *CatalogService) Update(ctx context.Context, p *model.Product) error {
+	if err := validateProduct(p); err != nil {
+		return err
+	}
+	return s.repo.UpdateProduct(ctx, p)
+}
+
+func validateProduct(p *model.Product) error {
+	if !skuRe.MatchString(p.SKU) {
+		return fmt.Errorf("%w: sku must be 1-64 letters, digits, dots, dashes and underscores", ErrInvalidProduct)
+	}
+	if p.Name == "" || len(p.Name) > 255 {
+		return fmt.Errorf("%w: name is required and at most 255 bytes", ErrInvalidProduct)
+	}
+	if len(p.Description) > 10000 {
+		return fmt.Errorf("%w: description is longer than 10000 bytes", ErrInvalidProduct)
+	}
+	if p.Price < 0 {
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidProduct)
+	}
+	if !currencyRe.MatchString(p.Currency) {
+		return fmt.Errorf("%w: currency must be an ISO 4217 code, e.g. RUB", ErrInvalidProduct)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// internal/service/dead_letter.go
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// headerReplayOf — id записи dead_letters, из которой сообщение отправлено повторно.
+const headerReplayOf = "x-replay-of"
+
+// DeadLetterService — просмотр и повторная отправка сообщений из DLQ.
+type DeadLetterService struct {
+	repo   *repository.DeadLetterRepository
+	writer *kafka.Writer
+}
+
+func NewDeadLetterService(repo *repository.DeadLetterRepository, writer *kafka.Writer) *DeadLetterService {
+	return &DeadLetterService{repo: repo, writer: writer}
+}
+
+func (s *DeadLetterService) List(ctx context.Context, filter model.DeadLetterFilter) ([]model.DeadLetter, error) {
+	return s.repo.List(ctx, filter)
+}
+
+func (s *DeadLetterService) Get(ctx context.Context, id int64) (*model.DeadLetter, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Replay publishes the message to its original topic with the original key and
+// headers, so the consumer handles it from scratch. Events that did get applied
+// are skipped there as duplicates.
+func (s *DeadLetterService) Replay(ctx context.Context, id int64) (*model.DeadLetter, error) {
+	dl, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make([]kafka.Header, 0, len(dl.Headers)+1)
+	for _, h := range dl.Headers {
+		if h.Key != headerReplayOf {
+			headers = append(headers, kafka.Header{Key: h.Key, Value: []byte(h.Value)})
+		}
+	}
+	headers = append(headers, kafka.Header{Key: headerReplayOf, Value: []byte(strconv.FormatInt(dl.ID, 10))})
+
+	msg := kafka.Message{Topic: dl.Topic, Key: dl.Key, Value: dl.Value, Headers: headers}
+	if err := s.writer.WriteMessages(ctx, msg); err != nil {
+		return nil, err
+	}
+	if err := s.repo.MarkReplayed(ctx, dl); err != nil {
+		return nil, err
+	}
+	return dl, nil
+}
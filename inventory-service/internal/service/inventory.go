@@ -3,44 +3,170 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
+	"inventory-service/internal/allocation"
+	"inventory-service/internal/model"
 	"inventory-service/internal/repository"
+
+	"contracts/events"
+	ordersv1 "contracts/gen/orders/v1"
 )
 
-type OrderEvent struct {
-	OrderID   int64 `json:"order_id"`
-	UserID    int64 `json:"user_id"`
-	ProductID int64 `json:"product_id"`
-	Quantity  int   `json:"quantity"`
-}
+// ErrDuplicateEvent — сообщение уже обработано; повтор пропущен, остатки не изменились.
+var ErrDuplicateEvent = repository.ErrDuplicateEvent
+
+// ErrInvalidEvent — событие не обработать никогда (например, заказ без товаров); повторять бессмысленно.
+var ErrInvalidEvent = errors.New("invalid event")
 
 type InventoryService struct {
-	repo *repository.InventoryRepository
+	repo             *repository.InventoryRepository
+	reservationTTL   time.Duration
+	allocation       allocation.Strategy
+	defaultWarehouse string
 }
 
-func NewInventoryService(repo *repository.InventoryRepository) *InventoryService {
-	return &InventoryService{repo: repo}
+// NewInventoryService: товар нового заказа удерживается на reservationTTL —
+// он должен быть не меньше ORDER_PENDING_TTL order-service. Склады для заказа
+// выбирает strategy; возврат без известного склада отгрузки едет на defaultWarehouse.
+func NewInventoryService(repo *repository.InventoryRepository, reservationTTL time.Duration, strategy allocation.Strategy, defaultWarehouse string) *InventoryService {
+	return &InventoryService{repo: repo, reservationTTL: reservationTTL, allocation: strategy, defaultWarehouse: defaultWarehouse}
 }
 
-func (s *InventoryService) HandleOrderEvent(ctx context.Context, msg []byte) error {
-	var event OrderEvent
-	if err := json.Unmarshal(msg, &event); err != nil {
+func (s *InventoryService) HandleOrderEvent(ctx context.Context, event *ordersv1.OrderCreated) error {
+	if len(event.GetItems()) == 0 {
+		return fmt.Errorf("%w: order %d has no items", ErrInvalidEvent, event.GetOrderId())
+	}
+
+	log.Printf("Processing order %d: %d line(s)", event.GetOrderId(), len(event.GetItems()))
+
+	lines := make([]model.StockLine, 0, len(event.GetItems()))
+	for _, item := range event.GetItems() {
+		lines = append(lines, model.StockLine{ProductID: item.GetProductId(), Quantity: int(item.GetQuantity())})
+	}
+
+	ref := orderEvent(events.TopicOrderCreated, event.GetEventId(), event.GetOrderId())
+	req := allocation.Request{OrderID: event.GetOrderId(), Region: event.GetShippingRegion(), Lines: lines}
+	reserved, err := s.repo.ReserveOrder(ctx, ref, req, s.reservationTTL, s.allocation)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		// Нехватка товара — исход заказа, а не сбой: повтор не поможет. Заказ
+		// отклоняется, order-service отменит его по inventory.reservation_failed
+		log.Printf("Rejecting order %d: %v", event.GetOrderId(), err)
+		return s.repo.RejectOrder(ctx, ref, event.GetOrderId(), err.Error())
+	}
+	if err != nil {
+		if !errors.Is(err, ErrDuplicateEvent) {
+			log.Printf("Failed to reserve stock for order %d: %v", event.GetOrderId(), err)
+		}
 		return err
 	}
 
-	log.Printf("Processing order %d: product=%d, qty=%d", event.OrderID, event.ProductID, event.Quantity)
+	if len(reserved) > 0 {
+		log.Printf("Stock reserved for order %d for %s: %s", event.GetOrderId(), s.reservationTTL, describeLines(reserved))
+	}
+	return nil
+}
+
+// HandleOrderPaid списывает товар, удержанный под оплаченный заказ.
+func (s *InventoryService) HandleOrderPaid(ctx context.Context, event *ordersv1.OrderPaid) error {
+	ref := orderEvent(events.TopicOrderPaid, event.GetEventId(), event.GetOrderId())
+	committed, err := s.repo.CommitOrder(ctx, ref, event.GetOrderId())
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		// Удержание истекло, и товар ушёл другим заказам: повтор не поможет.
+		// Заказ отклоняется так же, как при нехватке на order.created, —
+		// order-service отменит его и вернёт деньги
+		log.Printf("Rejecting paid order %d: %v", event.GetOrderId(), err)
+		return s.repo.RejectOrder(ctx, ref, event.GetOrderId(), err.Error())
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotReserved) {
+			log.Printf("Order %d is not reserved yet, event will be retried", event.GetOrderId())
+		} else if !errors.Is(err, ErrDuplicateEvent) {
+			log.Printf("Failed to commit reservation of order %d: %v", event.GetOrderId(), err)
+		}
+		return err
+	}
 
-	// Убедимся, что товар существует (для демо можно предварительно заполнить)
-	// В реальной системе — проверка наличия в каталоге
+	if committed {
+		log.Printf("Stock deducted for paid order %d", event.GetOrderId())
+	}
+	return nil
+}
 
-	if err := s.repo.DeductStock(ctx, event.ProductID, event.Quantity); err != nil {
-		log.Printf("Failed to deduct stock: %v", err)
-		// В продакшене: отправить в DLQ или повторить
+// ConfirmOrder списывает удержанный товар без оплаты онлайн (например, заказ
+// с оплатой при получении подтвердил оператор). Повтор — no-op (false), заказ
+// без удержаний — ErrOrderNotReserved.
+func (s *InventoryService) ConfirmOrder(ctx context.Context, orderID int64) (bool, error) {
+	return s.repo.CommitOrder(ctx, model.EventRef{}, orderID)
+}
+
+func (s *InventoryService) OrderReservations(ctx context.Context, orderID int64) ([]model.Reservation, error) {
+	return s.repo.OrderReservations(ctx, orderID)
+}
+
+// HandleOrderCancelled — компенсация: снимает удержания неоплаченного заказа
+// и возвращает на склад товар, списанный под оплаченный.
+func (s *InventoryService) HandleOrderCancelled(ctx context.Context, event *ordersv1.OrderCancelled) error {
+	ref := orderEvent(events.TopicOrderCancelled, event.GetEventId(), event.GetOrderId())
+	returned, err := s.repo.CancelOrder(ctx, ref, event.GetOrderId())
+	if err != nil {
+		if errors.Is(err, repository.ErrOrderNotReserved) {
+			log.Printf("Order %d is not reserved yet, event will be retried", event.GetOrderId())
+		} else if !errors.Is(err, ErrDuplicateEvent) {
+			log.Printf("Failed to restock order %d: %v", event.GetOrderId(), err)
+		}
 		return err
 	}
 
-	log.Printf("Stock deducted for product %d", event.ProductID)
+	if returned {
+		log.Printf("Stock returned for cancelled order %d (reason: %s)", event.GetOrderId(), event.GetReason())
+	} else {
+		log.Printf("Nothing to return for cancelled order %d", event.GetOrderId())
+	}
 	return nil
 }
+
+// HandleReturnReceived возвращает на склад товар из принятого возврата.
+func (s *InventoryService) HandleReturnReceived(ctx context.Context, event *ordersv1.OrderReturnReceived) error {
+	lines := make([]model.StockLine, 0, len(event.GetItems()))
+	for _, item := range event.GetItems() {
+		lines = append(lines, model.StockLine{WarehouseCode: s.defaultWarehouse, ProductID: item.GetProductId(), Quantity: int(item.GetQuantity())})
+	}
+
+	ref := model.EventRef{
+		Key:   fmt.Sprintf("%s:%d", events.TopicOrderReturnReceived, event.GetReturnId()),
+		ID:    event.GetEventId(),
+		Topic: events.TopicOrderReturnReceived,
+	}
+	restocked, err := s.repo.RestockReturn(ctx, ref, event.GetOrderId(), event.GetReturnId(), lines, event.GetQuarantine())
+	if err != nil {
+		if !errors.Is(err, ErrDuplicateEvent) {
+			log.Printf("Failed to restock return %d of order %d: %v", event.GetReturnId(), event.GetOrderId(), err)
+		}
+		return err
+	}
+
+	if restocked {
+		log.Printf("Return %d of order %d restocked (quarantine: %t)", event.GetReturnId(), event.GetOrderId(), event.GetQuarantine())
+	}
+	return nil
+}
+
+// orderEvent — ключ дедупликации события заказа: каждое из них бывает у заказа
+// один раз, даже если order-service переопубликует его с новым event_id.
+func orderEvent(topic, eventID string, orderID int64) model.EventRef {
+	return model.EventRef{Key: fmt.Sprintf("%s:%d", topic, orderID), ID: eventID, Topic: topic}
+}
+
+// describeLines — "main: 123×2, spb-1: 456×1" для логов.
+func describeLines(lines []model.StockLine) string {
+	parts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		parts = append(parts, fmt.Sprintf("%s: %d×%d", line.WarehouseCode, line.ProductID, line.Quantity))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+// internal/service/notifier.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"inventory-service/internal/model"
+)
+
+// AlertNotifier — хук для алертов об остатках (мессенджер, почта закупщикам и т.п.),
+// вызывается после публикации алерта в Kafka.
+type AlertNotifier interface {
+	Notify(ctx context.Context, alert model.StockAlert) error
+}
+
+// WebhookNotifier отправляет алерт POST-запросом с JSON-телом на заданный URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// webhookAlert — тело запроса: event — low_stock или out_of_stock.
+type webhookAlert struct {
+	Event string `json:"event"`
+	model.StockAlert
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert model.StockAlert) error {
+	event := model.EventLowStock
+	if alert.Level == model.StockLevelOut {
+		event = model.EventOutOfStock
+	}
+	body, err := json.Marshal(webhookAlert{Event: event, StockAlert: alert})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// internal/service/outbox.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+
+	"contracts/events"
+	inventoryv1 "contracts/gen/inventory/v1"
+
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// OutboxPublisher публикует события из outbox_events в топики inventory.* (at-least-once:
+// после сбоя событие уйдёт ещё раз с тем же event_id). Алерты low_stock и out_of_stock
+// после публикации передаются notifier, если он задан. Работает на каждой реплике:
+// строки захватываются с SKIP LOCKED, поэтому порядок событий одного товара между
+// репликами не гарантирован — consumer сравнивает version строки stock.
+type OutboxPublisher struct {
+	repo     *repository.InventoryRepository
+	writer   *kafka.Writer
+	notifier AlertNotifier
+	interval time.Duration
+	batch    int
+}
+
+func NewOutboxPublisher(repo *repository.InventoryRepository, writer *kafka.Writer, notifier AlertNotifier, interval time.Duration, batch int) *OutboxPublisher {
+	return &OutboxPublisher{repo: repo, writer: writer, notifier: notifier, interval: interval, batch: batch}
+}
+
+// Run publishes pending events every interval until ctx is cancelled.
+func (p *OutboxPublisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Разбираем очередь пачками, пока она не опустеет
+		for {
+			n, err := p.publishBatch(ctx)
+			if err != nil {
+				log.Printf("Failed to publish outbox events: %v", err)
+				break
+			}
+			if n < p.batch {
+				break
+			}
+		}
+	}
+}
+
+func (p *OutboxPublisher) publishBatch(ctx context.Context) (int, error) {
+	var alerts []model.StockAlert
+	n, err := p.repo.PublishOutbox(ctx, p.batch, func(pending []model.OutboxEvent) error {
+		alerts = alerts[:0]
+		msgs := make([]kafka.Message, 0, len(pending))
+		for _, e := range pending {
+			msg, alert, err := outboxMessage(e)
+			if err != nil {
+				// Событие не прочитать и при следующей попытке — пропускаем, чтобы не встала очередь
+				log.Printf("Dropping outbox event %d (%s): %v", e.ID, e.Type, err)
+				continue
+			}
+			msgs = append(msgs, msg)
+			if alert != nil {
+				alerts = append(alerts, *alert)
+			}
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		return p.writer.WriteMessages(ctx, msgs...)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Уведомления — best effort: событие уже в Kafka, повторно его не отправить
+	if p.notifier != nil {
+		for _, alert := range alerts {
+			if err := p.notifier.Notify(ctx, alert); err != nil {
+				log.Printf("Failed to notify about %s stock of product %d: %v", alert.Level, alert.ProductID, err)
+			}
+		}
+	}
+	return n, nil
+}
+
+// outboxMessage encodes an outbox event with the contracts codec; alerts are returned
+// as well, for the notifier.
+func outboxMessage(e model.OutboxEvent) (kafka.Message, *model.StockAlert, error) {
+	switch e.Type {
+	case model.EventStockChanged:
+		var change model.StockChange
+		if err := json.Unmarshal(e.Payload, &change); err != nil {
+			return kafka.Message{}, nil, err
+		}
+		m, s := change.Movement, change.Stock
+		msg, err := events.NewStockChangedMessage(&inventoryv1.StockChanged{
+			EventId:         e.EventID,
+			WarehouseCode:   m.WarehouseCode,
+			ProductId:       m.ProductID,
+			MovementType:    m.Type,
+			QuantityDelta:   int32(m.QuantityDelta),
+			ReservedDelta:   int32(m.ReservedDelta),
+			QuarantineDelta: int32(m.QuarantineDelta),
+			Quantity:        int32(s.Quantity),
+			Reserved:        int32(s.Reserved),
+			Available:       int32(s.Available()),
+			Quarantine:      int32(s.Quarantine),
+			Version:         s.Version,
+			OrderId:         m.OrderID,
+			OccurredAt:      timestamppb.New(m.CreatedAt),
+		})
+		return msg, nil, err
+
+	case model.EventLowStock, model.EventOutOfStock:
+		var alert model.StockAlert
+		if err := json.Unmarshal(e.Payload, &alert); err != nil {
+			return kafka.Message{}, nil, err
+		}
+		var msg kafka.Message
+		var err error
+		if e.Type == model.EventLowStock {
+			msg, err = events.NewLowStockMessage(&inventoryv1.LowStock{
+				EventId:    e.EventID,
+				ProductId:  alert.ProductID,
+				Available:  int32(alert.Available),
+				Threshold:  int32(alert.Threshold),
+				OccurredAt: timestamppb.New(alert.ChangedAt),
+			})
+		} else {
+			msg, err = events.NewOutOfStockMessage(&inventoryv1.OutOfStock{
+				EventId:    e.EventID,
+				ProductId:  alert.ProductID,
+				Available:  int32(alert.Available),
+				Threshold:  int32(alert.Threshold),
+				OccurredAt: timestamppb.New(alert.ChangedAt),
+			})
+		}
+		return msg, &alert, err
+
+	case model.EventReservationFailed:
+		var rejection model.OrderRejection
+		if err := json.Unmarshal(e.Payload, &rejection); err != nil {
+			return kafka.Message{}, nil, err
+		}
+		msg, err := events.NewReservationFailedMessage(&inventoryv1.ReservationFailed{
+			EventId:    e.EventID,
+			OrderId:    rejection.OrderID,
+			Reason:     rejection.Reason,
+			OccurredAt: timestamppb.New(rejection.CreatedAt),
+		})
+		return msg, nil, err
+
+	default:
+		return kafka.Message{}, nil, fmt.Errorf("unknown event type %q", e.Type)
+	}
+}
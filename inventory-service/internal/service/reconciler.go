@@ -0,0 +1,68 @@
+// internal/service/reconciler.go
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// StockReconciler периодически сверяет строки stock с суммой журнала stock_movements.
+// Расхождение значит, что остаток менялся мимо журнала: каждое пишется в лог,
+// их число — в метрику inventory.stock.discrepancies.
+type StockReconciler struct {
+	repo     *repository.InventoryRepository
+	interval time.Duration
+
+	discrepancies metric.Int64Gauge
+}
+
+func NewStockReconciler(repo *repository.InventoryRepository, interval time.Duration) *StockReconciler {
+	gauge, err := otel.Meter("inventory-service.reconciler").Int64Gauge("inventory.stock.discrepancies",
+		metric.WithDescription("Stock rows that do not match the sum of the stock movement ledger"),
+		metric.WithUnit("{row}"))
+	if err != nil {
+		log.Printf("Failed to create stock discrepancies gauge: %v", err)
+	}
+	return &StockReconciler{repo: repo, interval: interval, discrepancies: gauge}
+}
+
+// Run reconciles every interval until ctx is cancelled.
+func (r *StockReconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := r.Reconcile(ctx); err != nil {
+			log.Printf("Failed to reconcile stock: %v", err)
+		}
+	}
+}
+
+// Reconcile runs one check and returns the discrepancies found.
+func (r *StockReconciler) Reconcile(ctx context.Context) ([]model.StockDiscrepancy, error) {
+	found, err := r.repo.Reconcile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range found {
+		log.Printf("Stock does not match ledger: warehouse %s, product %d: quantity %d/%d, reserved %d/%d, quarantine %d/%d",
+			d.WarehouseCode, d.ProductID, d.Quantity, d.LedgerQuantity, d.Reserved, d.LedgerReserved, d.Quarantine, d.LedgerQuarantine)
+	}
+	if r.discrepancies != nil {
+		r.discrepancies.Record(ctx, int64(len(found)))
+	}
+	return found, nil
+}
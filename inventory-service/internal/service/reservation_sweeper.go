@@ -0,0 +1,58 @@
+// internal/service/reservation_sweeper.go
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"inventory-service/internal/repository"
+)
+
+// ReservationSweeper снимает удержания, у которых истёк TTL, и возвращает товар
+// в свободный остаток. Работает на каждой реплике: строки захватываются
+// с SKIP LOCKED, так что одно удержание снимается один раз.
+// Заодно чистит processed_events старше eventRetention.
+type ReservationSweeper struct {
+	repo           *repository.InventoryRepository
+	interval       time.Duration
+	batchSize      int
+	eventRetention time.Duration
+}
+
+func NewReservationSweeper(repo *repository.InventoryRepository, interval time.Duration, batchSize int, eventRetention time.Duration) *ReservationSweeper {
+	return &ReservationSweeper{repo: repo, interval: interval, batchSize: batchSize, eventRetention: eventRetention}
+}
+
+// Run releases expired reservations every interval until ctx is cancelled.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Разбираем очередь пачками, пока она не опустеет
+		for {
+			expired, err := s.repo.ReleaseExpired(ctx, s.batchSize)
+			if err != nil {
+				log.Printf("Failed to release expired reservations: %v", err)
+				break
+			}
+			for _, res := range expired {
+				log.Printf("Reservation expired: order %d, product %d, quantity %d", res.OrderID, res.ProductID, res.Quantity)
+			}
+			if len(expired) < s.batchSize {
+				break
+			}
+		}
+
+		if _, err := s.repo.PurgeProcessedEvents(ctx, s.eventRetention); err != nil {
+			log.Printf("Failed to purge processed events: %v", err)
+		}
+	}
+}
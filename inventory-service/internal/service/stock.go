@@ -0,0 +1,175 @@
+// internal/service/stock.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+)
+
+var (
+	ErrInvalidAdjustment = errors.New("invalid stock adjustment")
+	ErrInvalidThreshold  = errors.New("invalid reorder threshold")
+)
+
+// reasonRe — код причины корректировки: supplier_delivery, broken_in_transit, cycle_count...
+var reasonRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+const maxNoteLen = 255
+
+// StockService — остатки для REST API: чтение, ручные корректировки и перемещения
+// между складами. Запрос без склада относится к defaultWarehouse.
+type StockService struct {
+	repo             *repository.InventoryRepository
+	defaultWarehouse string
+}
+
+func NewStockService(repo *repository.InventoryRepository, defaultWarehouse string) *StockService {
+	return &StockService{repo: repo, defaultWarehouse: defaultWarehouse}
+}
+
+func (s *StockService) Get(ctx context.Context, productID int64) (*model.ProductStock, error) {
+	rows, err := s.repo.GetStock(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return &model.GroupStock(rows)[0], nil
+}
+
+// GetMany returns stock for the products that exist, in product_id order.
+func (s *StockService) GetMany(ctx context.Context, productIDs []int64) ([]model.ProductStock, error) {
+	rows, err := s.repo.GetStocks(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	return model.GroupStock(rows), nil
+}
+
+// AsOf reconstructs the stock of a product at a past moment from the movement ledger.
+func (s *StockService) AsOf(ctx context.Context, productID int64, at time.Time) (*model.ProductStock, error) {
+	rows, err := s.repo.StockAsOf(ctx, productID, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return &model.GroupStock(rows)[0], nil
+}
+
+// History returns stock movements of a product, newest first.
+func (s *StockService) History(ctx context.Context, filter model.StockHistoryFilter) ([]model.StockHistoryEntry, error) {
+	return s.repo.StockHistory(ctx, filter)
+}
+
+// Adjust applies a receive, damage or correction. quantity is the number of
+// units received or damaged; for a correction it is the signed delta.
+func (s *StockService) Adjust(ctx context.Context, adj *model.StockAdjustment, quantity int) (*model.Stock, error) {
+	switch adj.Type {
+	case model.AdjustmentReceive:
+		adj.Delta = quantity
+	case model.AdjustmentDamage:
+		adj.Delta = -quantity
+	case model.AdjustmentCorrection:
+		adj.Delta = quantity
+	default:
+		return nil, fmt.Errorf("%w: type must be receive, damage or correction", ErrInvalidAdjustment)
+	}
+	if adj.Type != model.AdjustmentCorrection && quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidAdjustment)
+	}
+	if quantity == 0 {
+		return nil, fmt.Errorf("%w: quantity must not be zero", ErrInvalidAdjustment)
+	}
+	if err := s.validate(ctx, adj); err != nil {
+		return nil, err
+	}
+	return s.repo.AdjustStock(ctx, adj)
+}
+
+// Set overwrites the on-hand quantity if the stock row is still at version.
+func (s *StockService) Set(ctx context.Context, adj *model.StockAdjustment, quantity int, version int64) (*model.Stock, error) {
+	if quantity < 0 {
+		return nil, fmt.Errorf("%w: quantity must not be negative", ErrInvalidAdjustment)
+	}
+	if version < 0 {
+		return nil, fmt.Errorf("%w: invalid version", ErrInvalidAdjustment)
+	}
+	adj.Type = model.AdjustmentSet
+	if err := s.validate(ctx, adj); err != nil {
+		return nil, err
+	}
+	return s.repo.SetStock(ctx, quantity, version, adj)
+}
+
+// Transfer moves free stock of t.ProductID from one warehouse to another and
+// returns the stock of both warehouses afterwards.
+func (s *StockService) Transfer(ctx context.Context, t *model.StockTransfer) (from, to *model.Stock, err error) {
+	if t.Quantity <= 0 {
+		return nil, nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidAdjustment)
+	}
+	if t.FromWarehouse == "" || t.ToWarehouse == "" || t.FromWarehouse == t.ToWarehouse {
+		return nil, nil, fmt.Errorf("%w: from and to must be two different warehouses", ErrInvalidAdjustment)
+	}
+	if err := validateReason(t.Reason, t.Note); err != nil {
+		return nil, nil, err
+	}
+	for _, code := range []string{t.FromWarehouse, t.ToWarehouse} {
+		if _, err := s.repo.GetWarehouse(ctx, code); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s.repo.TransferStock(ctx, t)
+}
+
+func (s *StockService) GetThreshold(ctx context.Context, productID int64) (*model.StockThreshold, error) {
+	return s.repo.GetThreshold(ctx, productID)
+}
+
+// SetThreshold sets the reorder threshold of a product; low_stock is raised when
+// the available quantity across all warehouses drops to it.
+func (s *StockService) SetThreshold(ctx context.Context, t *model.StockThreshold) error {
+	if t.LowStock < 0 {
+		return fmt.Errorf("%w: low_stock must not be negative", ErrInvalidThreshold)
+	}
+	return s.repo.SetThreshold(ctx, t)
+}
+
+func (s *StockService) DeleteThreshold(ctx context.Context, productID int64) error {
+	return s.repo.DeleteThreshold(ctx, productID)
+}
+
+// Alerts lists products that are currently low or out of stock.
+func (s *StockService) Alerts(ctx context.Context) ([]model.StockAlert, error) {
+	return s.repo.ListStockAlerts(ctx)
+}
+
+// validate fills in the default warehouse and checks that it exists.
+func (s *StockService) validate(ctx context.Context, adj *model.StockAdjustment) error {
+	if err := validateReason(adj.Reason, adj.Note); err != nil {
+		return err
+	}
+	if adj.WarehouseCode == "" {
+		adj.WarehouseCode = s.defaultWarehouse
+	}
+	_, err := s.repo.GetWarehouse(ctx, adj.WarehouseCode)
+	return err
+}
+
+func validateReason(reason, note string) error {
+	if !reasonRe.MatchString(reason) {
+		return fmt.Errorf("%w: reason must be a code like supplier_delivery", ErrInvalidAdjustment)
+	}
+	if len(note) > maxNoteLen {
+		return fmt.Errorf("%w: note is longer than %d bytes", ErrInvalidAdjustment, maxNoteLen)
+	}
+	return nil
+}
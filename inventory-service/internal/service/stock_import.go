@@ -0,0 +1,194 @@
+// internal/service/stock_import.go
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+)
+
+var ErrInvalidImport = errors.New("invalid stock import")
+
+// StockImportService — загрузка остатков из CSV (product_id, warehouse, quantity, mode)
+// и выгрузка в том же формате. Строки без склада относятся к defaultWarehouse,
+// без mode — set.
+type StockImportService struct {
+	repo             *repository.InventoryRepository
+	defaultWarehouse string
+	chunkSize        int
+	maxRows          int
+}
+
+func NewStockImportService(repo *repository.InventoryRepository, defaultWarehouse string, chunkSize, maxRows int) *StockImportService {
+	return &StockImportService{repo: repo, defaultWarehouse: defaultWarehouse, chunkSize: chunkSize, maxRows: maxRows}
+}
+
+// Import parses and validates the CSV and previews every row against current stock.
+// With dryRun nothing is applied and rows with errors are returned as they are;
+// otherwise any row error fails the whole import with ErrInvalidImport before
+// anything is written. A chunk that fails while applying leaves imp partial.
+func (s *StockImportService) Import(ctx context.Context, r io.Reader, imp *model.StockImport, dryRun bool) ([]model.StockImportRow, error) {
+	rows, err := s.parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.PreviewImport(ctx, rows, s.chunkSize); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return rows, nil
+	}
+	for _, row := range rows {
+		if row.Error != "" {
+			return rows, fmt.Errorf("%w: line %d: %s", ErrInvalidImport, row.Line, row.Error)
+		}
+	}
+
+	imp.Rows = len(rows)
+	if err := s.repo.ImportStock(ctx, imp, rows, s.chunkSize); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (s *StockImportService) Get(ctx context.Context, id int64) (*model.StockImport, error) {
+	return s.repo.GetImport(ctx, id)
+}
+
+func (s *StockImportService) Undo(ctx context.Context, id, userID int64) (*model.StockImport, error) {
+	return s.repo.UndoImport(ctx, id, userID)
+}
+
+// Export streams stock as CSV in the import format; warehouse "" — all warehouses.
+func (s *StockImportService) Export(ctx context.Context, w io.Writer, warehouse string) error {
+	if warehouse != "" {
+		if _, err := s.repo.GetWarehouse(ctx, warehouse); err != nil {
+			return err
+		}
+	}
+	return s.repo.ExportStock(ctx, w, warehouse)
+}
+
+// parse reads the CSV. The header names the columns in any order; product_id and
+// quantity are required, unknown columns (product name, comments) are ignored.
+// Values that cannot be parsed become row errors, malformed CSV fails the import.
+func (s *StockImportService) parse(r io.Reader) ([]model.StockImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: file is empty", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) // BOM из Excel
+		columns[name] = i
+	}
+	for _, name := range []string{"product_id", "quantity"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: header has no %s column (expected %s)", ErrInvalidImport, name, strings.Join(model.ImportCSVHeader, ","))
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []model.StockImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		if len(rows) == s.maxRows {
+			return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidImport, s.maxRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := model.StockImportRow{Line: line, WarehouseCode: field(record, "warehouse"), Mode: field(record, "mode")}
+		if row.ProductID, err = strconv.ParseInt(field(record, "product_id"), 10, 64); err != nil || row.ProductID <= 0 {
+			row.Error = "product_id must be a positive integer"
+		} else if row.Quantity, err = strconv.Atoi(field(record, "quantity")); err != nil {
+			row.Error = "quantity must be an integer"
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no rows after the header", ErrInvalidImport)
+	}
+	return rows, nil
+}
+
+// validate fills in defaults and marks rows with an unknown mode or warehouse,
+// a negative set and repeated (warehouse, product) pairs.
+func (s *StockImportService) validate(ctx context.Context, rows []model.StockImportRow) error {
+	warehouses, err := s.repo.ListWarehouses(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(warehouses))
+	for _, w := range warehouses {
+		known[w.Code] = true
+	}
+
+	type key struct {
+		warehouse string
+		product   int64
+	}
+	firstLine := make(map[key]int, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		if row.WarehouseCode == "" {
+			row.WarehouseCode = s.defaultWarehouse
+		}
+		row.Mode = strings.ToLower(row.Mode)
+		if row.Mode == "" {
+			row.Mode = model.ImportModeSet
+		}
+		if row.Error != "" {
+			continue
+		}
+
+		switch {
+		case row.Mode != model.ImportModeSet && row.Mode != model.ImportModeAdd:
+			row.Error = "mode must be set or add"
+		case row.Mode == model.ImportModeSet && row.Quantity < 0:
+			row.Error = "quantity must not be negative with mode set"
+		case !known[row.WarehouseCode]:
+			row.Error = fmt.Sprintf("unknown warehouse %q", row.WarehouseCode)
+		}
+		if row.Error != "" {
+			continue
+		}
+
+		k := key{row.WarehouseCode, row.ProductID}
+		if line, ok := firstLine[k]; ok {
+			row.Error = fmt.Sprintf("product %d in warehouse %s is already on line %d", row.ProductID, row.WarehouseCode, line)
+			continue
+		}
+		firstLine[k] = row.Line
+	}
+	return nil
+}
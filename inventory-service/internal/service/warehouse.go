@@ -0,0 +1,60 @@
+// internal/service/warehouse.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"inventory-service/internal/model"
+	"inventory-service/internal/repository"
+)
+
+var ErrInvalidWarehouse = errors.New("invalid warehouse")
+
+// warehouseCodeRe — код склада: main, msk-1, spb-north...
+var warehouseCodeRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// WarehouseService — справочник складов (админы).
+type WarehouseService struct {
+	repo *repository.InventoryRepository
+}
+
+func NewWarehouseService(repo *repository.InventoryRepository) *WarehouseService {
+	return &WarehouseService{repo: repo}
+}
+
+func (s *WarehouseService) List(ctx context.Context) ([]model.Warehouse, error) {
+	return s.repo.ListWarehouses(ctx)
+}
+
+func (s *WarehouseService) Create(ctx context.Context, w *model.Warehouse) error {
+	if !warehouseCodeRe.MatchString(w.Code) {
+		return fmt.Errorf("%w: code must be lowercase letters, digits and dashes, like msk-1", ErrInvalidWarehouse)
+	}
+	if err := validateWarehouse(w); err != nil {
+		return err
+	}
+	return s.repo.CreateWarehouse(ctx, w)
+}
+
+func (s *WarehouseService) Update(ctx context.Context, w *model.Warehouse) error {
+	if err := validateWarehouse(w); err != nil {
+		return err
+	}
+	return s.repo.UpdateWarehouse(ctx, w)
+}
+
+func validateWarehouse(w *model.Warehouse) error {
+	if w.Name == "" || len(w.Name) > 255 {
+		return fmt.Errorf("%w: name is required and at most 255 bytes", ErrInvalidWarehouse)
+	}
+	if len(w.Region) > 100 {
+		return fmt.Errorf("%w: region is longer than 100 bytes", ErrInvalidWarehouse)
+	}
+	if w.Priority < 0 {
+		return fmt.Errorf("%w: priority must not be negative", ErrInvalidWarehouse)
+	}
+	return nil
+}
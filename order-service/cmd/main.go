@@ -3,9 +3,7 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"errors"
-	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -13,18 +11,23 @@ import (
 	"strings"
 	"time"
 
+	"order-service/internal/catalog"
 	"order-service/internal/config"
+	"order-service/internal/export"
 	"order-service/internal/handler"
+	ordermw "order-service/internal/middleware"
+	"order-service/internal/payment"
 	"order-service/internal/repository"
 	"order-service/internal/service"
 	"order-service/internal/utils"
 
-	_ "github.com/go-sql-driver/mysql"
-
 	"github.com/labstack/echo/v4"
 	echomw "github.com/labstack/echo/v4/middleware"
+	"github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+
+	"contracts/events"
 )
 
 const serviceName = "order-service"
@@ -37,6 +40,18 @@ func main() {
 
 	cfg := config.Load()
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		db, err := repository.Open(repository.Dialect(cfg.DBDriver), cfg.DatabaseDSN())
+		if err != nil {
+			log.Fatal("Failed to connect to DB:", err)
+		}
+		defer db.Close()
+		if err := runMigrate(ctx, db, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	otelShutdown, err := utils.SetupOTelSDK(ctx, serviceName, serviceVersion, cfg.OtelExporterURL)
 	if err != nil {
 		log.Fatalf("Error setting up OpenTelemetry SDK: %v", err)
@@ -50,25 +65,31 @@ func main() {
 
 	utils.InitJWT(cfg.JWTSecret)
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		cfg.DBUser,
-		cfg.DBPassword,
-		cfg.DBHost,
-		cfg.DBPort,
-		cfg.DBName,
-	)
-
-	db, err := sql.Open("mysql", dsn)
+	db, err := repository.Open(repository.Dialect(cfg.DBDriver), cfg.DatabaseDSN())
 	if err != nil {
 		log.Fatal("Failed to connect to DB:", err)
 	}
 	defer db.Close()
 
-	// Kafka
+	if cfg.AutoMigrate {
+		if err := runMigrate(ctx, db, []string{"up"}); err != nil {
+			log.Fatal("Failed to migrate DB:", err)
+		}
+	}
+
+	// Redis (ключи идемпотентности)
+	redisClient := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr, Password: cfg.RedisPassword})
+	defer redisClient.Close()
+
+	if _, err := redisClient.Ping(ctx).Result(); err != nil {
+		logger := utils.NewHelperLogger("order-service.service.general")
+		logger.LogError(ctx, "Could not get into redis", err)
+	}
+
+	// Kafka (топик задаётся в каждом сообщении)
 	kafkaWriter := &kafka.Writer{
 		Addr:         kafka.TCP(cfg.KafkaBrokers...),
-		Topic:        "order.created",
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Hash{}, // события одного заказа (ключ order_id) — в одной партиции, по порядку
 		RequiredAcks: kafka.RequireAll,
 		Async:        false,
 	}
@@ -76,7 +97,77 @@ func main() {
 
 	// Order Service
 	orderRepo := repository.NewOrderRepository(db)
-	orderService := service.NewOrderService(orderRepo, kafkaWriter)
+	promotionRepo := repository.NewPromotionRepository(db)
+	promotionService := service.NewPromotionService(promotionRepo)
+	addressService := service.NewAddressService(repository.NewAddressRepository(db))
+	orderService := service.NewOrderService(orderRepo, promotionService, addressService, kafkaWriter)
+	idempotencyRepo := repository.NewIdempotencyRepository(redisClient)
+
+	// Каталог товаров (inventory-service): позиции и цены заказа сверяются синхронно
+	if cfg.CatalogURL != "" {
+		orderService.SetCatalog(catalog.NewClient(catalog.Config{
+			URL:             cfg.CatalogURL,
+			Timeout:         cfg.CatalogTimeout,
+			CacheTTL:        cfg.CatalogCacheTTL,
+			BreakerFailures: cfg.CatalogBreakerFailures,
+			BreakerCooldown: cfg.CatalogBreakerCooldown,
+		}))
+	} else {
+		logger := utils.NewHelperLogger("order-service.service.general")
+		logger.LogWarn(ctx, "CATALOG_URL is not set: order items and prices are not checked against the catalog")
+	}
+
+	// Webhooks
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(db), service.WebhookConfig{
+		MaxAttempts:       cfg.WebhookMaxAttempts,
+		BaseBackoff:       cfg.WebhookBaseBackoff,
+		MaxBackoff:        cfg.WebhookMaxBackoff,
+		PollInterval:      cfg.WebhookPollInterval,
+		Timeout:           cfg.WebhookTimeout,
+		AllowPrivateHosts: cfg.WebhookAllowPrivateHosts,
+	})
+	orderService.AddListener(webhookService)
+	go webhookService.Run(ctx)
+
+	// Платежи: заказ становится paid только после подтверждения списания
+	var paymentProvider payment.Provider
+	switch cfg.PaymentProvider {
+	case "fake":
+		paymentProvider = payment.NewFakeProvider(cfg.PaymentWebhookURL, cfg.PaymentWebhookSecret, cfg.FakePaymentDelay)
+	default:
+		log.Fatalf("Unknown payment provider %q", cfg.PaymentProvider)
+	}
+	paymentService := service.NewPaymentService(repository.NewPaymentRepository(db), orderService, paymentProvider)
+	orderService.SetRefunder(paymentService)
+
+	// Заказы, товар которых inventory-service не смог удержать, отменяются
+	rejectionReader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: cfg.KafkaBrokers,
+		GroupID: serviceName,
+		Topic:   events.TopicReservationFailed,
+	})
+	defer rejectionReader.Close()
+	go service.NewReservationFailedConsumer(rejectionReader, orderService).Run(ctx)
+
+	// Неоплаченные заказы отменяются по ORDER_PENDING_TTL
+	expiryService := service.NewOrderExpiryService(orderService, service.ExpiryConfig{
+		PendingTTL: cfg.OrderPendingTTL,
+		Interval:   cfg.OrderExpiryInterval,
+		BatchSize:  cfg.OrderExpiryBatch,
+	})
+	go expiryService.Run(ctx)
+
+	// Использования промокодов, чей заказ так и не создался, возвращаются по PROMO_ORPHAN_TTL
+	go service.NewPromotionSweeper(promotionRepo, service.PromotionSweepConfig{
+		OrphanTTL: cfg.PromoOrphanTTL,
+		Interval:  cfg.PromoSweepInterval,
+	}).Run(ctx)
+
+	// Live-статусы заказов (SSE) через Redis Streams — работают между репликами.
+	// Одна подписка Pub/Sub на под раздаёт события всем SSE-соединениям
+	statusStreamService := service.NewStatusStreamService(repository.NewStatusStreamRepository(redisClient))
+	orderService.AddListener(statusStreamService)
+	go statusStreamService.Run(ctx)
 
 	// Echo
 	e := echo.New()
@@ -116,7 +207,78 @@ func main() {
 
 	// Routes
 	orderHandler := handler.NewOrderHandler(orderService)
-	e.POST("/orders", orderHandler.CreateOrder, authMid)
+	e.POST("/orders", orderHandler.CreateOrder, authMid, ordermw.Idempotency(idempotencyRepo, cfg.IdempotencyTTL))
+	e.GET("/orders/:id", orderHandler.GetOrder, authMid)
+
+	// Пакетное создание заказов (B2B)
+	orderBatchHandler := handler.NewOrderBatchHandler(orderService, cfg.OrderBatchMax, cfg.OrderBatchMode)
+	e.POST("/orders/batch", orderBatchHandler.CreateOrders, authMid, ordermw.Idempotency(idempotencyRepo, cfg.IdempotencyTTL))
+	e.POST("/orders/:id/cancel", orderHandler.CancelOrder, authMid)
+	e.GET("/orders/:id/history", orderHandler.History, authMid)
+	e.GET("/orders/:id/shipment", orderHandler.Shipment, authMid)
+
+	addressHandler := handler.NewAddressHandler(addressService)
+	e.POST("/addresses", addressHandler.Create, authMid)
+	e.GET("/addresses", addressHandler.List, authMid)
+	e.GET("/addresses/:id", addressHandler.Get, authMid)
+	e.PUT("/addresses/:id", addressHandler.Update, authMid)
+	e.DELETE("/addresses/:id", addressHandler.Delete, authMid)
+
+	paymentHandler := handler.NewPaymentHandler(paymentService, cfg.PaymentWebhookSecret)
+	e.POST("/orders/:id/pay", paymentHandler.Pay, authMid, ordermw.Idempotency(idempotencyRepo, cfg.IdempotencyTTL))
+	e.POST("/payments/webhook", paymentHandler.Webhook) // подпись вместо JWT
+
+	orderEventsHandler := handler.NewOrderEventsHandler(orderService, statusStreamService, cfg.SSEHeartbeat)
+	e.GET("/orders/:id/events", orderEventsHandler.Stream, authMid)
+
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	e.POST("/webhooks", webhookHandler.Register, authMid)
+	e.GET("/webhooks", webhookHandler.List, authMid)
+	e.DELETE("/webhooks/:id", webhookHandler.Delete, authMid)
+	e.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries, authMid)
+	e.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver, authMid)
+
+	// Admin и операторы склада
+	roles := ordermw.Roles{}.
+		Grant(ordermw.RoleAdmin, cfg.AdminUserIDs...).
+		Grant(ordermw.RoleOperator, cfg.OperatorUserIDs...)
+	adminOnly := ordermw.RequireRole(roles, ordermw.RoleAdmin)
+	operators := ordermw.RequireRole(roles, ordermw.RoleOperator, ordermw.RoleAdmin)
+
+	e.POST("/operator/orders/:id/ship", orderHandler.ShipOrder, authMid, operators)
+	e.POST("/operator/orders/:id/deliver", orderHandler.DeliverOrder, authMid, operators)
+
+	// Возвраты (RMA)
+	returnHandler := handler.NewReturnHandler(service.NewReturnService(repository.NewReturnRepository(db), orderService))
+	e.POST("/orders/:id/returns", returnHandler.Create, authMid)
+	e.GET("/orders/:id/returns", returnHandler.List, authMid)
+	e.GET("/operator/returns/:id", returnHandler.Get, authMid, operators)
+	e.POST("/operator/returns/:id/approve", returnHandler.Approve, authMid, operators)
+	e.POST("/operator/returns/:id/reject", returnHandler.Reject, authMid, operators)
+	e.POST("/operator/returns/:id/receive", returnHandler.Receive, authMid, operators)
+	e.POST("/operator/returns/:id/refund", returnHandler.Refund, authMid, operators)
+
+	promotionHandler := handler.NewPromotionHandler(promotionService)
+	e.POST("/admin/promotions", promotionHandler.Create, authMid, adminOnly)
+	e.GET("/admin/promotions", promotionHandler.List, authMid, adminOnly)
+	e.GET("/admin/promotions/:id", promotionHandler.Get, authMid, adminOnly)
+	e.PUT("/admin/promotions/:id", promotionHandler.Update, authMid, adminOnly)
+	e.DELETE("/admin/promotions/:id", promotionHandler.Delete, authMid, adminOnly)
+
+	// Выгрузка заказов: потоком в ответ или асинхронно в файл
+	var exportStore export.Store = repository.NewExportFileStore(db)
+	if cfg.ExportStorage == "local" {
+		localStore, err := export.NewLocalStore(cfg.ExportDir)
+		if err != nil {
+			log.Fatal("Failed to prepare export directory:", err)
+		}
+		exportStore = localStore
+	}
+	exportHandler := handler.NewExportHandler(service.NewExportService(orderRepo, repository.NewExportJobRepository(redisClient), exportStore))
+	e.GET("/admin/orders/export", exportHandler.Export, authMid, adminOnly)
+	e.POST("/admin/orders/exports", exportHandler.StartJob, authMid, adminOnly)
+	e.GET("/admin/orders/exports/:id", exportHandler.GetJob, authMid, adminOnly)
+	e.GET("/admin/orders/exports/:id/download", exportHandler.Download, authMid, adminOnly)
 
 	// Health check
 	e.GET("/health", func(c echo.Context) error {
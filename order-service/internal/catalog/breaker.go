@@ -0,0 +1,84 @@
+// internal/catalog/breaker.go
+package catalog
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния circuit breaker.
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half_open"
+)
+
+// breaker — circuit breaker запросов к каталогу. После failures ошибок подряд он
+// размыкается и cooldown отклоняет запросы сразу, не дожидаясь таймаута. Затем
+// пропускает один пробный запрос: успех замыкает цепь, ошибка размыкает снова.
+type breaker struct {
+	failures int
+	cooldown time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failed   int
+	openedAt time.Time
+	probing  bool // пробный запрос в полуоткрытом состоянии уже идёт
+}
+
+func newBreaker(failures int, cooldown time.Duration) *breaker {
+	return &breaker{failures: max(failures, 1), cooldown: cooldown, state: breakerClosed}
+}
+
+// allow reports whether a request may be sent now; every allowed request must be
+// followed by success, failure or release.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state, b.probing = breakerHalfOpen, true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state, b.failed, b.probing = breakerClosed, 0, false
+}
+
+// failure records a failed request and returns true if it opened the breaker.
+func (b *breaker) failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failed++
+	if b.state == breakerHalfOpen || b.failed >= b.failures {
+		opened := b.state != breakerOpen
+		b.state, b.openedAt, b.probing = breakerOpen, time.Now(), false
+		return opened
+	}
+	return false
+}
+
+// release ends a request that says nothing about the catalog (the caller gave up),
+// so another request can probe it.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
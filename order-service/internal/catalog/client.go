@@ -0,0 +1,193 @@
+// internal/catalog/client.go
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/propagation"
+
+	"order-service/internal/utils"
+)
+
+// ErrUnavailable — каталог не ответил, ответил ошибкой или circuit breaker разомкнут.
+var ErrUnavailable = errors.New("product catalog is unavailable")
+
+// maxIDsPerRequest — лимит ids в одном GET /products?ids= у inventory-service.
+const maxIDsPerRequest = 200
+
+// maxCacheEntries — при большем размере кэш при записи чистится от просроченных товаров.
+const maxCacheEntries = 10_000
+
+// Product — товар каталога inventory-service.
+type Product struct {
+	ID       int64  `json:"id"`
+	SKU      string `json:"sku"`
+	Name     string `json:"name"`
+	Price    int64  `json:"price"` // в минимальных единицах валюты
+	Currency string `json:"currency"`
+	Active   bool   `json:"active"`
+}
+
+type Config struct {
+	URL             string // базовый URL inventory-service
+	Timeout         time.Duration
+	CacheTTL        time.Duration
+	BreakerFailures int           // ошибок подряд до размыкания
+	BreakerCooldown time.Duration // сколько запросы отклоняются сразу после размыкания
+}
+
+// Client читает товары из каталога inventory-service синхронно. Найденные товары
+// кэшируются на CacheTTL, поэтому смена цены доходит до заказов с этой задержкой;
+// отсутствующие не кэшируются — только что заведённый товар виден сразу.
+type Client struct {
+	baseURL string
+	client  *http.Client
+	ttl     time.Duration
+	breaker *breaker
+
+	mu    sync.Mutex
+	cache map[int64]cacheEntry
+}
+
+type cacheEntry struct {
+	product Product
+	expires time.Time
+}
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(cfg.URL, "/"),
+		client:  &http.Client{Timeout: cfg.Timeout},
+		ttl:     cfg.CacheTTL,
+		breaker: newBreaker(cfg.BreakerFailures, cfg.BreakerCooldown),
+		cache:   make(map[int64]cacheEntry),
+	}
+}
+
+// Products returns the catalog products for ids; products the catalog does not
+// know are absent from the map. Cached products are not requested again.
+func (c *Client) Products(ctx context.Context, ids []int64) (map[int64]Product, error) {
+	products := make(map[int64]Product, len(ids))
+	var missing []int64
+	now := time.Now()
+	c.mu.Lock()
+	for _, id := range ids {
+		if e, ok := c.cache[id]; ok && now.Before(e.expires) {
+			products[id] = e.product
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	c.mu.Unlock()
+
+	for start := 0; start < len(missing); start += maxIDsPerRequest {
+		fetched, err := c.fetch(ctx, missing[start:min(start+maxIDsPerRequest, len(missing))])
+		if err != nil {
+			return nil, err
+		}
+		c.store(fetched)
+		for _, p := range fetched {
+			products[p.ID] = p
+		}
+	}
+	return products, nil
+}
+
+func (c *Client) store(products []Product) {
+	expires := time.Now().Add(c.ttl)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.cache) >= maxCacheEntries {
+		now := time.Now()
+		for id, e := range c.cache {
+			if !now.Before(e.expires) {
+				delete(c.cache, id)
+			}
+		}
+	}
+	for _, p := range products {
+		c.cache[p.ID] = cacheEntry{product: p, expires: expires}
+	}
+}
+
+// fetch requests GET /products?ids= through the breaker. Network errors, timeouts
+// and 5xx count as failures; a cancelled caller context does not.
+func (c *Client) fetch(ctx context.Context, ids []int64) ([]Product, error) {
+	if !c.breaker.allow() {
+		return nil, fmt.Errorf("%w: circuit breaker is open", ErrUnavailable)
+	}
+
+	products, err := c.get(ctx, ids)
+	var statusErr *statusError
+	switch {
+	case err == nil:
+		c.breaker.success()
+		return products, nil
+	case errors.As(err, &statusErr) && statusErr.code < http.StatusInternalServerError:
+		// Каталог жив, но запрос ему не понравился — это не сбой
+		c.breaker.success()
+		return nil, err
+	case ctx.Err() != nil:
+		c.breaker.release()
+		return nil, err
+	}
+
+	if c.breaker.failure() {
+		logger := utils.NewHelperLogger("order-service.catalog.client")
+		logger.LogError(ctx, "Product catalog circuit breaker opened", err,
+			log.KeyValue{Key: "cooldown", Value: log.StringValue(c.breaker.cooldown.String())},
+		)
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("catalog responded with status %d", e.code)
+}
+
+func (c *Client) get(ctx context.Context, ids []int64) ([]Product, error) {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatInt(id, 10)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		c.baseURL+"/products?ids="+url.QueryEscape(strings.Join(parts, ",")), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{code: resp.StatusCode}
+	}
+	var body struct {
+		Products []Product `json:"products"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode catalog response: %w", err)
+	}
+	return body.Products, nil
+}
@@ -2,19 +2,72 @@
 package config
 
 import (
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
+	DBDriver   string // postgres (по умолчанию, как в чарте) | mysql
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
+	// AutoMigrate применяет миграции при старте (см. `order-service migrate`).
+	AutoMigrate bool
+
+	RedisAddr     string
+	RedisPassword string
 
 	JWTSecret string
 
+	AdminUserIDs    []int64
+	OperatorUserIDs []int64
+
+	IdempotencyTTL time.Duration
+
+	SSEHeartbeat time.Duration
+
+	WebhookMaxAttempts  int
+	WebhookBaseBackoff  time.Duration
+	WebhookMaxBackoff   time.Duration
+	WebhookPollInterval time.Duration
+	WebhookTimeout      time.Duration
+	// WebhookAllowPrivateHosts — вебхуки на localhost и внутренние адреса (только локально).
+	WebhookAllowPrivateHosts bool
+
+	PaymentProvider      string
+	PaymentWebhookSecret string
+	PaymentWebhookURL    string
+	FakePaymentDelay     time.Duration
+
+	OrderPendingTTL     time.Duration
+	OrderExpiryInterval time.Duration
+	OrderExpiryBatch    int
+
+	PromoOrphanTTL     time.Duration
+	PromoSweepInterval time.Duration
+
+	OrderBatchMax  int
+	OrderBatchMode string
+
+	// ExportStorage — где лежат файлы асинхронных выгрузок: db (общая база, для
+	// нескольких реплик) или local (EXPORT_DIR, только одна реплика).
+	ExportStorage string
+	ExportDir     string
+
+	// CatalogURL — inventory-service с каталогом товаров; пусто — заказы с каталогом не сверяются.
+	CatalogURL             string
+	CatalogTimeout         time.Duration
+	CatalogCacheTTL        time.Duration
+	CatalogBreakerFailures int
+	CatalogBreakerCooldown time.Duration
+
 	KafkaBrokers []string
 
 	OtelExporterURL string
@@ -29,24 +82,125 @@ func Load() *Config {
 		}
 	}
 
+	dbDriver := getEnv("DB_DRIVER", "postgres")
+	dbPort := "5432"
+	if dbDriver == "mysql" {
+		dbPort = "3306"
+	}
+
 	return &Config{
+		DBDriver:   dbDriver,
 		DBHost:     getEnv("DB_HOST", "192.168.0.176"),
-		DBPort:     getEnv("DB_PORT", "3306"),
+		DBPort:     getEnv("DB_PORT", dbPort),
 		DBUser:     getEnv("DB_USER", "order_user"),
 		DBPassword: getEnv("DB_PASSWORD", "order_pass"),
 		DBName:     getEnv("DB_NAME", "order_db"),
 
+		AutoMigrate: getEnv("AUTO_MIGRATE", "false") == "true",
+
+		RedisAddr:     getEnv("REDIS_ADDR", "192.168.0.176:6379"),
+		RedisPassword: getEnv("REDIS_PASSWORD", ""),
+
 		JWTSecret: getEnv("JWT_SECRET", "super-secret-jwt-key"),
 
+		AdminUserIDs:    getInt64List("ADMIN_USER_IDS"),
+		OperatorUserIDs: getInt64List("OPERATOR_USER_IDS"),
+
+		IdempotencyTTL: getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		SSEHeartbeat: getDuration("SSE_HEARTBEAT", 15*time.Second),
+
+		WebhookMaxAttempts:  getInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookBaseBackoff:  getDuration("WEBHOOK_BASE_BACKOFF", 10*time.Second),
+		WebhookMaxBackoff:   getDuration("WEBHOOK_MAX_BACKOFF", time.Hour),
+		WebhookPollInterval: getDuration("WEBHOOK_POLL_INTERVAL", 2*time.Second),
+		WebhookTimeout:      getDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+
+		WebhookAllowPrivateHosts: getEnv("WEBHOOK_ALLOW_PRIVATE_HOSTS", "false") == "true",
+
+		PaymentProvider:      getEnv("PAYMENT_PROVIDER", "fake"),
+		PaymentWebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", "payment-webhook-secret"),
+		PaymentWebhookURL:    getEnv("PAYMENT_WEBHOOK_URL", "http://localhost:8082/payments/webhook"),
+		FakePaymentDelay:     getDuration("FAKE_PAYMENT_DELAY", 2*time.Second),
+
+		OrderPendingTTL:     getDuration("ORDER_PENDING_TTL", 30*time.Minute),
+		OrderExpiryInterval: getDuration("ORDER_EXPIRY_INTERVAL", time.Minute),
+		OrderExpiryBatch:    getInt("ORDER_EXPIRY_BATCH", 100),
+
+		PromoOrphanTTL:     getDuration("PROMO_ORPHAN_TTL", 10*time.Minute),
+		PromoSweepInterval: getDuration("PROMO_SWEEP_INTERVAL", time.Minute),
+
+		OrderBatchMax:  getInt("ORDER_BATCH_MAX", 500),
+		OrderBatchMode: getEnv("ORDER_BATCH_MODE", "partial"),
+
+		ExportStorage: getEnv("EXPORT_STORAGE", "db"),
+		ExportDir:     getEnv("EXPORT_DIR", "/tmp/order-exports"),
+
+		CatalogURL:             getEnv("CATALOG_URL", ""),
+		CatalogTimeout:         getDuration("CATALOG_TIMEOUT", 2*time.Second),
+		CatalogCacheTTL:        getDuration("CATALOG_CACHE_TTL", time.Minute),
+		CatalogBreakerFailures: getInt("CATALOG_BREAKER_FAILURES", 5),
+		CatalogBreakerCooldown: getDuration("CATALOG_BREAKER_COOLDOWN", 30*time.Second),
+
 		KafkaBrokers: kafkaBrokers,
 
 		OtelExporterURL: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "192.168.0.176:4317"),
 	}
 }
 
+// DatabaseDSN returns the connection string for DBDriver.
+func (c *Config) DatabaseDSN() string {
+	if c.DBDriver == "postgres" {
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.DBUser, c.DBPassword),
+			Host:     net.JoinHostPort(c.DBHost, c.DBPort),
+			Path:     c.DBName,
+			RawQuery: "sslmode=disable",
+		}
+		return dsn.String()
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+		c.DBUser,
+		c.DBPassword,
+		c.DBHost,
+		c.DBPort,
+		c.DBName,
+	)
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return fallback
 }
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+func getInt(key string, fallback int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
+
+// getInt64List parses a comma-separated list of IDs; malformed entries are skipped.
+func getInt64List(key string) []int64 {
+	var ids []int64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,36 @@
+// internal/export/store.go
+package export
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Store — хранилище готовых выгрузок. Метаданные задач лежат в Redis и видны всем
+// репликам, поэтому и файл должен быть общим: repository.ExportFileStore хранит его
+// в базе заказов. LocalStore — только для одной реплики (локальный запуск).
+type Store interface {
+	Create(ctx context.Context, name string) (io.WriteCloser, error)
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+}
+
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) Create(_ context.Context, name string) (io.WriteCloser, error) {
+	return os.Create(filepath.Join(s.dir, filepath.Base(name)))
+}
+
+func (s *LocalStore) Open(_ context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, filepath.Base(name)))
+}
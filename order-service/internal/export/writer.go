@@ -0,0 +1,88 @@
+// internal/export/writer.go
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"order-service/internal/model"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Writer пишет заказы построчно; Flush обязателен в конце.
+type Writer interface {
+	Write(order *model.Order) error
+	Flush() error
+}
+
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(csvHeader)
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// csvHeader — одна строка на заказ; позиции выгружаются только в JSONL.
+var csvHeader = []string{
+	"id", "user_id", "status", "status_reason", "currency", "total_amount",
+	"discount_amount", "promo_code", "items", "created_at",
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(o *model.Order) error {
+	return c.w.Write([]string{
+		strconv.FormatInt(o.ID, 10),
+		strconv.FormatInt(o.UserID, 10),
+		o.Status,
+		o.StatusReason,
+		o.Currency,
+		strconv.FormatInt(o.TotalAmount, 10),
+		strconv.FormatInt(o.Discount, 10),
+		o.PromoCode,
+		strconv.Itoa(len(o.Items)),
+		o.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Write(o *model.Order) error {
+	return j.enc.Encode(o)
+}
+
+func (j *jsonlWriter) Flush() error {
+	return nil
+}
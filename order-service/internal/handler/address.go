@@ -0,0 +1,117 @@
+// internal/handler/address.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AddressHandler struct {
+	addressService *service.AddressService
+}
+
+func NewAddressHandler(addressService *service.AddressService) *AddressHandler {
+	return &AddressHandler{addressService: addressService}
+}
+
+type addressRequest struct {
+	model.ShippingAddress
+	IsDefault bool `json:"is_default"`
+}
+
+func (h *AddressHandler) Create(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	req := new(addressRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	address := &model.Address{UserID: userID, ShippingAddress: req.ShippingAddress, IsDefault: req.IsDefault}
+	if err := h.addressService.Create(c.Request().Context(), address); err != nil {
+		return addressError(err)
+	}
+	return c.JSON(http.StatusCreated, address)
+}
+
+func (h *AddressHandler) List(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	addresses, err := h.addressService.List(c.Request().Context(), userID)
+	if err != nil {
+		return addressError(err)
+	}
+	return c.JSON(http.StatusOK, addresses)
+}
+
+func (h *AddressHandler) Get(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	address, err := h.addressService.Get(c.Request().Context(), userID, id)
+	if err != nil {
+		return addressError(err)
+	}
+	return c.JSON(http.StatusOK, address)
+}
+
+func (h *AddressHandler) Update(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	req := new(addressRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	ctx := c.Request().Context()
+	address := &model.Address{ID: id, UserID: userID, ShippingAddress: req.ShippingAddress, IsDefault: req.IsDefault}
+	if err := h.addressService.Update(ctx, address); err != nil {
+		return addressError(err)
+	}
+	address, err = h.addressService.Get(ctx, userID, id)
+	if err != nil {
+		return addressError(err)
+	}
+	return c.JSON(http.StatusOK, address)
+}
+
+func (h *AddressHandler) Delete(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	if err := h.addressService.Delete(c.Request().Context(), userID, id); err != nil {
+		return addressError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func addressError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidAddress):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.ErrNotFound
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
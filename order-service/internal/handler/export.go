@@ -0,0 +1,150 @@
+// internal/handler/export.go
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"order-service/internal/export"
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+var orderStatuses = []string{
+	model.OrderStatusPending,
+	model.OrderStatusPaid,
+	model.OrderStatusCancelled,
+	model.OrderStatusShipped,
+	model.OrderStatusDelivered,
+	model.OrderStatusReturned,
+}
+
+// ExportHandler — выгрузка заказов для финансов (админ).
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// Export streams the orders straight into the response.
+func (h *ExportHandler) Export(c echo.Context) error {
+	format, filter, err := exportParams(c)
+	if err != nil {
+		return err
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, export.ContentType(format))
+	res.Header().Set(echo.HeaderContentDisposition,
+		fmt.Sprintf(`attachment; filename="orders-%s.%s"`, time.Now().UTC().Format("20060102-150405"), format))
+	res.WriteHeader(http.StatusOK)
+
+	// Заголовки уже отправлены: ошибку посреди выгрузки можно только залогировать,
+	// клиент увидит обрыв потока
+	if _, err := h.exportService.Stream(c.Request().Context(), filter, format, res); err != nil {
+		c.Logger().Errorf("order export aborted: %v", err)
+	}
+	return nil
+}
+
+// StartJob starts an asynchronous export and returns its job.
+func (h *ExportHandler) StartJob(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	format, filter, err := exportParams(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.exportService.StartJob(c.Request().Context(), userID, filter, format)
+	if err != nil {
+		return exportError(err)
+	}
+	return c.JSON(http.StatusAccepted, job)
+}
+
+func (h *ExportHandler) GetJob(c echo.Context) error {
+	job, err := h.exportService.GetJob(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return exportError(err)
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+func (h *ExportHandler) Download(c echo.Context) error {
+	job, f, err := h.exportService.OpenResult(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return exportError(err)
+	}
+	defer f.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, export.ContentType(job.Format))
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, job.FileName))
+	res.WriteHeader(http.StatusOK)
+	_, err = io.Copy(res, f)
+	return err
+}
+
+// exportParams reads format, from, to and status from the query string.
+// from/to accept RFC 3339 or a plain date (YYYY-MM-DD, UTC).
+func exportParams(c echo.Context) (string, model.OrderFilter, error) {
+	var filter model.OrderFilter
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if format != export.FormatCSV && format != export.FormatJSONL {
+		return "", filter, echo.NewHTTPError(http.StatusBadRequest, "format must be csv or jsonl")
+	}
+
+	var err error
+	if filter.From, err = parseExportTime(c.QueryParam("from")); err != nil {
+		return "", filter, echo.NewHTTPError(http.StatusBadRequest, "from must be RFC 3339 or YYYY-MM-DD")
+	}
+	if filter.To, err = parseExportTime(c.QueryParam("to")); err != nil {
+		return "", filter, echo.NewHTTPError(http.StatusBadRequest, "to must be RFC 3339 or YYYY-MM-DD")
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.To.After(filter.From) {
+		return "", filter, echo.NewHTTPError(http.StatusBadRequest, "to must be after from")
+	}
+
+	filter.Status = c.QueryParam("status")
+	if filter.Status != "" && !slices.Contains(orderStatuses, filter.Status) {
+		return "", filter, echo.NewHTTPError(http.StatusBadRequest, "unknown status")
+	}
+	return format, filter, nil
+}
+
+func parseExportTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+func exportError(err error) error {
+	switch {
+	case errors.Is(err, export.ErrUnknownFormat):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrExportNotReady):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.ErrNotFound
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
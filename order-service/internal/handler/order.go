@@ -2,13 +2,26 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"regexp"
+	"strconv"
 
+	"order-service/internal/model"
+	"order-service/internal/repository"
 	"order-service/internal/service"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	maxOrderItems = 100
+	maxUnitPrice  = 1_000_000_000_00 // 1 млрд в основных единицах валюты
+	maxQuantity   = 10_000
+)
+
+var currencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
 type OrderHandler struct {
 	orderService *service.OrderService
 }
@@ -17,26 +30,228 @@ func NewOrderHandler(orderService *service.OrderService) *OrderHandler {
 	return &OrderHandler{orderService: orderService}
 }
 
+type orderItemRequest struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+	UnitPrice int64 `json:"unit_price"` // в минимальных единицах валюты
+}
+
+type createOrderRequest struct {
+	Currency        string                 `json:"currency"`
+	Items           []orderItemRequest     `json:"items"`
+	PromoCode       string                 `json:"promo_code"`
+	AddressID       int64                  `json:"address_id"`
+	ShippingAddress *model.ShippingAddress `json:"shipping_address"`
+}
+
 func (h *OrderHandler) CreateOrder(c echo.Context) error {
 	userID, _ := c.Get("user_id").(int64) // можно передавать через контекст в middleware
 
+	req := new(createOrderRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	input, err := orderInput(req)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	order, err := h.orderService.CreateOrder(c.Request().Context(), userID, input)
+	if err != nil {
+		return createOrderError(err)
+	}
+
+	return c.JSON(http.StatusCreated, order)
+}
+
+// orderInput validates a new order request.
+func orderInput(req *createOrderRequest) (service.CreateOrderInput, error) {
+	if !currencyRe.MatchString(req.Currency) {
+		return service.CreateOrderInput{}, errors.New("currency must be an ISO 4217 code, e.g. RUB")
+	}
+	if len(req.Items) == 0 {
+		return service.CreateOrderInput{}, errors.New("items must not be empty")
+	}
+	if len(req.Items) > maxOrderItems {
+		return service.CreateOrderInput{}, errors.New("too many items")
+	}
+
+	items := make([]model.OrderItem, 0, len(req.Items))
+	seen := make(map[int64]bool, len(req.Items))
+	for _, it := range req.Items {
+		if it.ProductID <= 0 {
+			return service.CreateOrderInput{}, errors.New("product_id must be positive")
+		}
+		if seen[it.ProductID] {
+			return service.CreateOrderInput{}, errors.New("duplicate product_id in items")
+		}
+		seen[it.ProductID] = true
+		if it.Quantity <= 0 || it.Quantity > maxQuantity {
+			return service.CreateOrderInput{}, errors.New("quantity must be positive")
+		}
+		if it.UnitPrice < 0 || it.UnitPrice > maxUnitPrice {
+			return service.CreateOrderInput{}, errors.New("unit_price is out of range")
+		}
+		items = append(items, model.OrderItem{
+			ProductID: it.ProductID,
+			Quantity:  it.Quantity,
+			UnitPrice: it.UnitPrice,
+		})
+	}
+
+	if len(req.PromoCode) > 64 {
+		return service.CreateOrderInput{}, errors.New("promo_code is too long")
+	}
+
+	return service.CreateOrderInput{
+		Currency:  req.Currency,
+		Items:     items,
+		PromoCode: req.PromoCode,
+		AddressID: req.AddressID,
+		Shipping:  req.ShippingAddress,
+	}, nil
+}
+
+func createOrderError(err error) *echo.HTTPError {
+	switch {
+	case errors.Is(err, service.ErrInvalidAddress):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrPromotionNotApplicable), errors.Is(err, service.ErrInvalidOrderItems):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, service.ErrCatalogUnavailable):
+		return echo.NewHTTPError(http.StatusServiceUnavailable, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+func (h *OrderHandler) GetOrder(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	order, err := h.orderService.GetOrder(c.Request().Context(), userID, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.ErrNotFound
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, order)
+}
+
+func (h *OrderHandler) CancelOrder(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
 	type Request struct {
-		ProductID int64 `json:"product_id"`
-		Quantity  int   `json:"quantity"`
+		Reason string `json:"reason"`
 	}
 
 	req := new(Request)
 	if err := c.Bind(req); err != nil {
 		return echo.ErrBadRequest
 	}
+	if len(req.Reason) > 255 {
+		return echo.NewHTTPError(http.StatusBadRequest, "reason is too long")
+	}
 
-	if req.Quantity <= 0 {
-		return echo.NewHTTPError(http.StatusBadRequest, "quantity must be positive")
+	order, err := h.orderService.CancelOrder(c.Request().Context(), userID, orderID, req.Reason)
+	if err != nil {
+		return orderError(err)
 	}
+	return c.JSON(http.StatusOK, order)
+}
 
-	if err := h.orderService.CreateOrder(c.Request().Context(), userID, req.ProductID, req.Quantity); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+func (h *OrderHandler) History(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	history, err := h.orderService.History(c.Request().Context(), userID, orderID)
+	if err != nil {
+		return orderError(err)
 	}
+	return c.JSON(http.StatusOK, history)
+}
+
+func (h *OrderHandler) Shipment(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
 
-	return c.JSON(http.StatusCreated, map[string]string{"message": "Order created"})
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	shipment, err := h.orderService.GetShipment(c.Request().Context(), userID, orderID)
+	if err != nil {
+		return orderError(err)
+	}
+	return c.JSON(http.StatusOK, shipment)
+}
+
+// ShipOrder — эндпоинт оператора склада: заказ передан перевозчику.
+func (h *OrderHandler) ShipOrder(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	type Request struct {
+		Carrier        string `json:"carrier"`
+		TrackingNumber string `json:"tracking_number"`
+	}
+
+	req := new(Request)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+	if req.Carrier == "" || req.TrackingNumber == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "carrier and tracking_number are required")
+	}
+	if len(req.Carrier) > 64 || len(req.TrackingNumber) > 128 {
+		return echo.NewHTTPError(http.StatusBadRequest, "carrier or tracking_number is too long")
+	}
+
+	shipment, err := h.orderService.ShipOrder(c.Request().Context(), orderID, req.Carrier, req.TrackingNumber)
+	if err != nil {
+		return orderError(err)
+	}
+	return c.JSON(http.StatusOK, shipment)
+}
+
+// DeliverOrder — эндпоинт оператора склада: заказ вручён.
+func (h *OrderHandler) DeliverOrder(c echo.Context) error {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	order, err := h.orderService.DeliverOrder(c.Request().Context(), orderID)
+	if err != nil {
+		return orderError(err)
+	}
+	return c.JSON(http.StatusOK, order)
+}
+
+func orderError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.ErrNotFound
+	case errors.Is(err, service.ErrInvalidOrderState):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
 }
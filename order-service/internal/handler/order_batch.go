@@ -0,0 +1,141 @@
+// internal/handler/order_batch.go
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"order-service/internal/model"
+	"order-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	BatchModeAtomic  = "atomic"  // все заказы в одной транзакции
+	BatchModePartial = "partial" // каждый заказ сам по себе
+)
+
+// OrderBatchHandler — пакетное создание заказов для B2B-клиентов.
+type OrderBatchHandler struct {
+	orderService *service.OrderService
+	maxOrders    int
+	defaultMode  string
+}
+
+func NewOrderBatchHandler(orderService *service.OrderService, maxOrders int, defaultMode string) *OrderBatchHandler {
+	if defaultMode != BatchModeAtomic {
+		defaultMode = BatchModePartial
+	}
+	return &OrderBatchHandler{orderService: orderService, maxOrders: maxOrders, defaultMode: defaultMode}
+}
+
+type batchOrderResult struct {
+	Index  int          `json:"index"`
+	Status string       `json:"status"` // created | failed
+	Order  *model.Order `json:"order,omitempty"`
+	Code   int          `json:"code,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// CreateOrders creates up to maxOrders orders and reports the outcome of each.
+// The response is 201 if every order was created, 207 if only some were and
+// 422 if none were.
+func (h *OrderBatchHandler) CreateOrders(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	type Request struct {
+		Mode   string               `json:"mode"`
+		Orders []createOrderRequest `json:"orders"`
+	}
+
+	req := new(Request)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = h.defaultMode
+	}
+	if mode != BatchModeAtomic && mode != BatchModePartial {
+		return echo.NewHTTPError(http.StatusBadRequest, "mode must be atomic or partial")
+	}
+	if len(req.Orders) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "orders must not be empty")
+	}
+	if len(req.Orders) > h.maxOrders {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("at most %d orders per batch", h.maxOrders))
+	}
+
+	results := make([]batchOrderResult, len(req.Orders))
+	inputs := make([]service.CreateOrderInput, 0, len(req.Orders))
+	indexes := make([]int, 0, len(req.Orders)) // позиция input в исходной пачке
+	for i := range req.Orders {
+		results[i].Index = i
+		input, err := orderInput(&req.Orders[i])
+		if err != nil {
+			results[i].Status = "failed"
+			results[i].Code = http.StatusBadRequest
+			results[i].Error = err.Error()
+			continue
+		}
+		inputs = append(inputs, input)
+		indexes = append(indexes, i)
+	}
+
+	// В атомарном режиме невалидный заказ отменяет всю пачку ещё до базы
+	if mode == BatchModeAtomic && len(inputs) < len(req.Orders) {
+		for _, i := range indexes {
+			results[i].Status = "failed"
+			results[i].Code = http.StatusConflict
+			results[i].Error = service.ErrBatchRolledBack.Error()
+		}
+		inputs = nil
+	}
+
+	if len(inputs) > 0 {
+		created := h.orderService.CreateOrders(c.Request().Context(), userID, inputs, mode == BatchModeAtomic)
+		for j, r := range created {
+			res := &results[indexes[j]]
+			if r.Err != nil {
+				httpErr := batchOrderError(r.Err)
+				res.Status = "failed"
+				res.Code = httpErr.Code
+				res.Error = fmt.Sprint(httpErr.Message)
+				continue
+			}
+			res.Status = "created"
+			res.Order = r.Order
+		}
+	}
+
+	var ok int
+	for _, r := range results {
+		if r.Status == "created" {
+			ok++
+		}
+	}
+	status := http.StatusMultiStatus
+	switch ok {
+	case len(results):
+		status = http.StatusCreated
+	case 0:
+		status = http.StatusUnprocessableEntity
+	}
+
+	return c.JSON(status, map[string]any{
+		"mode":    mode,
+		"created": ok,
+		"failed":  len(results) - ok,
+		"results": results,
+	})
+}
+
+func batchOrderError(err error) *echo.HTTPError {
+	if errors.Is(err, service.ErrBatchRolledBack) {
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return createOrderError(err)
+}
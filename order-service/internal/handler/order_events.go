@@ -0,0 +1,129 @@
+// internal/handler/order_events.go
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+var streamIDRe = regexp.MustCompile(`^\d+-\d+$`)
+
+// OrderEventsHandler отдаёт изменения статуса заказа через Server-Sent Events.
+type OrderEventsHandler struct {
+	orderService  *service.OrderService
+	streamService *service.StatusStreamService
+	heartbeat     time.Duration
+}
+
+func NewOrderEventsHandler(orderService *service.OrderService, streamService *service.StatusStreamService, heartbeat time.Duration) *OrderEventsHandler {
+	return &OrderEventsHandler{orderService: orderService, streamService: streamService, heartbeat: heartbeat}
+}
+
+// Stream serves GET /orders/:id/events. A reconnecting client sends Last-Event-ID
+// and receives everything it missed; a new client first gets a "snapshot" event
+// with the current status. Heartbeat comments keep proxies from closing the connection.
+func (h *OrderEventsHandler) Stream(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+	ctx := c.Request().Context()
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	cursor := c.Request().Header.Get("Last-Event-ID")
+	if cursor != "" && !streamIDRe.MatchString(cursor) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid Last-Event-ID")
+	}
+	resume := cursor != ""
+	if !resume {
+		// Позицию берём до снапшота: событие между ними придёт дважды, но не потеряется
+		if cursor, err = h.streamService.Cursor(ctx, orderID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+	}
+
+	order, err := h.orderService.GetOrder(ctx, userID, orderID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.ErrNotFound
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if !resume {
+		snapshot := model.OrderStatusEvent{
+			Type:      "snapshot",
+			OrderID:   order.ID,
+			Status:    order.Status,
+			CreatedAt: time.Now().UTC(),
+		}
+		if err := writeSSE(res, snapshot); err != nil {
+			return nil
+		}
+	}
+	fmt.Fprintf(res, "retry: %d\n\n", 3000)
+	res.Flush()
+
+	sub := h.streamService.Subscribe(orderID)
+	defer sub.Close()
+
+	for {
+		events, err := sub.Next(ctx, cursor, h.heartbeat)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			c.Logger().Errorf("order %d status stream: %v", orderID, err)
+			return nil
+		}
+
+		if len(events) == 0 {
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+			continue
+		}
+
+		for _, event := range events {
+			if err := writeSSE(res, event); err != nil {
+				return nil
+			}
+			cursor = event.ID
+		}
+	}
+}
+
+func writeSSE(res *echo.Response, event model.OrderStatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if event.ID != "" {
+		fmt.Fprintf(res, "id: %s\n", event.ID)
+	}
+	if _, err := fmt.Fprintf(res, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	res.Flush()
+	return nil
+}
@@ -0,0 +1,95 @@
+// internal/handler/payment.go
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"order-service/internal/payment"
+	"order-service/internal/repository"
+	"order-service/internal/service"
+	"order-service/internal/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Допустимое расхождение часов с провайдером при проверке подписи
+const paymentSignatureTolerance = 5 * time.Minute
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+	webhookSecret  string
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService, webhookSecret string) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService, webhookSecret: webhookSecret}
+}
+
+func (h *PaymentHandler) Pay(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	type Request struct {
+		PaymentMethod string `json:"payment_method"`
+	}
+
+	req := new(Request)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+	if req.PaymentMethod == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "payment_method is required")
+	}
+
+	p, err := h.paymentService.Pay(c.Request().Context(), userID, orderID, req.PaymentMethod)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return echo.ErrNotFound
+		case errors.Is(err, service.ErrInvalidOrderState):
+			return echo.NewHTTPError(http.StatusConflict, "order is not awaiting payment")
+		case errors.Is(err, service.ErrPaymentDeclined):
+			return echo.NewHTTPError(http.StatusPaymentRequired, err.Error())
+		default:
+			return echo.NewHTTPError(http.StatusBadGateway, err.Error())
+		}
+	}
+
+	// Заказ станет paid после уведомления провайдера о списании
+	return c.JSON(http.StatusAccepted, p)
+}
+
+// Webhook receives asynchronous notifications from the payment provider.
+func (h *PaymentHandler) Webhook(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 64<<10))
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	signature := c.Request().Header.Get(payment.SignatureHeader)
+	if err := utils.VerifyPayload(h.webhookSecret, signature, body, paymentSignatureTolerance); err != nil {
+		return echo.ErrUnauthorized
+	}
+
+	var n payment.Notification
+	if err := json.Unmarshal(body, &n); err != nil || n.ProviderPaymentID == "" {
+		return echo.ErrBadRequest
+	}
+
+	if err := h.paymentService.HandleNotification(c.Request().Context(), n); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return echo.ErrNotFound
+		}
+		// 5xx — провайдер повторит уведомление позже
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,150 @@
+// internal/handler/promotion.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PromotionHandler — админские CRUD-эндпоинты промокодов.
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+}
+
+func NewPromotionHandler(promotionService *service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{promotionService: promotionService}
+}
+
+type promotionRequest struct {
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          int64      `json:"value"`
+	Currency       string     `json:"currency"`
+	MinOrderAmount int64      `json:"min_order_amount"`
+	MaxUses        int        `json:"max_uses"`
+	MaxUsesPerUser int        `json:"max_uses_per_user"`
+	ProductIDs     []int64    `json:"product_ids"`
+	StartsAt       *time.Time `json:"starts_at"`
+	EndsAt         *time.Time `json:"ends_at"`
+	Active         *bool      `json:"active"`
+}
+
+func (r *promotionRequest) toModel() (*model.Promotion, error) {
+	if r.Currency != "" && !currencyRe.MatchString(r.Currency) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "currency must be an ISO 4217 code, e.g. RUB")
+	}
+	p := &model.Promotion{
+		Code:           r.Code,
+		Type:           r.Type,
+		Value:          r.Value,
+		Currency:       r.Currency,
+		MinOrderAmount: r.MinOrderAmount,
+		MaxUses:        r.MaxUses,
+		MaxUsesPerUser: r.MaxUsesPerUser,
+		ProductIDs:     r.ProductIDs,
+		StartsAt:       r.StartsAt,
+		EndsAt:         r.EndsAt,
+		Active:         true,
+	}
+	if r.Active != nil {
+		p.Active = *r.Active
+	}
+	return p, nil
+}
+
+func (h *PromotionHandler) Create(c echo.Context) error {
+	req := new(promotionRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+	p, err := req.toModel()
+	if err != nil {
+		return err
+	}
+
+	if err := h.promotionService.Create(c.Request().Context(), p); err != nil {
+		return promotionError(err)
+	}
+	return c.JSON(http.StatusCreated, p)
+}
+
+func (h *PromotionHandler) List(c echo.Context) error {
+	promotions, err := h.promotionService.List(c.Request().Context())
+	if err != nil {
+		return promotionError(err)
+	}
+	return c.JSON(http.StatusOK, promotions)
+}
+
+func (h *PromotionHandler) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	p, err := h.promotionService.Get(c.Request().Context(), id)
+	if err != nil {
+		return promotionError(err)
+	}
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *PromotionHandler) Update(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	req := new(promotionRequest)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+	p, err := req.toModel()
+	if err != nil {
+		return err
+	}
+	p.ID = id
+
+	ctx := c.Request().Context()
+	if err := h.promotionService.Update(ctx, p); err != nil {
+		return promotionError(err)
+	}
+	p, err = h.promotionService.Get(ctx, id)
+	if err != nil {
+		return promotionError(err)
+	}
+	return c.JSON(http.StatusOK, p)
+}
+
+func (h *PromotionHandler) Delete(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	if err := h.promotionService.Delete(c.Request().Context(), id); err != nil {
+		return promotionError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func promotionError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidPromotion):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrAlreadyExists):
+		return echo.NewHTTPError(http.StatusConflict, "promotion with this code already exists")
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.ErrNotFound
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
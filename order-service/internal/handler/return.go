@@ -0,0 +1,199 @@
+// internal/handler/return.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxReturnNote = 200
+
+type ReturnHandler struct {
+	returnService *service.ReturnService
+}
+
+func NewReturnHandler(returnService *service.ReturnService) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService}
+}
+
+func (h *ReturnHandler) Create(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	type Item struct {
+		ProductID int64  `json:"product_id"`
+		Quantity  int    `json:"quantity"`
+		Reason    string `json:"reason"`
+	}
+	type Request struct {
+		Items []Item `json:"items"`
+	}
+
+	req := new(Request)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+	if len(req.Items) > maxOrderItems {
+		return echo.NewHTTPError(http.StatusBadRequest, "too many items")
+	}
+
+	items := make([]model.ReturnItem, 0, len(req.Items))
+	for _, it := range req.Items {
+		if it.Reason == "" {
+			return echo.NewHTTPError(http.StatusBadRequest, "reason is required for every item")
+		}
+		if len(it.Reason) > 255 {
+			return echo.NewHTTPError(http.StatusBadRequest, "reason is too long")
+		}
+		items = append(items, model.ReturnItem{ProductID: it.ProductID, Quantity: it.Quantity, Reason: it.Reason})
+	}
+
+	ret, err := h.returnService.RequestReturn(c.Request().Context(), userID, orderID, items)
+	if err != nil {
+		return returnError(err)
+	}
+	return c.JSON(http.StatusCreated, ret)
+}
+
+func (h *ReturnHandler) List(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	returns, err := h.returnService.List(c.Request().Context(), userID, orderID)
+	if err != nil {
+		return returnError(err)
+	}
+	return c.JSON(http.StatusOK, returns)
+}
+
+// Get — эндпоинт оператора: возврат по ID.
+func (h *ReturnHandler) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	ret, err := h.returnService.Get(c.Request().Context(), id)
+	if err != nil {
+		return returnError(err)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (h *ReturnHandler) Approve(c echo.Context) error {
+	id, note, err := returnDecision(c)
+	if err != nil {
+		return err
+	}
+
+	ret, err := h.returnService.Approve(c.Request().Context(), id, note)
+	if err != nil {
+		return returnError(err)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (h *ReturnHandler) Reject(c echo.Context) error {
+	id, note, err := returnDecision(c)
+	if err != nil {
+		return err
+	}
+
+	ret, err := h.returnService.Reject(c.Request().Context(), id, note)
+	if err != nil {
+		return returnError(err)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+// Receive — товар пришёл на склад; quarantine отправляет его в карантинный остаток.
+func (h *ReturnHandler) Receive(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	type Request struct {
+		Quarantine bool   `json:"quarantine"`
+		Note       string `json:"note"`
+	}
+
+	req := new(Request)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+	if len(req.Note) > maxReturnNote {
+		return echo.NewHTTPError(http.StatusBadRequest, "note is too long")
+	}
+
+	ret, err := h.returnService.Receive(c.Request().Context(), id, req.Quarantine, req.Note)
+	if err != nil {
+		if ret != nil {
+			// Товар принят, но деньги не вернулись — повторить через /refund
+			return c.JSON(http.StatusAccepted, ret)
+		}
+		return returnError(err)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (h *ReturnHandler) Refund(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	ret, err := h.returnService.Refund(c.Request().Context(), id)
+	if err != nil {
+		return returnError(err)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func returnDecision(c echo.Context) (int64, string, error) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, "", echo.ErrBadRequest
+	}
+
+	type Request struct {
+		Note string `json:"note"`
+	}
+
+	req := new(Request)
+	if err := c.Bind(req); err != nil {
+		return 0, "", echo.ErrBadRequest
+	}
+	if len(req.Note) > maxReturnNote {
+		return 0, "", echo.NewHTTPError(http.StatusBadRequest, "note is too long")
+	}
+	return id, req.Note, nil
+}
+
+func returnError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidReturn):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrInvalidOrderState), errors.Is(err, service.ErrInvalidReturnState):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.ErrNotFound
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
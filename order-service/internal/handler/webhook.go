@@ -0,0 +1,116 @@
+// internal/handler/webhook.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"order-service/internal/repository"
+	"order-service/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+func (h *WebhookHandler) Register(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	type Request struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+
+	req := new(Request)
+	if err := c.Bind(req); err != nil {
+		return echo.ErrBadRequest
+	}
+
+	webhook, err := h.webhookService.Register(c.Request().Context(), userID, req.URL, req.Events)
+	if err != nil {
+		return webhookError(err)
+	}
+
+	// Секрет показывается только один раз — при создании
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *WebhookHandler) List(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	webhooks, err := h.webhookService.List(c.Request().Context(), userID)
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	if err := h.webhookService.Delete(c.Request().Context(), userID, id); err != nil {
+		return webhookError(err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) Deliveries(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	limit := 50
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+
+	deliveries, err := h.webhookService.Deliveries(c.Request().Context(), userID, id, limit)
+	if err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) Redeliver(c echo.Context) error {
+	userID, _ := c.Get("user_id").(int64)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		return echo.ErrBadRequest
+	}
+
+	if err := h.webhookService.Redeliver(c.Request().Context(), userID, id, deliveryID); err != nil {
+		return webhookError(err)
+	}
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "Redelivery queued"})
+}
+
+func webhookError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidWebhook):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrNotFound):
+		return echo.ErrNotFound
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
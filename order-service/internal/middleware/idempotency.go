@@ -0,0 +1,204 @@
+// internal/middleware/idempotency.go
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"order-service/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	// Блокировка живёт idempotencyLockTTL, пока её каждые idempotencyLockRenew
+	// продлевает обработчик; TTL нужен только на случай, если под умер.
+	idempotencyLockTTL   = 30 * time.Second
+	idempotencyLockRenew = 10 * time.Second
+	idempotencyLockWait  = 10 * time.Second
+	idempotencyPoll      = 50 * time.Millisecond
+)
+
+// IdempotencyStore хранит ответы и блокировки по ключу идемпотентности.
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (*model.IdempotencyRecord, error)
+	Save(ctx context.Context, key string, record *model.IdempotencyRecord, ttl time.Duration) error
+	Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error)
+	// Extend returns false if the lock is no longer held with token.
+	Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// Idempotency replays the stored response for a repeated Idempotency-Key.
+// A repeat with a different request body is rejected with 422, and concurrent
+// requests with the same key are serialized by a lock in the store.
+// Requests without the header pass through untouched.
+func Idempotency(store IdempotencyStore, ttl time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			idemKey := c.Request().Header.Get(IdempotencyKeyHeader)
+			if idemKey == "" {
+				return next(c)
+			}
+			if len(idemKey) > 255 {
+				return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key is too long")
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return echo.ErrBadRequest
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			// Ключи разных пользователей не должны пересекаться
+			userID, _ := c.Get("user_id").(int64)
+			key := fmt.Sprintf("%d:%s", userID, idemKey)
+			fingerprint := requestFingerprint(c.Request(), body)
+
+			ctx := c.Request().Context()
+			token, err := acquireLock(ctx, store, key)
+			if err != nil {
+				return err
+			}
+			defer store.Unlock(context.WithoutCancel(ctx), key, token)
+
+			// Продлеваем блокировку до сохранения ответа: долгий обработчик не
+			// должен отпустить ключ параллельному повтору
+			renewCtx, stopRenew := context.WithCancel(context.WithoutCancel(ctx))
+			renewDone := make(chan struct{})
+			go func() {
+				defer close(renewDone)
+				renewLock(renewCtx, c, store, key, token)
+			}()
+			defer func() {
+				stopRenew()
+				<-renewDone
+			}()
+
+			record, err := store.Get(ctx, key)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+			}
+			if record != nil {
+				if record.Fingerprint != fingerprint {
+					return echo.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+				}
+				c.Response().Header().Set(IdempotencyReplayedHeader, "true")
+				return c.Blob(record.StatusCode, record.ContentType, record.Body)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			// 5xx не кэшируем — клиент должен иметь возможность повторить запрос
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				return nil
+			}
+
+			record = &model.IdempotencyRecord{
+				Fingerprint: fingerprint,
+				StatusCode:  status,
+				ContentType: c.Response().Header().Get(echo.HeaderContentType),
+				Body:        recorder.body.Bytes(),
+			}
+			if err := store.Save(context.WithoutCancel(ctx), key, record, ttl); err != nil {
+				c.Logger().Errorf("failed to save idempotency record: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+func acquireLock(ctx context.Context, store IdempotencyStore, key string) (string, error) {
+	deadline := time.Now().Add(idempotencyLockWait)
+	for {
+		token, ok, err := store.Lock(ctx, key, idempotencyLockTTL)
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+		if ok {
+			return token, nil
+		}
+		if time.Now().After(deadline) {
+			return "", echo.NewHTTPError(http.StatusConflict, "a request with the same Idempotency-Key is still in progress")
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(idempotencyPoll):
+		}
+	}
+}
+
+// renewLock extends the lock every idempotencyLockRenew until ctx is cancelled.
+func renewLock(ctx context.Context, c echo.Context, store IdempotencyStore, key, token string) {
+	ticker := time.NewTicker(idempotencyLockRenew)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		held, err := store.Extend(ctx, key, token, idempotencyLockTTL)
+		if err != nil {
+			// Следующая попытка может успеть до истечения TTL
+			c.Logger().Errorf("failed to extend idempotency lock: %v", err)
+			continue
+		}
+		if !held {
+			c.Logger().Errorf("idempotency lock for %q expired while the request was in progress", key)
+			return
+		}
+	}
+}
+
+func requestFingerprint(req *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.Path))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the client into body.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
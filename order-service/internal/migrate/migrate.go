@@ -0,0 +1,27 @@
+// internal/migrate/migrate.go
+
+// Package migrate — миграции order-service: файлы migrations/<dialect>/ и общий
+// мигратор pkg/migrate.
+package migrate
+
+import (
+	"database/sql"
+	"embed"
+	"path"
+
+	"pkg/migrate"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// lockName — имя advisory lock; одинаковое у всех реплик сервиса.
+const lockName = "order-service.migrate"
+
+func New(db *sql.DB, dialect string) (*migrate.Migrator, error) {
+	driver, err := migrate.NewSQLDriver(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(driver, lockName, migrationsFS, path.Join("migrations", dialect))
+}
@@ -0,0 +1,10 @@
+-- Исходная схема (как init-sql/orders-init.sql). На базе, созданной им, миграция
+-- ничего не меняет; дальнейшие изменения схемы — только следующими миграциями.
+CREATE TABLE IF NOT EXISTS orders (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  user_id BIGINT NOT NULL,
+  product_id BIGINT NOT NULL,
+  quantity INT NOT NULL,
+  status VARCHAR(50) DEFAULT 'pending',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
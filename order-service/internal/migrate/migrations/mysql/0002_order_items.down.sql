@@ -0,0 +1,14 @@
+-- У заказа остаётся первая позиция
+ALTER TABLE orders
+  ADD COLUMN product_id BIGINT NOT NULL DEFAULT 0 AFTER user_id,
+  ADD COLUMN quantity INT NOT NULL DEFAULT 0 AFTER product_id;
+UPDATE orders o
+JOIN (SELECT order_id, MIN(id) AS id FROM order_items GROUP BY order_id) f ON f.order_id = o.id
+JOIN order_items i ON i.id = f.id
+SET o.product_id = i.product_id, o.quantity = i.quantity;
+ALTER TABLE orders
+  ALTER COLUMN product_id DROP DEFAULT,
+  ALTER COLUMN quantity DROP DEFAULT,
+  DROP COLUMN total_amount,
+  DROP COLUMN currency;
+DROP TABLE IF EXISTS order_items;
@@ -0,0 +1,24 @@
+-- Заказ из нескольких позиций с ценами и валютой
+CREATE TABLE IF NOT EXISTS order_items (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  order_id BIGINT NOT NULL,
+  product_id BIGINT NOT NULL,
+  quantity INT NOT NULL,
+  unit_price BIGINT NOT NULL,
+  line_total BIGINT NOT NULL,
+  UNIQUE KEY uq_order_product (order_id, product_id),
+  FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+-- Старые заказы — одна позиция без цены: валюта XXX (ISO 4217, «без валюты»), сумма 0
+INSERT INTO order_items (order_id, product_id, quantity, unit_price, line_total)
+SELECT id, product_id, quantity, 0, 0 FROM orders WHERE quantity > 0;
+
+ALTER TABLE orders
+  ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'XXX' AFTER status,
+  ADD COLUMN total_amount BIGINT NOT NULL DEFAULT 0 AFTER currency;
+ALTER TABLE orders
+  ALTER COLUMN currency DROP DEFAULT,
+  ALTER COLUMN total_amount DROP DEFAULT,
+  DROP COLUMN product_id,
+  DROP COLUMN quantity;
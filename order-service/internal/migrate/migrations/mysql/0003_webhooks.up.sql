@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  user_id BIGINT NOT NULL,
+  url VARCHAR(2048) NOT NULL,
+  secret VARCHAR(128) NOT NULL,
+  events VARCHAR(1024) NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_webhooks_user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  webhook_id BIGINT NOT NULL,
+  event_id VARCHAR(64) NOT NULL,
+  event_type VARCHAR(64) NOT NULL,
+  payload MEDIUMBLOB NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  response_code INT,
+  last_error TEXT,
+  next_attempt_at TIMESTAMP NOT NULL,
+  delivered_at TIMESTAMP NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_webhook_deliveries_due (status, next_attempt_at),
+  KEY idx_webhook_deliveries_webhook (webhook_id, id),
+  FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE
+);
@@ -0,0 +1,28 @@
+ALTER TABLE orders ADD COLUMN status_reason VARCHAR(255) AFTER total_amount;
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  order_id BIGINT NOT NULL,
+  from_status VARCHAR(50),
+  to_status VARCHAR(50) NOT NULL,
+  reason VARCHAR(255),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_history_order (order_id, id),
+  FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS payments (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  order_id BIGINT NOT NULL,
+  provider VARCHAR(32) NOT NULL,
+  provider_payment_id VARCHAR(128) NOT NULL,
+  amount BIGINT NOT NULL,
+  currency CHAR(3) NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  failure_reason VARCHAR(255),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_provider_payment (provider, provider_payment_id),
+  KEY idx_payments_order (order_id, id),
+  FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
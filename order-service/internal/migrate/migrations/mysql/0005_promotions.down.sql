@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS promotion_usages;
+DROP TABLE IF EXISTS promotions;
+ALTER TABLE orders DROP COLUMN discount_amount, DROP COLUMN promo_code;
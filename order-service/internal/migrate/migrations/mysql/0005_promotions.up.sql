@@ -0,0 +1,30 @@
+ALTER TABLE orders
+  ADD COLUMN promo_code VARCHAR(64) AFTER status_reason,
+  ADD COLUMN discount_amount BIGINT NOT NULL DEFAULT 0 AFTER promo_code;
+
+CREATE TABLE IF NOT EXISTS promotions (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  code VARCHAR(64) NOT NULL UNIQUE,
+  type VARCHAR(20) NOT NULL,
+  value BIGINT NOT NULL,
+  currency CHAR(3),
+  min_order_amount BIGINT NOT NULL DEFAULT 0,
+  max_uses INT NOT NULL DEFAULT 0,
+  max_uses_per_user INT NOT NULL DEFAULT 0,
+  used_count INT NOT NULL DEFAULT 0,
+  product_ids VARCHAR(2048),
+  starts_at TIMESTAMP NULL,
+  ends_at TIMESTAMP NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS promotion_usages (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  promotion_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL,
+  order_id BIGINT UNIQUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_promotion_usages_user (promotion_id, user_id),
+  FOREIGN KEY (promotion_id) REFERENCES promotions (id) ON DELETE CASCADE
+);
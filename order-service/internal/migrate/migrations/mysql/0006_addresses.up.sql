@@ -0,0 +1,28 @@
+-- снимок адреса доставки (JSON) на момент оформления
+ALTER TABLE orders ADD COLUMN shipping_address TEXT AFTER discount_amount;
+
+CREATE TABLE IF NOT EXISTS addresses (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  user_id BIGINT NOT NULL,
+  recipient VARCHAR(255) NOT NULL,
+  phone VARCHAR(255),
+  line1 VARCHAR(255) NOT NULL,
+  line2 VARCHAR(255),
+  city VARCHAR(255) NOT NULL,
+  region VARCHAR(255),
+  postal_code VARCHAR(255) NOT NULL,
+  country CHAR(2) NOT NULL,
+  is_default BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_addresses_user (user_id)
+);
+
+CREATE TABLE IF NOT EXISTS shipments (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  order_id BIGINT NOT NULL UNIQUE,
+  carrier VARCHAR(64) NOT NULL,
+  tracking_number VARCHAR(128) NOT NULL,
+  shipped_at TIMESTAMP NOT NULL,
+  delivered_at TIMESTAMP NULL,
+  FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
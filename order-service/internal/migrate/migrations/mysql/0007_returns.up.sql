@@ -0,0 +1,25 @@
+ALTER TABLE payments ADD COLUMN refunded_amount BIGINT NOT NULL DEFAULT 0 AFTER currency;
+
+CREATE TABLE IF NOT EXISTS returns (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  order_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL,
+  status VARCHAR(20) NOT NULL,
+  refund_amount BIGINT NOT NULL,
+  quarantine BOOLEAN NOT NULL DEFAULT FALSE,
+  note VARCHAR(255),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_returns_order (order_id),
+  FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS return_items (
+  id BIGINT PRIMARY KEY AUTO_INCREMENT,
+  return_id BIGINT NOT NULL,
+  product_id BIGINT NOT NULL,
+  quantity INT NOT NULL,
+  reason VARCHAR(255) NOT NULL,
+  refund_amount BIGINT NOT NULL,
+  FOREIGN KEY (return_id) REFERENCES returns (id) ON DELETE CASCADE
+);
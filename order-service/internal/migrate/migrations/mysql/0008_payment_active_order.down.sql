@@ -0,0 +1,2 @@
+ALTER TABLE payments DROP INDEX uq_payments_active_order;
+ALTER TABLE payments DROP COLUMN active_order_id;
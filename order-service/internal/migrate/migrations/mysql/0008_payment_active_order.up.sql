@@ -0,0 +1,9 @@
+-- active_order_id = order_id у единственного не-failed платежа заказа, у остальных NULL:
+-- UNIQUE не даёт двум параллельным оплатам одного заказа создать два платежа.
+ALTER TABLE payments ADD COLUMN active_order_id BIGINT NULL;
+
+UPDATE payments p
+JOIN (SELECT MAX(id) AS id FROM payments WHERE status <> 'failed' GROUP BY order_id) a ON a.id = p.id
+SET p.active_order_id = p.order_id;
+
+ALTER TABLE payments ADD UNIQUE KEY uq_payments_active_order (active_order_id);
@@ -0,0 +1,9 @@
+-- Файлы асинхронных выгрузок, кусками: их читает любая реплика, а не только записавшая.
+CREATE TABLE IF NOT EXISTS export_files (
+  name VARCHAR(128) NOT NULL,
+  chunk INT NOT NULL,
+  data LONGBLOB NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (name, chunk),
+  KEY idx_export_files_created (created_at)
+);
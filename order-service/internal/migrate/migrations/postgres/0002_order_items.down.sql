@@ -0,0 +1,11 @@
+-- У заказа остаётся первая позиция
+ALTER TABLE orders ADD COLUMN product_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN quantity INT NOT NULL DEFAULT 0;
+UPDATE orders o SET product_id = i.product_id, quantity = i.quantity
+FROM order_items i
+WHERE i.order_id = o.id AND i.id = (SELECT MIN(x.id) FROM order_items x WHERE x.order_id = o.id);
+ALTER TABLE orders ALTER COLUMN product_id DROP DEFAULT;
+ALTER TABLE orders ALTER COLUMN quantity DROP DEFAULT;
+ALTER TABLE orders DROP COLUMN total_amount;
+ALTER TABLE orders DROP COLUMN currency;
+DROP TABLE IF EXISTS order_items;
@@ -0,0 +1,21 @@
+-- Заказ из нескольких позиций с ценами и валютой
+CREATE TABLE IF NOT EXISTS order_items (
+    id SERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT NOT NULL CHECK (unit_price >= 0),
+    line_total BIGINT NOT NULL,
+    UNIQUE (order_id, product_id)
+);
+
+-- Старые заказы — одна позиция без цены: валюта XXX (ISO 4217, «без валюты»), сумма 0
+INSERT INTO order_items (order_id, product_id, quantity, unit_price, line_total)
+SELECT id, product_id, quantity, 0, 0 FROM orders WHERE quantity > 0;
+
+ALTER TABLE orders ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'XXX';
+ALTER TABLE orders ALTER COLUMN currency DROP DEFAULT;
+ALTER TABLE orders ADD COLUMN total_amount BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ALTER COLUMN total_amount DROP DEFAULT;
+ALTER TABLE orders DROP COLUMN product_id;
+ALTER TABLE orders DROP COLUMN quantity;
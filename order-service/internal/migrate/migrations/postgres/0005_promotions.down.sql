@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS promotion_usages;
+DROP TABLE IF EXISTS promotions;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS promo_code;
@@ -0,0 +1,30 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promo_code VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_amount BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    type VARCHAR(20) NOT NULL,
+    value BIGINT NOT NULL,
+    currency CHAR(3),
+    min_order_amount BIGINT NOT NULL DEFAULT 0,
+    max_uses INT NOT NULL DEFAULT 0,
+    max_uses_per_user INT NOT NULL DEFAULT 0,
+    used_count INT NOT NULL DEFAULT 0,
+    product_ids VARCHAR(2048),
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Использование промокода: резервируется до создания заказа, удаляется при отмене
+CREATE TABLE IF NOT EXISTS promotion_usages (
+    id SERIAL PRIMARY KEY,
+    promotion_id BIGINT NOT NULL REFERENCES promotions (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    order_id BIGINT UNIQUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_usages_user ON promotion_usages (promotion_id, user_id);
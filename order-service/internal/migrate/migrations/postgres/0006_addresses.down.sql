@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS shipments;
+DROP TABLE IF EXISTS addresses;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_address;
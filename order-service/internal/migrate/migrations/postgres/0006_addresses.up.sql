@@ -0,0 +1,28 @@
+-- снимок адреса доставки (JSON) на момент оформления
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address TEXT;
+
+CREATE TABLE IF NOT EXISTS addresses (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    phone VARCHAR(255),
+    line1 VARCHAR(255) NOT NULL,
+    line2 VARCHAR(255),
+    city VARCHAR(255) NOT NULL,
+    region VARCHAR(255),
+    postal_code VARCHAR(255) NOT NULL,
+    country CHAR(2) NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_user ON addresses (user_id);
+
+CREATE TABLE IF NOT EXISTS shipments (
+    id SERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL UNIQUE REFERENCES orders (id) ON DELETE CASCADE,
+    carrier VARCHAR(64) NOT NULL,
+    tracking_number VARCHAR(128) NOT NULL,
+    shipped_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS returns;
+ALTER TABLE payments DROP COLUMN IF EXISTS refunded_amount;
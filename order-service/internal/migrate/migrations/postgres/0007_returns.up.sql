@@ -0,0 +1,24 @@
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS refunded_amount BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS returns (
+    id SERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    refund_amount BIGINT NOT NULL,
+    quarantine BOOLEAN NOT NULL DEFAULT FALSE,
+    note VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_returns_order ON returns (order_id);
+
+CREATE TABLE IF NOT EXISTS return_items (
+    id SERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL REFERENCES returns (id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    reason VARCHAR(255) NOT NULL,
+    refund_amount BIGINT NOT NULL
+);
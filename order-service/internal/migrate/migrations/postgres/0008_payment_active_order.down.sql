@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS uq_payments_active_order;
+ALTER TABLE payments DROP COLUMN IF EXISTS active_order_id;
@@ -0,0 +1,9 @@
+-- active_order_id = order_id у единственного не-failed платежа заказа, у остальных NULL:
+-- UNIQUE не даёт двум параллельным оплатам одного заказа создать два платежа.
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS active_order_id BIGINT;
+
+UPDATE payments p SET active_order_id = p.order_id
+FROM (SELECT MAX(id) AS id FROM payments WHERE status <> 'failed' GROUP BY order_id) a
+WHERE a.id = p.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_payments_active_order ON payments (active_order_id);
@@ -0,0 +1,10 @@
+-- Файлы асинхронных выгрузок, кусками: их читает любая реплика, а не только записавшая.
+CREATE TABLE IF NOT EXISTS export_files (
+  name VARCHAR(128) NOT NULL,
+  chunk INT NOT NULL,
+  data BYTEA NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (name, chunk)
+);
+
+CREATE INDEX IF NOT EXISTS idx_export_files_created ON export_files (created_at);
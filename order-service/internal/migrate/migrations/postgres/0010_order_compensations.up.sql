@@ -0,0 +1,11 @@
+-- Компенсации заказов, отменённых фоном: возврат промокода и order.cancelled.
+-- Строка пишется в одной транзакции с отменой и удаляется, когда компенсация
+-- выполнена; до тех пор её повторяет OrderExpiryService.
+CREATE TABLE IF NOT EXISTS order_compensations (
+    order_id BIGINT PRIMARY KEY REFERENCES orders (id) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_compensations_next ON order_compensations (next_attempt_at);
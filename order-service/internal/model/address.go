@@ -0,0 +1,26 @@
+// internal/model/address.go
+package model
+
+import "time"
+
+// ShippingAddress — адрес доставки. На заказ копируется снимок, поэтому
+// правка или удаление сохранённого адреса не меняет уже оформленные заказы.
+type ShippingAddress struct {
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone,omitempty"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"` // ISO 3166-1 alpha-2
+}
+
+// Address — сохранённый адрес пользователя.
+type Address struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	ShippingAddress
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+}
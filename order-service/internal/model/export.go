@@ -0,0 +1,32 @@
+// internal/model/export.go
+package model
+
+import "time"
+
+// OrderFilter — фильтр выгрузки заказов. Нулевые поля не ограничивают выборку.
+type OrderFilter struct {
+	From   time.Time `json:"from,omitzero"` // created_at >= From
+	To     time.Time `json:"to,omitzero"`   // created_at < To
+	Status string    `json:"status,omitempty"`
+}
+
+const (
+	ExportJobPending = "pending"
+	ExportJobRunning = "running"
+	ExportJobDone    = "done"
+	ExportJobFailed  = "failed"
+)
+
+// ExportJob — асинхронная выгрузка заказов в файл.
+type ExportJob struct {
+	ID          string      `json:"id"`
+	Status      string      `json:"status"`
+	Format      string      `json:"format"`
+	Filter      OrderFilter `json:"filter"`
+	RequestedBy int64       `json:"requested_by"`
+	Rows        int64       `json:"rows"`
+	FileName    string      `json:"file_name,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	FinishedAt  *time.Time  `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,10 @@
+// internal/model/idempotency.go
+package model
+
+// IdempotencyRecord — сохранённый ответ на запрос с заголовком Idempotency-Key.
+type IdempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
@@ -3,11 +3,86 @@ package model
 
 import "time"
 
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusCancelled = "cancelled"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusReturned  = "returned" // все позиции возвращены и деньги за них вернули
+)
+
+// orderTransitions — допустимые переходы статусов заказа.
+// Отправленный заказ уже не отменить — только вернуть.
+var orderTransitions = map[string][]string{
+	OrderStatusPending:   {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:      {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:   {OrderStatusDelivered},
+	OrderStatusDelivered: {OrderStatusReturned},
+}
+
+// CanTransition reports whether an order may move from one status to another.
+func CanTransition(from, to string) bool {
+	for _, s := range orderTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// События жизненного цикла заказа (для вебхуков и подписчиков).
+const (
+	OrderEventCreated   = "order.created"
+	OrderEventPaid      = "order.paid"
+	OrderEventCancelled = "order.cancelled"
+	OrderEventShipped   = "order.shipped"
+	OrderEventDelivered = "order.delivered"
+	OrderEventReturned  = "order.returned"
+)
+
+// OrderEventTypes — все события, на которые можно подписаться.
+var OrderEventTypes = []string{
+	OrderEventCreated,
+	OrderEventPaid,
+	OrderEventCancelled,
+	OrderEventShipped,
+	OrderEventDelivered,
+	OrderEventReturned,
+}
+
 type Order struct {
-	ID        int64     `pg:"id,pk"`
-	UserID    int64     `pg:"user_id,notnull"`
-	ProductID int64     `pg:"product_id,notnull"`
-	Quantity  int       `pg:"quantity,notnull"`
-	Status    string    `pg:"status,default:'pending'"`
-	CreatedAt time.Time `pg:"created_at,default:now()"`
+	ID           int64            `pg:"id,pk" json:"id"`
+	UserID       int64            `pg:"user_id,notnull" json:"user_id"`
+	Status       string           `pg:"status,default:'pending'" json:"status"`
+	StatusReason string           `pg:"status_reason" json:"status_reason,omitempty"`
+	Currency     string           `pg:"currency,notnull" json:"currency"`
+	TotalAmount  int64            `pg:"total_amount,notnull" json:"total_amount"` // в минимальных единицах валюты (копейки, центы), уже со скидкой
+	PromoCode    string           `pg:"promo_code" json:"promo_code,omitempty"`
+	Discount     int64            `pg:"discount_amount" json:"discount_amount"`
+	Shipping     *ShippingAddress `pg:"shipping_address" json:"shipping_address,omitempty"`
+	Items        []OrderItem      `pg:"rel:has-many" json:"items"`
+	CreatedAt    time.Time        `pg:"created_at,default:now()" json:"created_at"`
+
+	// PromoUsageID — зарезервированное использование промокода; привязывается к заказу при создании
+	PromoUsageID int64 `pg:"-" json:"-"`
+}
+
+type OrderItem struct {
+	ID        int64 `pg:"id,pk" json:"id"`
+	OrderID   int64 `pg:"order_id,notnull" json:"order_id"`
+	ProductID int64 `pg:"product_id,notnull" json:"product_id"`
+	Quantity  int   `pg:"quantity,notnull" json:"quantity"`
+	UnitPrice int64 `pg:"unit_price,notnull" json:"unit_price"` // в минимальных единицах валюты
+	LineTotal int64 `pg:"line_total,notnull" json:"line_total"`
+}
+
+// OrderStatusChange — запись истории статусов заказа.
+type OrderStatusChange struct {
+	ID         int64     `json:"id"`
+	OrderID    int64     `json:"order_id"`
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -0,0 +1,26 @@
+// internal/model/payment.go
+package model
+
+import "time"
+
+const (
+	PaymentStatusAuthorized     = "authorized"
+	PaymentStatusCapturePending = "capture_pending"
+	PaymentStatusCaptured       = "captured"
+	PaymentStatusFailed         = "failed"
+	PaymentStatusRefunded       = "refunded" // возвращена вся сумма; частичный возврат оставляет captured
+)
+
+type Payment struct {
+	ID                int64     `json:"id"`
+	OrderID           int64     `json:"order_id"`
+	Provider          string    `json:"provider"`
+	ProviderPaymentID string    `json:"provider_payment_id"`
+	Amount            int64     `json:"amount"`
+	Currency          string    `json:"currency"`
+	RefundedAmount    int64     `json:"refunded_amount"`
+	Status            string    `json:"status"`
+	FailureReason     string    `json:"failure_reason,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
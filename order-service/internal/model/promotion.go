@@ -0,0 +1,49 @@
+// internal/model/promotion.go
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Типы скидок.
+const (
+	PromotionPercentage = "percentage" // Value — процент от суммы подходящих позиций (1–100)
+	PromotionFixed      = "fixed"      // Value — сумма в минимальных единицах Currency
+)
+
+type Promotion struct {
+	ID             int64      `json:"id"`
+	Code           string     `json:"code"`
+	Type           string     `json:"type"`
+	Value          int64      `json:"value"`
+	Currency       string     `json:"currency,omitempty"` // обязательна для fixed и min_order_amount
+	MinOrderAmount int64      `json:"min_order_amount"`
+	MaxUses        int        `json:"max_uses"`          // 0 — без ограничения
+	MaxUsesPerUser int        `json:"max_uses_per_user"` // 0 — без ограничения
+	UsedCount      int        `json:"used_count"`
+	ProductIDs     []int64    `json:"product_ids,omitempty"` // пусто — скидка на все товары
+	StartsAt       *time.Time `json:"starts_at,omitempty"`
+	EndsAt         *time.Time `json:"ends_at,omitempty"`
+	Active         bool       `json:"active"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// AppliesTo reports whether the discount covers the product.
+func (p *Promotion) AppliesTo(productID int64) bool {
+	return len(p.ProductIDs) == 0 || slices.Contains(p.ProductIDs, productID)
+}
+
+// ActiveAt reports whether the promotion is enabled and inside its validity window.
+func (p *Promotion) ActiveAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	return true
+}
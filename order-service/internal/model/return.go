@@ -0,0 +1,38 @@
+// internal/model/return.go
+package model
+
+import "time"
+
+// Статусы возврата (RMA): requested → approved → received → refunded, или rejected.
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunded  = "refunded"
+)
+
+// ReturnHistoryPrefix отличает шаги возврата от статусов заказа в истории заказа.
+const ReturnHistoryPrefix = "return_"
+
+type Return struct {
+	ID           int64        `json:"id"`
+	OrderID      int64        `json:"order_id"`
+	UserID       int64        `json:"user_id"`
+	Status       string       `json:"status"`
+	RefundAmount int64        `json:"refund_amount"` // в минимальных единицах валюты заказа
+	Quarantine   bool         `json:"quarantine"`
+	Note         string       `json:"note,omitempty"` // комментарий оператора
+	Items        []ReturnItem `json:"items"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+type ReturnItem struct {
+	ID           int64  `json:"id"`
+	ReturnID     int64  `json:"return_id"`
+	ProductID    int64  `json:"product_id"`
+	Quantity     int    `json:"quantity"`
+	Reason       string `json:"reason"`
+	RefundAmount int64  `json:"refund_amount"`
+}
@@ -0,0 +1,13 @@
+// internal/model/shipment.go
+package model
+
+import "time"
+
+type Shipment struct {
+	ID             int64      `json:"id"`
+	OrderID        int64      `json:"order_id"`
+	Carrier        string     `json:"carrier"`
+	TrackingNumber string     `json:"tracking_number"`
+	ShippedAt      time.Time  `json:"shipped_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
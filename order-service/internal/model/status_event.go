@@ -0,0 +1,13 @@
+// internal/model/status_event.go
+package model
+
+import "time"
+
+// OrderStatusEvent — изменение статуса заказа для live-стрима (SSE).
+type OrderStatusEvent struct {
+	ID        string    `json:"-"` // ID записи в Redis Stream, уходит клиенту как SSE id
+	Type      string    `json:"type"`
+	OrderID   int64     `json:"order_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
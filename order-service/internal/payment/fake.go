@@ -0,0 +1,153 @@
+// internal/payment/fake.go
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"order-service/internal/utils"
+
+	"contracts/events"
+)
+
+// Тестовые токены способа оплаты для FakeProvider.
+const (
+	FakeMethodDeclined     = "tok_declined"      // Authorize отклоняется
+	FakeMethodCaptureFails = "tok_capture_fails" // Capture завершается уведомлением payment.failed
+)
+
+// FakeProvider — in-process провайдер для тестов и локального запуска.
+// Capture и Refund завершаются асинхронно: через delay провайдер отправляет
+// подписанное уведомление на webhookURL, как это делает настоящий шлюз.
+type FakeProvider struct {
+	webhookURL string
+	secret     string
+	delay      time.Duration
+	client     *http.Client
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+}
+
+type fakePayment struct {
+	amount   int64
+	refunded int64
+	method   string
+	status   string
+}
+
+func NewFakeProvider(webhookURL, secret string, delay time.Duration) *FakeProvider {
+	return &FakeProvider{
+		webhookURL: webhookURL,
+		secret:     secret,
+		delay:      delay,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		payments:   make(map[string]*fakePayment),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error) {
+	id := "fake_" + events.NewEventID()
+	if req.PaymentMethod == FakeMethodDeclined {
+		return &Result{ProviderPaymentID: id, Status: StatusFailed, FailureReason: "card_declined"}, nil
+	}
+
+	p.mu.Lock()
+	p.payments[id] = &fakePayment{amount: req.Amount, method: req.PaymentMethod, status: StatusAuthorized}
+	p.mu.Unlock()
+
+	return &Result{ProviderPaymentID: id, Status: StatusAuthorized}, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, providerPaymentID string, amount int64) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fp, ok := p.payments[providerPaymentID]
+	if !ok {
+		return nil, ErrUnknownPayment
+	}
+	if fp.status != StatusAuthorized {
+		return nil, fmt.Errorf("fake provider: cannot capture payment in status %s", fp.status)
+	}
+	fp.status = StatusCapturePending
+
+	n := Notification{EventID: events.NewEventID(), Type: NotificationCaptured, ProviderPaymentID: providerPaymentID, Amount: amount}
+	if fp.method == FakeMethodCaptureFails {
+		n.Type = NotificationFailed
+		n.FailureReason = "insufficient_funds"
+	}
+	go p.settle(providerPaymentID, n)
+
+	return &Result{ProviderPaymentID: providerPaymentID, Status: StatusCapturePending}, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, providerPaymentID string, amount int64) (*Result, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fp, ok := p.payments[providerPaymentID]
+	if !ok {
+		return nil, ErrUnknownPayment
+	}
+	if fp.status != StatusCaptured {
+		return nil, fmt.Errorf("fake provider: cannot refund payment in status %s", fp.status)
+	}
+	if fp.refunded+amount > fp.amount {
+		return nil, fmt.Errorf("fake provider: refund exceeds the captured amount")
+	}
+	fp.refunded += amount
+	if fp.refunded == fp.amount {
+		fp.status = StatusRefunded
+	}
+
+	go p.settle(providerPaymentID, Notification{
+		EventID:           events.NewEventID(),
+		Type:              NotificationRefunded,
+		ProviderPaymentID: providerPaymentID,
+		Amount:            amount,
+	})
+
+	return &Result{ProviderPaymentID: providerPaymentID, Status: fp.status}, nil
+}
+
+// settle finishes the operation after the delay and notifies the webhook.
+func (p *FakeProvider) settle(providerPaymentID string, n Notification) {
+	time.Sleep(p.delay)
+
+	p.mu.Lock()
+	if fp, ok := p.payments[providerPaymentID]; ok && fp.status == StatusCapturePending {
+		if n.Type == NotificationCaptured {
+			fp.status = StatusCaptured
+		} else {
+			fp.status = StatusFailed
+		}
+	}
+	p.mu.Unlock()
+
+	body, _ := json.Marshal(n)
+	req, err := http.NewRequest(http.MethodPost, p.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("fake payment provider: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, utils.SignPayload(p.secret, time.Now(), body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		log.Printf("fake payment provider: notification %s for %s failed: %v", n.Type, providerPaymentID, err)
+		return
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,60 @@
+// internal/payment/provider.go
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// Статусы, которые возвращает провайдер.
+const (
+	StatusAuthorized     = "authorized"
+	StatusCapturePending = "capture_pending"
+	StatusCaptured       = "captured"
+	StatusFailed         = "failed"
+	StatusRefunded       = "refunded"
+)
+
+// Типы асинхронных уведомлений провайдера.
+const (
+	NotificationCaptured = "payment.captured"
+	NotificationFailed   = "payment.failed"
+	NotificationRefunded = "payment.refunded"
+)
+
+// SignatureHeader carries utils.SignPayload of the notification body.
+const SignatureHeader = "X-Payment-Signature"
+
+var ErrUnknownPayment = errors.New("unknown payment")
+
+type AuthorizeRequest struct {
+	OrderID        int64
+	Amount         int64
+	Currency       string
+	PaymentMethod  string // токен карты/кошелька от фронтенда
+	IdempotencyKey string
+}
+
+type Result struct {
+	ProviderPaymentID string
+	Status            string
+	FailureReason     string
+}
+
+// Provider — платёжный шлюз. Capture может завершаться асинхронно:
+// итог приходит уведомлением (Notification) на payment-webhook.
+type Provider interface {
+	Name() string
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Result, error)
+	Capture(ctx context.Context, providerPaymentID string, amount int64) (*Result, error)
+	Refund(ctx context.Context, providerPaymentID string, amount int64) (*Result, error)
+}
+
+// Notification — тело асинхронного уведомления провайдера.
+type Notification struct {
+	EventID           string `json:"event_id"`
+	Type              string `json:"type"`
+	ProviderPaymentID string `json:"provider_payment_id"`
+	Amount            int64  `json:"amount"`
+	FailureReason     string `json:"failure_reason,omitempty"`
+}
@@ -0,0 +1,157 @@
+// internal/repository/address.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"order-service/internal/model"
+)
+
+type AddressRepository struct {
+	db *DB
+}
+
+func NewAddressRepository(db *DB) *AddressRepository {
+	return &AddressRepository{db: db}
+}
+
+// Create saves the address. The user's first address becomes the default one.
+func (r *AddressRepository) Create(ctx context.Context, a *model.Address) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM addresses WHERE user_id = ?`, a.UserID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		a.IsDefault = true
+	}
+
+	a.CreatedAt = time.Now()
+	a.ID, err = tx.insert(ctx, `
+		INSERT INTO addresses (user_id, recipient, phone, line1, line2, city, region, postal_code, country, is_default, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		a.UserID, a.Recipient, nullString(a.Phone), a.Line1, nullString(a.Line2), a.City,
+		nullString(a.Region), a.PostalCode, a.Country, a.IsDefault, a.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := resetDefault(ctx, tx, a); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Get returns the address if it belongs to userID, otherwise ErrNotFound.
+func (r *AddressRepository) Get(ctx context.Context, userID, id int64) (*model.Address, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+addressColumns+` FROM addresses WHERE id = ? AND user_id = ?`, id, userID)
+	return scanAddress(row)
+}
+
+// GetDefault returns the user's default address or ErrNotFound.
+func (r *AddressRepository) GetDefault(ctx context.Context, userID int64) (*model.Address, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+addressColumns+` FROM addresses WHERE user_id = ? AND is_default = TRUE`, userID)
+	return scanAddress(row)
+}
+
+func (r *AddressRepository) ListByUser(ctx context.Context, userID int64) ([]model.Address, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+addressColumns+` FROM addresses WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var addresses []model.Address
+	for rows.Next() {
+		a, err := scanAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *a)
+	}
+	return addresses, rows.Err()
+}
+
+func (r *AddressRepository) Update(ctx context.Context, a *model.Address) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE addresses
+		SET recipient = ?, phone = ?, line1 = ?, line2 = ?, city = ?, region = ?, postal_code = ?, country = ?, is_default = ?
+		WHERE id = ? AND user_id = ?`,
+		a.Recipient, nullString(a.Phone), a.Line1, nullString(a.Line2), a.City,
+		nullString(a.Region), a.PostalCode, a.Country, a.IsDefault, a.ID, a.UserID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// MySQL не считает строку изменённой, если значения совпали
+		var exists bool
+		err := tx.QueryRowContext(ctx, `SELECT TRUE FROM addresses WHERE id = ? AND user_id = ?`, a.ID, a.UserID).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := resetDefault(ctx, tx, a); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *AddressRepository) Delete(ctx context.Context, userID, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM addresses WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// resetDefault keeps a single default address per user.
+func resetDefault(ctx context.Context, tx *Tx, a *model.Address) error {
+	if !a.IsDefault {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+		UPDATE addresses SET is_default = FALSE WHERE user_id = ? AND id <> ?`, a.UserID, a.ID)
+	return err
+}
+
+const addressColumns = `id, user_id, recipient, COALESCE(phone, ''), line1, COALESCE(line2, ''), city,
+		COALESCE(region, ''), postal_code, country, is_default, created_at`
+
+func scanAddress(row rowScanner) (*model.Address, error) {
+	a := &model.Address{}
+	err := row.Scan(&a.ID, &a.UserID, &a.Recipient, &a.Phone, &a.Line1, &a.Line2, &a.City,
+		&a.Region, &a.PostalCode, &a.Country, &a.IsDefault, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return a, nil
+}
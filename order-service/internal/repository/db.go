@@ -0,0 +1,185 @@
+// internal/repository/db.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Dialect — SQL-диалект базы заказов.
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+)
+
+const (
+	// mysqlDuplicateEntry — код ошибки MySQL/MariaDB при нарушении UNIQUE.
+	mysqlDuplicateEntry = 1062
+	// pgUniqueViolation — SQLSTATE PostgreSQL при нарушении UNIQUE.
+	pgUniqueViolation = "23505"
+)
+
+// DB wraps *sql.DB for both supported dialects. Repositories write queries with
+// "?" placeholders; for PostgreSQL they are rewritten to $1, $2, ...
+type DB struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// Open connects to the orders database; dialect selects the driver.
+func Open(dialect Dialect, dsn string) (*DB, error) {
+	driver := string(dialect)
+	if dialect == DialectPostgres {
+		driver = "pgx"
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return NewDB(db, dialect)
+}
+
+func NewDB(db *sql.DB, dialect Dialect) (*DB, error) {
+	if dialect != DialectMySQL && dialect != DialectPostgres {
+		return nil, fmt.Errorf("unsupported database dialect %q", dialect)
+	}
+	return &DB{db: db, dialect: dialect}, nil
+}
+
+func (d *DB) Dialect() Dialect {
+	return d.dialect
+}
+
+// SQL returns the underlying *sql.DB, e.g. for schema migrations.
+func (d *DB) SQL() *sql.DB {
+	return d.db
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.db.ExecContext(ctx, rebind(d.dialect, query), args...)
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.db.QueryContext(ctx, rebind(d.dialect, query), args...)
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.db.QueryRowContext(ctx, rebind(d.dialect, query), args...)
+}
+
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{tx: tx, dialect: d.dialect}, nil
+}
+
+// insert runs an INSERT into a table with an auto-generated id and returns the id.
+func (d *DB) insert(ctx context.Context, query string, args ...any) (int64, error) {
+	return insertID(ctx, d, d.dialect, query, args)
+}
+
+// Tx — транзакция DB с тем же переводом плейсхолдеров.
+type Tx struct {
+	tx      *sql.Tx
+	dialect Dialect
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return t.tx.ExecContext(ctx, rebind(t.dialect, query), args...)
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return t.tx.QueryContext(ctx, rebind(t.dialect, query), args...)
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return t.tx.QueryRowContext(ctx, rebind(t.dialect, query), args...)
+}
+
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+func (t *Tx) insert(ctx context.Context, query string, args ...any) (int64, error) {
+	return insertID(ctx, t, t.dialect, query, args)
+}
+
+type execQueryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// insertID uses LastInsertId on MySQL; PostgreSQL has no such thing, so the id
+// is read back with RETURNING.
+func insertID(ctx context.Context, q execQueryer, dialect Dialect, query string, args []any) (int64, error) {
+	var id int64
+	if dialect == DialectPostgres {
+		err := q.QueryRowContext(ctx, strings.TrimRight(query, " \t\n")+" RETURNING id", args...).Scan(&id)
+		return id, err
+	}
+
+	result, err := q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// rebind rewrites "?" placeholders to PostgreSQL's $N, leaving quoted literals alone.
+func rebind(dialect Dialect, query string) string {
+	if dialect != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	inQuote := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inQuote = !inQuote
+		case c == '?' && !inQuote:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// duplicateAsExists maps a UNIQUE violation of either dialect to ErrAlreadyExists.
+func duplicateAsExists(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry {
+		return ErrAlreadyExists
+	}
+	// pgx и lib/pq отдают код ошибки через SQLState()
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) && pgErr.SQLState() == pgUniqueViolation {
+		return ErrAlreadyExists
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+// internal/repository/errors.go
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNotFound       = errors.New("not found")
+	ErrStatusConflict = errors.New("status was changed concurrently")
+	ErrAlreadyExists  = errors.New("already exists")
+)
+
+// BatchError — ошибка пакетной операции с номером элемента, на котором она случилась.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,180 @@
+// internal/repository/export.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"order-service/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// exportFetchSize — строк на один FETCH из курсора выгрузки (PostgreSQL).
+const exportFetchSize = 1000
+
+// StreamOrders calls fn for every order matching the filter, oldest first, with
+// its items, so memory does not grow with the result. On MySQL the driver reads
+// rows from the connection as they are consumed; on PostgreSQL the rows come from
+// a named cursor, exportFetchSize at a time. The connection is held until the end.
+func (r *OrderRepository) StreamOrders(ctx context.Context, filter model.OrderFilter, fn func(*model.Order) error) error {
+	query := `
+		SELECT o.id, o.user_id, o.status, COALESCE(o.status_reason, ''), o.currency, o.total_amount,
+			COALESCE(o.promo_code, ''), o.discount_amount, o.created_at,
+			i.id, i.product_id, i.quantity, i.unit_price, i.line_total
+		FROM orders o
+		LEFT JOIN order_items i ON i.order_id = o.id
+		WHERE 1 = 1`
+	var args []any
+	if !filter.From.IsZero() {
+		query += " AND o.created_at >= ?"
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		query += " AND o.created_at < ?"
+		args = append(args, filter.To)
+	}
+	if filter.Status != "" {
+		query += " AND o.status = ?"
+		args = append(args, filter.Status)
+	}
+	query += " ORDER BY o.id, i.id"
+
+	g := &orderGrouper{fn: fn}
+	if r.db.Dialect() == DialectPostgres {
+		if err := r.streamCursor(ctx, query, args, g); err != nil {
+			return err
+		}
+		return g.flush()
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if _, err := g.scan(rows); err != nil {
+		return err
+	}
+	return g.flush()
+}
+
+// streamCursor reads query through a named cursor, exportFetchSize rows per FETCH,
+// so the server produces rows only as fast as the export consumes them.
+func (r *OrderRepository) streamCursor(ctx context.Context, query string, args []any, g *orderGrouper) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DECLARE export_orders NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return err
+	}
+	for {
+		rows, err := tx.QueryContext(ctx, "FETCH "+strconv.Itoa(exportFetchSize)+" FROM export_orders")
+		if err != nil {
+			return err
+		}
+		n, err := g.scan(rows)
+		if err != nil {
+			return err
+		}
+		if n < exportFetchSize {
+			break
+		}
+	}
+	return tx.Commit()
+}
+
+// orderGrouper собирает заказ из строк JOIN'а: они приходят сгруппированными по
+// заказу, поэтому заказ готов, когда сменился id. Заказ может продолжаться в
+// следующей пачке FETCH.
+type orderGrouper struct {
+	fn      func(*model.Order) error
+	current *model.Order
+}
+
+// scan consumes and closes rows and returns the number of rows read.
+func (g *orderGrouper) scan(rows *sql.Rows) (int, error) {
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		n++
+		var o model.Order
+		var itemID, productID, unitPrice, lineTotal sql.NullInt64
+		var quantity sql.NullInt32
+		if err := rows.Scan(&o.ID, &o.UserID, &o.Status, &o.StatusReason, &o.Currency, &o.TotalAmount,
+			&o.PromoCode, &o.Discount, &o.CreatedAt,
+			&itemID, &productID, &quantity, &unitPrice, &lineTotal); err != nil {
+			return n, err
+		}
+
+		if g.current == nil || g.current.ID != o.ID {
+			if err := g.flush(); err != nil {
+				return n, err
+			}
+			g.current = &o
+		}
+		if itemID.Valid {
+			g.current.Items = append(g.current.Items, model.OrderItem{
+				ID:        itemID.Int64,
+				OrderID:   g.current.ID,
+				ProductID: productID.Int64,
+				Quantity:  int(quantity.Int32),
+				UnitPrice: unitPrice.Int64,
+				LineTotal: lineTotal.Int64,
+			})
+		}
+	}
+	return n, rows.Err()
+}
+
+// flush passes the collected order to fn.
+func (g *orderGrouper) flush() error {
+	if g.current == nil {
+		return nil
+	}
+	order := g.current
+	g.current = nil
+	return g.fn(order)
+}
+
+const exportJobTTL = 7 * 24 * time.Hour
+
+// ExportJobRepository хранит метаданные асинхронных выгрузок в Redis.
+type ExportJobRepository struct {
+	redis *redis.Client
+}
+
+func NewExportJobRepository(redis *redis.Client) *ExportJobRepository {
+	return &ExportJobRepository{redis: redis}
+}
+
+func (r *ExportJobRepository) Save(ctx context.Context, job *model.ExportJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return r.redis.Set(ctx, "export-job:"+job.ID, data, exportJobTTL).Err()
+}
+
+func (r *ExportJobRepository) Get(ctx context.Context, id string) (*model.ExportJob, error) {
+	data, err := r.redis.Get(ctx, "export-job:"+id).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	job := &model.ExportJob{}
+	if err := json.Unmarshal(data, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,146 @@
+// internal/repository/export_file.go
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+// exportChunkSize — размер куска файла выгрузки в строке export_files.
+const exportChunkSize = 1 << 20
+
+// ExportFileStore keeps export files in the orders database, so every replica can
+// serve a file written by another one. It implements export.Store: a file is split
+// into chunks of exportChunkSize and neither side holds more than one in memory.
+type ExportFileStore struct {
+	db *DB
+}
+
+func NewExportFileStore(db *DB) *ExportFileStore {
+	return &ExportFileStore{db: db}
+}
+
+// Create starts a new file; its chunks become visible once the writer is closed.
+// Files older than the job metadata in Redis are deleted on the way.
+func (s *ExportFileStore) Create(ctx context.Context, name string) (io.WriteCloser, error) {
+	name = filepath.Base(name)
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM export_files WHERE created_at < ?`,
+		time.Now().Add(-exportJobTTL)); err != nil {
+		return nil, err
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM export_files WHERE name = ?`, name); err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	return &exportFileWriter{ctx: ctx, tx: tx, name: name}, nil
+}
+
+// Open returns a reader that loads the file chunk by chunk.
+func (s *ExportFileStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	r := &exportFileReader{ctx: ctx, db: s.db, name: filepath.Base(name)}
+	// Первый кусок сразу: отсутствующий файл — ошибка Open, а не пустой ответ
+	if err := r.next(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// exportFileWriter пишет куски в одной транзакции: незаконченный файл не виден.
+type exportFileWriter struct {
+	ctx   context.Context
+	tx    *Tx
+	name  string
+	chunk int
+	buf   bytes.Buffer
+	err   error
+}
+
+func (w *exportFileWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		take := min(len(p), exportChunkSize-w.buf.Len())
+		w.buf.Write(p[:take])
+		p = p[take:]
+		if w.buf.Len() == exportChunkSize {
+			if w.err = w.flush(); w.err != nil {
+				return 0, w.err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (w *exportFileWriter) flush() error {
+	if _, err := w.tx.ExecContext(w.ctx, `
+		INSERT INTO export_files (name, chunk, data, created_at) VALUES (?, ?, ?, ?)`,
+		w.name, w.chunk, w.buf.Bytes(), time.Now()); err != nil {
+		return err
+	}
+	w.chunk++
+	w.buf.Reset()
+	return nil
+}
+
+func (w *exportFileWriter) Close() error {
+	if w.err == nil && (w.buf.Len() > 0 || w.chunk == 0) {
+		w.err = w.flush()
+	}
+	if w.err != nil {
+		_ = w.tx.Rollback()
+		return w.err
+	}
+	w.err = errors.New("export file is closed")
+	return w.tx.Commit()
+}
+
+type exportFileReader struct {
+	ctx   context.Context
+	db    *DB
+	name  string
+	chunk int
+	buf   []byte
+}
+
+func (r *exportFileReader) next() error {
+	err := r.db.QueryRowContext(r.ctx, `SELECT data FROM export_files WHERE name = ? AND chunk = ?`,
+		r.name, r.chunk).Scan(&r.buf)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if r.chunk == 0 {
+				return ErrNotFound
+			}
+			return io.EOF
+		}
+		return err
+	}
+	r.chunk++
+	return nil
+}
+
+func (r *exportFileReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *exportFileReader) Close() error {
+	r.buf = nil
+	return nil
+}
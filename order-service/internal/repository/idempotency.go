@@ -0,0 +1,94 @@
+// internal/repository/idempotency.go
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"order-service/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// unlockScript удаляет блокировку, только если она всё ещё принадлежит нам.
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// extendScript продлевает блокировку, только если она всё ещё принадлежит нам.
+var extendScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+type IdempotencyRepository struct {
+	redis *redis.Client
+}
+
+func NewIdempotencyRepository(redis *redis.Client) *IdempotencyRepository {
+	return &IdempotencyRepository{redis: redis}
+}
+
+// Get returns the stored record for key, or nil if there is none.
+func (r *IdempotencyRepository) Get(ctx context.Context, key string) (*model.IdempotencyRecord, error) {
+	data, err := r.redis.Get(ctx, "idempotency:"+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	record := &model.IdempotencyRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *IdempotencyRepository) Save(ctx context.Context, key string, record *model.IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return r.redis.Set(ctx, "idempotency:"+key, data, ttl).Err()
+}
+
+// Lock tries to take the per-key lock. The returned token must be passed to Unlock.
+func (r *IdempotencyRepository) Lock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	token := newLockToken()
+	ok, err := r.redis.SetNX(ctx, "idempotency-lock:"+key, token, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return token, ok, nil
+}
+
+func (r *IdempotencyRepository) Unlock(ctx context.Context, key, token string) error {
+	return unlockScript.Run(ctx, r.redis, []string{"idempotency-lock:" + key}, token).Err()
+}
+
+// Extend resets the TTL of a lock still held with token. It returns false if
+// the lock has expired or was taken by someone else.
+func (r *IdempotencyRepository) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := extendScript.Run(ctx, r.redis, []string{"idempotency-lock:" + key}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func newLockToken() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
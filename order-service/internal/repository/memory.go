@@ -0,0 +1,263 @@
+// internal/repository/memory.go
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"order-service/internal/model"
+)
+
+// MemoryOrderStore — OrderStore в памяти процесса для юнит-тестов.
+// Ведёт себя так же, как SQL-реализация, включая ошибки.
+type MemoryOrderStore struct {
+	mu        sync.Mutex
+	orders    map[int64]*model.Order
+	history   map[int64][]model.OrderStatusChange
+	shipments map[int64]*model.Shipment
+	// compensations — время следующей попытки компенсации по заказу
+	compensations map[int64]time.Time
+	lastID        struct{ order, item, history, shipment int64 }
+}
+
+func NewMemoryOrderStore() *MemoryOrderStore {
+	return &MemoryOrderStore{
+		orders:        make(map[int64]*model.Order),
+		history:       make(map[int64][]model.OrderStatusChange),
+		shipments:     make(map[int64]*model.Shipment),
+		compensations: make(map[int64]time.Time),
+	}
+}
+
+func (s *MemoryOrderStore) Create(ctx context.Context, order *model.Order) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.insert(order)
+	return nil
+}
+
+func (s *MemoryOrderStore) CreateBatch(ctx context.Context, orders []*model.Order) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, order := range orders {
+		s.insert(order)
+	}
+	return nil
+}
+
+func (s *MemoryOrderStore) insert(order *model.Order) {
+	order.CreatedAt = time.Now()
+	s.lastID.order++
+	order.ID = s.lastID.order
+	for i := range order.Items {
+		s.lastID.item++
+		order.Items[i].ID = s.lastID.item
+		order.Items[i].OrderID = order.ID
+	}
+
+	s.orders[order.ID] = copyOrder(order)
+	s.addHistory(order.ID, "", order.Status, "")
+}
+
+func (s *MemoryOrderStore) GetByID(ctx context.Context, id int64) (*model.Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyOrder(order), nil
+}
+
+func (s *MemoryOrderStore) UpdateStatus(ctx context.Context, id int64, from, to, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.updateStatus(id, from, to, reason)
+}
+
+func (s *MemoryOrderStore) updateStatus(id int64, from, to, reason string) error {
+	order, ok := s.orders[id]
+	if !ok || order.Status != from {
+		return ErrStatusConflict
+	}
+	order.Status = to
+	order.StatusReason = reason
+	s.addHistory(id, from, to, reason)
+	return nil
+}
+
+func (s *MemoryOrderStore) ExpirePending(ctx context.Context, before time.Time, limit int, reason string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []int64
+	for id, order := range s.orders {
+		if order.Status == model.OrderStatusPending && order.CreatedAt.Before(before) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	for _, id := range ids {
+		if err := s.updateStatus(id, model.OrderStatusPending, model.OrderStatusCancelled, reason); err != nil {
+			return nil, err
+		}
+		s.compensations[id] = time.Now()
+	}
+	return ids, nil
+}
+
+func (s *MemoryOrderStore) ClaimCompensations(ctx context.Context, limit int, retryAfter time.Duration) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var ids []int64
+	for id, next := range s.compensations {
+		if !next.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	for _, id := range ids {
+		s.compensations[id] = now.Add(retryAfter)
+	}
+	return ids, nil
+}
+
+func (s *MemoryOrderStore) CompleteCompensation(ctx context.Context, orderID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.compensations, orderID)
+	return nil
+}
+
+func (s *MemoryOrderStore) History(ctx context.Context, orderID int64) ([]model.OrderStatusChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.history[orderID]), nil
+}
+
+func (s *MemoryOrderStore) addHistory(orderID int64, from, to, reason string) {
+	s.lastID.history++
+	s.history[orderID] = append(s.history[orderID], model.OrderStatusChange{
+		ID:         s.lastID.history,
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func (s *MemoryOrderStore) Ship(ctx context.Context, orderID int64, shipment *model.Shipment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.updateStatus(orderID, model.OrderStatusPaid, model.OrderStatusShipped, ""); err != nil {
+		return err
+	}
+	s.lastID.shipment++
+	shipment.ID = s.lastID.shipment
+	shipment.OrderID = orderID
+	shipment.ShippedAt = time.Now()
+	shipment.DeliveredAt = nil
+
+	stored := *shipment
+	s.shipments[orderID] = &stored
+	return nil
+}
+
+func (s *MemoryOrderStore) Deliver(ctx context.Context, orderID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.updateStatus(orderID, model.OrderStatusShipped, model.OrderStatusDelivered, ""); err != nil {
+		return err
+	}
+	if shipment, ok := s.shipments[orderID]; ok {
+		now := time.Now()
+		shipment.DeliveredAt = &now
+	}
+	return nil
+}
+
+func (s *MemoryOrderStore) GetShipment(ctx context.Context, orderID int64) (*model.Shipment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shipment, ok := s.shipments[orderID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	result := *shipment
+	return &result, nil
+}
+
+func (s *MemoryOrderStore) StreamOrders(ctx context.Context, filter model.OrderFilter, fn func(*model.Order) error) error {
+	s.mu.Lock()
+	var matched []*model.Order
+	for _, order := range s.orders {
+		if matchesFilter(order, filter) {
+			o := copyOrder(order)
+			o.Shipping = nil // выгрузка из SQL тоже без адреса
+			matched = append(matched, o)
+		}
+	}
+	s.mu.Unlock()
+
+	slices.SortFunc(matched, func(a, b *model.Order) int { return int(a.ID - b.ID) })
+	for _, order := range matched {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchesFilter(order *model.Order, filter model.OrderFilter) bool {
+	if !filter.From.IsZero() && order.CreatedAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !order.CreatedAt.Before(filter.To) {
+		return false
+	}
+	return filter.Status == "" || order.Status == filter.Status
+}
+
+// copyOrder returns a copy that shares nothing with the stored order.
+// PromoUsageID is not stored, as in the SQL implementation.
+func copyOrder(order *model.Order) *model.Order {
+	c := *order
+	c.PromoUsageID = 0
+	c.Items = slices.Clone(order.Items)
+	if order.Shipping != nil {
+		shipping := *order.Shipping
+		c.Shipping = &shipping
+	}
+	return &c
+}
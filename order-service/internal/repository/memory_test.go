@@ -0,0 +1,14 @@
+package repository_test
+
+import (
+	"testing"
+
+	"order-service/internal/repository"
+	"order-service/internal/repository/storetest"
+)
+
+func TestMemoryOrderStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) repository.OrderStore {
+		return repository.NewMemoryOrderStore()
+	})
+}
@@ -0,0 +1,159 @@
+// internal/repository/memory_webhook.go
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"order-service/internal/model"
+)
+
+// MemoryWebhookStore — WebhookStore в памяти процесса для юнит-тестов.
+type MemoryWebhookStore struct {
+	mu         sync.Mutex
+	webhooks   map[int64]model.Webhook
+	deliveries map[int64]model.WebhookDelivery
+	lastID     struct{ webhook, delivery int64 }
+}
+
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		webhooks:   make(map[int64]model.Webhook),
+		deliveries: make(map[int64]model.WebhookDelivery),
+	}
+}
+
+func (s *MemoryWebhookStore) Create(ctx context.Context, webhook *model.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID.webhook++
+	webhook.ID = s.lastID.webhook
+	webhook.CreatedAt = time.Now()
+	stored := *webhook
+	stored.Events = slices.Clone(webhook.Events)
+	s.webhooks[webhook.ID] = stored
+	return nil
+}
+
+func (s *MemoryWebhookStore) Get(ctx context.Context, id int64) (*model.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	webhook.Events = slices.Clone(webhook.Events)
+	return &webhook, nil
+}
+
+func (s *MemoryWebhookStore) ListByUser(ctx context.Context, userID int64, activeOnly bool) ([]model.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var webhooks []model.Webhook
+	for _, webhook := range s.webhooks {
+		if webhook.UserID == userID && (webhook.Active || !activeOnly) {
+			webhook.Events = slices.Clone(webhook.Events)
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (s *MemoryWebhookStore) Delete(ctx context.Context, userID, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if webhook, ok := s.webhooks[id]; !ok || webhook.UserID != userID {
+		return ErrNotFound
+	}
+	delete(s.webhooks, id)
+	return nil
+}
+
+func (s *MemoryWebhookStore) CreateDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID.delivery++
+	d.ID = s.lastID.delivery
+	d.CreatedAt = time.Now()
+	d.NextAttemptAt = d.CreatedAt
+	d.Status = model.WebhookDeliveryPending
+	s.deliveries[d.ID] = *d
+	return nil
+}
+
+func (s *MemoryWebhookStore) GetDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &d, nil
+}
+
+func (s *MemoryWebhookStore) ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deliveries []model.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.WebhookID == webhookID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	return deliveries[:min(limit, len(deliveries))], nil
+}
+
+func (s *MemoryWebhookStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var due []model.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Status == model.WebhookDeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	due = due[:min(limit, len(due))]
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		s.deliveries[d.ID] = d
+	}
+	return due, nil
+}
+
+func (s *MemoryWebhookStore) SaveAttempt(ctx context.Context, d *model.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.deliveries[d.ID]; ok {
+		s.deliveries[d.ID] = *d
+	}
+	return nil
+}
+
+func (s *MemoryWebhookStore) Redeliver(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	d.Status, d.Attempts, d.NextAttemptAt = model.WebhookDeliveryPending, 0, time.Now()
+	s.deliveries[id] = d
+	return nil
+}
@@ -0,0 +1,332 @@
+// internal/repository/order.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"order-service/internal/model"
+)
+
+type OrderRepository struct {
+	db *DB
+}
+
+func NewOrderRepository(db *DB) *OrderRepository {
+	return &OrderRepository{db: db}
+}
+
+// Create inserts the order and all of its items in one transaction.
+func (r *OrderRepository) Create(ctx context.Context, order *model.Order) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertOrder(ctx, tx, order); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CreateBatch stores all orders in one transaction: either every order is
+// created or none is. On failure the returned BatchError names the order.
+func (r *OrderRepository) CreateBatch(ctx context.Context, orders []*model.Order) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, order := range orders {
+		if err := insertOrder(ctx, tx, order); err != nil {
+			return &BatchError{Index: i, Err: err}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func insertOrder(ctx context.Context, tx *Tx, order *model.Order) error {
+	order.CreatedAt = time.Now()
+
+	// Снимок адреса хранится как JSON и не зависит от таблицы addresses
+	var shipping sql.NullString
+	if order.Shipping != nil {
+		b, err := json.Marshal(order.Shipping)
+		if err != nil {
+			return err
+		}
+		shipping = nullString(string(b))
+	}
+
+	// Insert the order and return the generated ID
+	id, err := tx.insert(ctx, `
+		INSERT INTO orders (user_id, status, currency, total_amount, promo_code, discount_amount, shipping_address, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		order.UserID,
+		order.Status,
+		order.Currency,
+		order.TotalAmount,
+		nullString(order.PromoCode),
+		order.Discount,
+		shipping,
+		order.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	order.ID = id
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.OrderID = order.ID
+
+		item.ID, err = tx.insert(ctx, `
+			INSERT INTO order_items (order_id, product_id, quantity, unit_price, line_total)
+			VALUES (?, ?, ?, ?, ?)
+		`,
+			item.OrderID,
+			item.ProductID,
+			item.Quantity,
+			item.UnitPrice,
+			item.LineTotal,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Использование промокода привязывается к заказу атомарно с его созданием
+	if order.PromoUsageID != 0 {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE promotion_usages SET order_id = ? WHERE id = ?`, order.ID, order.PromoUsageID); err != nil {
+			return err
+		}
+	}
+
+	return insertStatusChange(ctx, tx, order.ID, "", order.Status, "")
+}
+
+// UpdateStatus moves the order from one status to another and records the change
+// in the status history. It returns ErrStatusConflict if the order is no longer in from.
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id int64, from, to, reason string) error {
+	return r.updateStatus(ctx, id, from, to, reason, nil)
+}
+
+// updateStatus is UpdateStatus that also runs fn in the same transaction,
+// for changes that must happen together with the status change.
+func (r *OrderRepository) updateStatus(ctx context.Context, id int64, from, to, reason string, fn func(tx *Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE orders SET status = ?, status_reason = ?
+		WHERE id = ? AND status = ?`, to, reason, id, from)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrStatusConflict
+	}
+
+	if err := insertStatusChange(ctx, tx, id, from, to, reason); err != nil {
+		return err
+	}
+	if fn != nil {
+		if err := fn(tx); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ExpirePending cancels up to limit pending orders created before the cutoff and
+// returns their IDs. Rows are locked with SKIP LOCKED, so concurrent replicas
+// pick disjoint batches instead of waiting for each other. Every cancelled order
+// gets a pending compensation in the same transaction, see ClaimCompensations.
+func (r *OrderRepository) ExpirePending(ctx context.Context, before time.Time, limit int, reason string) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// TIMESTAMP в MySQL хранит целые секунды: без усечения время округлилось бы
+	// вверх и первая попытка компенсации отложилась бы до следующего тика
+	now := time.Now().Truncate(time.Second)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM orders
+		WHERE status = ? AND created_at < ?
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`, model.OrderStatusPending, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE orders SET status = ?, status_reason = ? WHERE id = ?`,
+			model.OrderStatusCancelled, reason, id); err != nil {
+			return nil, err
+		}
+		if err := insertStatusChange(ctx, tx, id, model.OrderStatusPending, model.OrderStatusCancelled, reason); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO order_compensations (order_id, next_attempt_at) VALUES (?, ?)`,
+			id, now); err != nil {
+			return nil, err
+		}
+	}
+	return ids, tx.Commit()
+}
+
+// ClaimCompensations returns up to limit orders whose compensation is due and
+// postpones them by retryAfter: a claim that is not completed in time is picked
+// up again, by this or another replica.
+func (r *OrderRepository) ClaimCompensations(ctx context.Context, limit int, retryAfter time.Duration) ([]int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	rows, err := tx.QueryContext(ctx, `
+		SELECT order_id FROM order_compensations
+		WHERE next_attempt_at <= ?
+		ORDER BY order_id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE order_compensations SET attempts = attempts + 1, next_attempt_at = ?
+			WHERE order_id = ?`, now.Add(retryAfter), id); err != nil {
+			return nil, err
+		}
+	}
+	return ids, tx.Commit()
+}
+
+// CompleteCompensation removes the order's pending compensation.
+func (r *OrderRepository) CompleteCompensation(ctx context.Context, orderID int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM order_compensations WHERE order_id = ?`, orderID)
+	return err
+}
+
+// History returns the status changes of the order, oldest first.
+func (r *OrderRepository) History(ctx context.Context, orderID int64) ([]model.OrderStatusChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, order_id, COALESCE(from_status, ''), to_status, COALESCE(reason, ''), created_at
+		FROM order_status_history WHERE order_id = ? ORDER BY id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.OrderStatusChange
+	for rows.Next() {
+		var change model.OrderStatusChange
+		if err := rows.Scan(&change.ID, &change.OrderID, &change.FromStatus, &change.ToStatus, &change.Reason, &change.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+func insertStatusChange(ctx context.Context, tx *Tx, orderID int64, from, to, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO order_status_history (order_id, from_status, to_status, reason, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		orderID, nullString(from), to, nullString(reason), time.Now())
+	return err
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*model.Order, error) {
+	order := &model.Order{}
+	var shipping sql.NullString
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, status, COALESCE(status_reason, ''), currency, total_amount,
+			COALESCE(promo_code, ''), discount_amount, shipping_address, created_at
+		FROM orders WHERE id = ?`, id).
+		Scan(&order.ID, &order.UserID, &order.Status, &order.StatusReason, &order.Currency, &order.TotalAmount,
+			&order.PromoCode, &order.Discount, &shipping, &order.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if shipping.Valid {
+		order.Shipping = &model.ShippingAddress{}
+		if err := json.Unmarshal([]byte(shipping.String), order.Shipping); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, order_id, product_id, quantity, unit_price, line_total
+		FROM order_items WHERE order_id = ? ORDER BY id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.OrderItem
+		if err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.Quantity, &item.UnitPrice, &item.LineTotal); err != nil {
+			return nil, err
+		}
+		order.Items = append(order.Items, item)
+	}
+	return order, rows.Err()
+}
@@ -0,0 +1,40 @@
+// internal/repository/order_store.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"order-service/internal/model"
+)
+
+// OrderStore — хранилище заказов. Реализации: OrderRepository (MySQL и
+// PostgreSQL) и MemoryOrderStore для юнит-тестов; все должны проходить
+// storetest.Run.
+type OrderStore interface {
+	// Create inserts the order with its items and assigns their IDs.
+	Create(ctx context.Context, order *model.Order) error
+	// CreateBatch creates all orders or none; a failure is a *BatchError.
+	CreateBatch(ctx context.Context, orders []*model.Order) error
+	GetByID(ctx context.Context, id int64) (*model.Order, error)
+	// UpdateStatus returns ErrStatusConflict if the order is not in from.
+	UpdateStatus(ctx context.Context, id int64, from, to, reason string) error
+	// ExpirePending cancels up to limit pending orders created before the cutoff
+	// and records a pending compensation for each of them atomically.
+	ExpirePending(ctx context.Context, before time.Time, limit int, reason string) ([]int64, error)
+	// ClaimCompensations returns up to limit due compensations and postpones
+	// them by retryAfter until CompleteCompensation removes them.
+	ClaimCompensations(ctx context.Context, limit int, retryAfter time.Duration) ([]int64, error)
+	CompleteCompensation(ctx context.Context, orderID int64) error
+	History(ctx context.Context, orderID int64) ([]model.OrderStatusChange, error)
+	Ship(ctx context.Context, orderID int64, shipment *model.Shipment) error
+	Deliver(ctx context.Context, orderID int64) error
+	GetShipment(ctx context.Context, orderID int64) (*model.Shipment, error)
+	// StreamOrders calls fn for every matching order, oldest first.
+	StreamOrders(ctx context.Context, filter model.OrderFilter, fn func(*model.Order) error) error
+}
+
+var (
+	_ OrderStore = (*OrderRepository)(nil)
+	_ OrderStore = (*MemoryOrderStore)(nil)
+)
@@ -0,0 +1,130 @@
+// internal/repository/payment.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"order-service/internal/model"
+)
+
+type PaymentRepository struct {
+	db *DB
+}
+
+func NewPaymentRepository(db *DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// Create inserts a payment. An order has at most one payment that has not failed:
+// Create returns ErrAlreadyExists if another one already holds the order.
+func (r *PaymentRepository) Create(ctx context.Context, p *model.Payment) error {
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+
+	// active_order_id под UNIQUE; у failed-платежей он NULL и заказ не занимает
+	var activeOrderID sql.NullInt64
+	if p.Status != model.PaymentStatusFailed {
+		activeOrderID = sql.NullInt64{Int64: p.OrderID, Valid: true}
+	}
+
+	var err error
+	p.ID, err = r.db.insert(ctx, `
+		INSERT INTO payments (order_id, active_order_id, provider, provider_payment_id, amount, currency, status, failure_reason, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		p.OrderID,
+		activeOrderID,
+		p.Provider,
+		p.ProviderPaymentID,
+		p.Amount,
+		p.Currency,
+		p.Status,
+		nullString(p.FailureReason),
+		p.CreatedAt,
+		p.UpdatedAt,
+	)
+	return duplicateAsExists(err)
+}
+
+func (r *PaymentRepository) GetByProviderID(ctx context.Context, provider, providerPaymentID string) (*model.Payment, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+paymentColumns+` FROM payments
+		WHERE provider = ? AND provider_payment_id = ?`, provider, providerPaymentID)
+	return scanPayment(row)
+}
+
+// GetActiveByOrder returns the latest payment of the order that has not failed.
+func (r *PaymentRepository) GetActiveByOrder(ctx context.Context, orderID int64) (*model.Payment, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+paymentColumns+` FROM payments
+		WHERE order_id = ? AND status <> ?
+		ORDER BY id DESC LIMIT 1`, orderID, model.PaymentStatusFailed)
+	return scanPayment(row)
+}
+
+// CountByOrder returns the number of payments recorded for the order, failed ones included.
+func (r *PaymentRepository) CountByOrder(ctx context.Context, orderID int64) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM payments WHERE order_id = ?`, orderID).Scan(&n)
+	return n, err
+}
+
+// UpdateStatus changes the payment status only if it is still in from,
+// which makes repeated provider notifications harmless. A failed payment releases
+// the order, so it can be paid again.
+func (r *PaymentRepository) UpdateStatus(ctx context.Context, id int64, from, to, failureReason string) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE payments
+		SET status = ?, failure_reason = ?, updated_at = ?,
+			active_order_id = CASE WHEN ? THEN NULL ELSE active_order_id END
+		WHERE id = ? AND status = ?`,
+		to, nullString(failureReason), time.Now(), to == model.PaymentStatusFailed, id, from)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrStatusConflict
+	}
+	return nil
+}
+
+// AddRefund records a refund of amount on a captured payment. The payment turns
+// refunded once the whole amount is returned. It returns ErrStatusConflict if the
+// payment is not captured or the refund would exceed the captured amount.
+func (r *PaymentRepository) AddRefund(ctx context.Context, id, amount int64) error {
+	// status считается по refunded_amount до обновления: MySQL выполняет SET слева
+	// направо, PostgreSQL читает старую строку
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE payments
+		SET status = CASE WHEN refunded_amount + ? >= amount THEN ? ELSE status END,
+			refunded_amount = refunded_amount + ?,
+			updated_at = ?
+		WHERE id = ? AND status = ? AND refunded_amount + ? <= amount`,
+		amount, model.PaymentStatusRefunded, amount, time.Now(), id, model.PaymentStatusCaptured, amount)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrStatusConflict
+	}
+	return nil
+}
+
+const paymentColumns = `id, order_id, provider, provider_payment_id, amount, currency, refunded_amount, status,
+		COALESCE(failure_reason, ''), created_at, updated_at`
+
+func scanPayment(row rowScanner) (*model.Payment, error) {
+	p := &model.Payment{}
+	err := row.Scan(&p.ID, &p.OrderID, &p.Provider, &p.ProviderPaymentID, &p.Amount, &p.Currency, &p.RefundedAmount, &p.Status,
+		&p.FailureReason, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
@@ -0,0 +1,270 @@
+// internal/repository/promotion.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-service/internal/model"
+)
+
+type PromotionRepository struct {
+	db *DB
+}
+
+func NewPromotionRepository(db *DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+func (r *PromotionRepository) Create(ctx context.Context, p *model.Promotion) error {
+	p.CreatedAt = time.Now()
+	p.UsedCount = 0
+
+	var err error
+	p.ID, err = r.db.insert(ctx, `
+		INSERT INTO promotions (code, type, value, currency, min_order_amount, max_uses, max_uses_per_user,
+			used_count, product_ids, starts_at, ends_at, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?)
+	`,
+		p.Code,
+		p.Type,
+		p.Value,
+		nullString(p.Currency),
+		p.MinOrderAmount,
+		p.MaxUses,
+		p.MaxUsesPerUser,
+		joinIDs(p.ProductIDs),
+		p.StartsAt,
+		p.EndsAt,
+		p.Active,
+		p.CreatedAt,
+	)
+	return duplicateAsExists(err)
+}
+
+func (r *PromotionRepository) Get(ctx context.Context, id int64) (*model.Promotion, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+promotionColumns+` FROM promotions WHERE id = ?`, id)
+	return scanPromotion(row)
+}
+
+func (r *PromotionRepository) List(ctx context.Context) ([]model.Promotion, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+promotionColumns+` FROM promotions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []model.Promotion
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, *p)
+	}
+	return promotions, rows.Err()
+}
+
+// Update overwrites the editable fields; used_count is maintained by Reserve and Release only.
+func (r *PromotionRepository) Update(ctx context.Context, p *model.Promotion) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE promotions
+		SET code = ?, type = ?, value = ?, currency = ?, min_order_amount = ?, max_uses = ?,
+			max_uses_per_user = ?, product_ids = ?, starts_at = ?, ends_at = ?, active = ?
+		WHERE id = ?`,
+		p.Code, p.Type, p.Value, nullString(p.Currency), p.MinOrderAmount, p.MaxUses,
+		p.MaxUsesPerUser, joinIDs(p.ProductIDs), p.StartsAt, p.EndsAt, p.Active, p.ID)
+	if err != nil {
+		return duplicateAsExists(err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		// MySQL не считает строку изменённой, если значения совпали
+		if _, err := r.Get(ctx, p.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PromotionRepository) Delete(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM promotions WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Reserve takes one usage of the promotion with the given code. The promotion row
+// is locked for the duration of the check, so concurrent orders cannot exceed
+// the global or per-user limits. check sees the locked promotion and the number
+// of usages the user already holds; a non-nil result aborts the reservation.
+func (r *PromotionRepository) Reserve(ctx context.Context, code string, userID int64, check func(p *model.Promotion, userUses int) error) (*model.Promotion, int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, `SELECT `+promotionColumns+` FROM promotions WHERE code = ? FOR UPDATE`, code)
+	p, err := scanPromotion(row)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var userUses int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM promotion_usages WHERE promotion_id = ? AND user_id = ?`,
+		p.ID, userID).Scan(&userUses); err != nil {
+		return nil, 0, err
+	}
+
+	if err := check(p, userUses); err != nil {
+		return nil, 0, err
+	}
+
+	usageID, err := tx.insert(ctx, `
+		INSERT INTO promotion_usages (promotion_id, user_id, created_at) VALUES (?, ?, ?)`,
+		p.ID, userID, time.Now())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE promotions SET used_count = used_count + 1 WHERE id = ?`, p.ID); err != nil {
+		return nil, 0, err
+	}
+	p.UsedCount++
+
+	return p, usageID, tx.Commit()
+}
+
+// ReleaseOrphaned returns up to limit usages reserved before the cutoff that never
+// got attached to an order: the order insert failed, or the replica died before
+// ReleaseUsage. Usages are locked with SKIP LOCKED, so replicas can sweep in
+// parallel. Returns the number of usages released.
+func (r *PromotionRepository) ReleaseOrphaned(ctx context.Context, before time.Time, limit int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, promotion_id FROM promotion_usages
+		WHERE order_id IS NULL AND created_at < ?
+		ORDER BY id
+		LIMIT ?
+		FOR UPDATE SKIP LOCKED`, before, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	type usage struct{ id, promotionID int64 }
+	var orphans []usage
+	for rows.Next() {
+		var u usage
+		if err := rows.Scan(&u.id, &u.promotionID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		orphans = append(orphans, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, u := range orphans {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM promotion_usages WHERE id = ?`, u.id); err != nil {
+			return 0, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE promotions SET used_count = used_count - 1 WHERE id = ? AND used_count > 0`, u.promotionID); err != nil {
+			return 0, err
+		}
+	}
+	return len(orphans), tx.Commit()
+}
+
+// ReleaseUsage returns a reserved usage that never got attached to an order.
+func (r *PromotionRepository) ReleaseUsage(ctx context.Context, usageID int64) error {
+	return r.release(ctx, `id = ?`, usageID)
+}
+
+// ReleaseOrder returns the usage held by the order. Releasing twice is a no-op.
+func (r *PromotionRepository) ReleaseOrder(ctx context.Context, orderID int64) error {
+	return r.release(ctx, `order_id = ?`, orderID)
+}
+
+func (r *PromotionRepository) release(ctx context.Context, where string, arg int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var usageID, promotionID int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, promotion_id FROM promotion_usages WHERE `+where+` FOR UPDATE`, arg).
+		Scan(&usageID, &promotionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM promotion_usages WHERE id = ?`, usageID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE promotions SET used_count = used_count - 1 WHERE id = ? AND used_count > 0`, promotionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+const promotionColumns = `id, code, type, value, COALESCE(currency, ''), min_order_amount, max_uses,
+		max_uses_per_user, used_count, COALESCE(product_ids, ''), starts_at, ends_at, active, created_at`
+
+func scanPromotion(row rowScanner) (*model.Promotion, error) {
+	p := &model.Promotion{}
+	var productIDs string
+	var startsAt, endsAt sql.NullTime
+	err := row.Scan(&p.ID, &p.Code, &p.Type, &p.Value, &p.Currency, &p.MinOrderAmount, &p.MaxUses,
+		&p.MaxUsesPerUser, &p.UsedCount, &productIDs, &startsAt, &endsAt, &p.Active, &p.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if startsAt.Valid {
+		p.StartsAt = &startsAt.Time
+	}
+	if endsAt.Valid {
+		p.EndsAt = &endsAt.Time
+	}
+	for _, s := range strings.Split(productIDs, ",") {
+		if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+			p.ProductIDs = append(p.ProductIDs, id)
+		}
+	}
+	return p, nil
+}
+
+func joinIDs(ids []int64) sql.NullString {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return nullString(strings.Join(parts, ","))
+}
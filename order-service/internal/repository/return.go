@@ -0,0 +1,244 @@
+// internal/repository/return.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"order-service/internal/model"
+)
+
+type ReturnRepository struct {
+	db *DB
+}
+
+func NewReturnRepository(db *DB) *ReturnRepository {
+	return &ReturnRepository{db: db}
+}
+
+// Create stores a return request. The order row is locked while check looks at
+// the quantities already claimed by other returns of the order (rejected ones
+// excluded), so two concurrent requests cannot return the same item twice.
+func (r *ReturnRepository) Create(ctx context.Context, ret *model.Return, check func(claimed map[int64]int) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var orderID int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM orders WHERE id = ? FOR UPDATE`, ret.OrderID).Scan(&orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	claimed, err := sumReturned(ctx, tx, ret.OrderID, `r.status <> ?`, model.ReturnStatusRejected)
+	if err != nil {
+		return err
+	}
+	if err := check(claimed); err != nil {
+		return err
+	}
+
+	ret.Status = model.ReturnStatusRequested
+	ret.CreatedAt = time.Now()
+	ret.UpdatedAt = ret.CreatedAt
+
+	ret.ID, err = tx.insert(ctx, `
+		INSERT INTO returns (order_id, user_id, status, refund_amount, quarantine, created_at, updated_at)
+		VALUES (?, ?, ?, ?, FALSE, ?, ?)
+	`,
+		ret.OrderID,
+		ret.UserID,
+		ret.Status,
+		ret.RefundAmount,
+		ret.CreatedAt,
+		ret.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i := range ret.Items {
+		item := &ret.Items[i]
+		item.ReturnID = ret.ID
+
+		item.ID, err = tx.insert(ctx, `
+			INSERT INTO return_items (return_id, product_id, quantity, reason, refund_amount)
+			VALUES (?, ?, ?, ?, ?)`,
+			item.ReturnID, item.ProductID, item.Quantity, item.Reason, item.RefundAmount)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := insertReturnHistory(ctx, tx, ret, "", ret.Status); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *ReturnRepository) Get(ctx context.Context, id int64) (*model.Return, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+returnColumns+` FROM returns WHERE id = ?`, id)
+	ret, err := scanReturn(row)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.items(ctx, ret.ID)
+	if err != nil {
+		return nil, err
+	}
+	ret.Items = items
+	return ret, nil
+}
+
+// ListByOrder returns the returns of the order, oldest first.
+func (r *ReturnRepository) ListByOrder(ctx context.Context, orderID int64) ([]model.Return, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+returnColumns+` FROM returns WHERE order_id = ? ORDER BY id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returns []model.Return
+	for rows.Next() {
+		ret, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, *ret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range returns {
+		if returns[i].Items, err = r.items(ctx, returns[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return returns, nil
+}
+
+// UpdateStatus moves the return from one status to another, saving its note and
+// quarantine flag, and records the step in the order history. It returns
+// ErrStatusConflict if the return is no longer in from.
+func (r *ReturnRepository) UpdateStatus(ctx context.Context, ret *model.Return, from, to string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE returns SET status = ?, note = ?, quarantine = ?, updated_at = ?
+		WHERE id = ? AND status = ?`,
+		to, nullString(ret.Note), ret.Quarantine, now, ret.ID, from)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrStatusConflict
+	}
+
+	if err := insertReturnHistory(ctx, tx, ret, from, to); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	ret.Status = to
+	ret.UpdatedAt = now
+	return nil
+}
+
+// RefundedQuantities returns how many units of each product of the order were
+// returned and refunded.
+func (r *ReturnRepository) RefundedQuantities(ctx context.Context, orderID int64) (map[int64]int, error) {
+	return sumReturned(ctx, r.db, orderID, `r.status = ?`, model.ReturnStatusRefunded)
+}
+
+func (r *ReturnRepository) items(ctx context.Context, returnID int64) ([]model.ReturnItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, return_id, product_id, quantity, reason, refund_amount
+		FROM return_items WHERE return_id = ? ORDER BY id`, returnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.ReturnItem
+	for rows.Next() {
+		var item model.ReturnItem
+		if err := rows.Scan(&item.ID, &item.ReturnID, &item.ProductID, &item.Quantity, &item.Reason, &item.RefundAmount); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func sumReturned(ctx context.Context, q queryer, orderID int64, cond string, args ...any) (map[int64]int, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT ri.product_id, SUM(ri.quantity)
+		FROM return_items ri
+		JOIN returns r ON r.id = ri.return_id
+		WHERE r.order_id = ? AND `+cond+`
+		GROUP BY ri.product_id`, append([]any{orderID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	quantities := make(map[int64]int)
+	for rows.Next() {
+		var productID int64
+		var quantity int
+		if err := rows.Scan(&productID, &quantity); err != nil {
+			return nil, err
+		}
+		quantities[productID] = quantity
+	}
+	return quantities, rows.Err()
+}
+
+// insertReturnHistory puts a return step into the order status history,
+// e.g. "return_approved", so the whole story of the order is in one place.
+func insertReturnHistory(ctx context.Context, tx *Tx, ret *model.Return, from, to string) error {
+	if from != "" {
+		from = model.ReturnHistoryPrefix + from
+	}
+	reason := fmt.Sprintf("return #%d", ret.ID)
+	if ret.Note != "" {
+		reason += ": " + ret.Note
+	}
+	return insertStatusChange(ctx, tx, ret.OrderID, from, model.ReturnHistoryPrefix+to, reason)
+}
+
+const returnColumns = `id, order_id, user_id, status, refund_amount, quarantine, COALESCE(note, ''), created_at, updated_at`
+
+func scanReturn(row rowScanner) (*model.Return, error) {
+	ret := &model.Return{}
+	err := row.Scan(&ret.ID, &ret.OrderID, &ret.UserID, &ret.Status, &ret.RefundAmount, &ret.Quarantine,
+		&ret.Note, &ret.CreatedAt, &ret.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return ret, nil
+}
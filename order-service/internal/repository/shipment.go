@@ -0,0 +1,54 @@
+// internal/repository/shipment.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"order-service/internal/model"
+)
+
+// Ship moves a paid order to shipped and creates its shipment in one transaction.
+func (r *OrderRepository) Ship(ctx context.Context, orderID int64, shipment *model.Shipment) error {
+	shipment.OrderID = orderID
+	shipment.ShippedAt = time.Now()
+
+	return r.updateStatus(ctx, orderID, model.OrderStatusPaid, model.OrderStatusShipped, "", func(tx *Tx) error {
+		var err error
+		shipment.ID, err = tx.insert(ctx, `
+			INSERT INTO shipments (order_id, carrier, tracking_number, shipped_at)
+			VALUES (?, ?, ?, ?)`,
+			shipment.OrderID, shipment.Carrier, shipment.TrackingNumber, shipment.ShippedAt)
+		return err
+	})
+}
+
+// Deliver moves a shipped order to delivered and stamps its shipment.
+func (r *OrderRepository) Deliver(ctx context.Context, orderID int64) error {
+	return r.updateStatus(ctx, orderID, model.OrderStatusShipped, model.OrderStatusDelivered, "", func(tx *Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE shipments SET delivered_at = ? WHERE order_id = ?`, time.Now(), orderID)
+		return err
+	})
+}
+
+func (r *OrderRepository) GetShipment(ctx context.Context, orderID int64) (*model.Shipment, error) {
+	s := &model.Shipment{}
+	var deliveredAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, order_id, carrier, tracking_number, shipped_at, delivered_at
+		FROM shipments WHERE order_id = ?`, orderID).
+		Scan(&s.ID, &s.OrderID, &s.Carrier, &s.TrackingNumber, &s.ShippedAt, &deliveredAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if deliveredAt.Valid {
+		s.DeliveredAt = &deliveredAt.Time
+	}
+	return s, nil
+}
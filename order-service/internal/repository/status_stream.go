@@ -0,0 +1,122 @@
+// internal/repository/status_stream.go
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-service/internal/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	statusStreamMaxLen = 100
+	statusStreamTTL    = 24 * time.Hour
+	// statusChannel — канал Pub/Sub, в который Append дублирует каждое событие
+	// для live-подписчиков всех реплик
+	statusChannel = "order-events"
+	// statusReadCount — сколько событий Read отдаёт за раз
+	statusReadCount = 20
+)
+
+// appendScript добавляет событие в стрим заказа и публикует его вместе с ID
+// записи одной командой: подписчик получает тот же ID, что и дочитывающий стрим.
+var appendScript = redis.NewScript(`
+local id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'data', ARGV[2])
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+redis.call('PUBLISH', ARGV[4], id .. ' ' .. ARGV[2])
+return id
+`)
+
+// StatusStreamRepository хранит события статусов заказа в Redis Stream
+// (по одному стриму на заказ) и рассылает их через Pub/Sub. Стрим общий для
+// всех реплик и позволяет дочитать пропущенное по Last-Event-ID.
+type StatusStreamRepository struct {
+	redis *redis.Client
+}
+
+func NewStatusStreamRepository(redis *redis.Client) *StatusStreamRepository {
+	return &StatusStreamRepository{redis: redis}
+}
+
+func statusStreamKey(orderID int64) string {
+	return "order-events:" + strconv.FormatInt(orderID, 10)
+}
+
+func (r *StatusStreamRepository) Append(ctx context.Context, event *model.OrderStatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	return appendScript.Run(ctx, r.redis, []string{statusStreamKey(event.OrderID)},
+		statusStreamMaxLen, data, int(statusStreamTTL.Seconds()), statusChannel).Err()
+}
+
+// LastID returns the ID of the newest event, or "0-0" for an empty stream.
+func (r *StatusStreamRepository) LastID(ctx context.Context, orderID int64) (string, error) {
+	msgs, err := r.redis.XRevRangeN(ctx, statusStreamKey(orderID), "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+	if len(msgs) == 0 {
+		return "0-0", nil
+	}
+	return msgs[0].ID, nil
+}
+
+// Read returns up to statusReadCount events after afterID without waiting.
+func (r *StatusStreamRepository) Read(ctx context.Context, orderID int64, afterID string) ([]model.OrderStatusEvent, error) {
+	msgs, err := r.redis.XRangeN(ctx, statusStreamKey(orderID), "("+afterID, "+", statusReadCount).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var events []model.OrderStatusEvent
+	for _, msg := range msgs {
+		data, _ := msg.Values["data"].(string)
+		var event model.OrderStatusEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			continue
+		}
+		event.ID = msg.ID
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Listen subscribes to the events of all orders and calls fn for each one
+// until ctx is cancelled. Events published while the connection is down are
+// not redelivered; they stay in the order streams for Read.
+func (r *StatusStreamRepository) Listen(ctx context.Context, fn func(model.OrderStatusEvent)) error {
+	pubsub := r.redis.Subscribe(ctx, statusChannel)
+	defer pubsub.Close()
+
+	// Ждём подтверждения подписки, чтобы ошибка подключения вернулась сразу
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			id, data, _ := strings.Cut(msg.Payload, " ")
+			var event model.OrderStatusEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				continue
+			}
+			event.ID = id
+			fn(event)
+		}
+	}
+}
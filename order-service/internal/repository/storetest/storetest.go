@@ -0,0 +1,402 @@
+// internal/repository/storetest/storetest.go
+
+// Package storetest проверяет, что реализации repository.OrderStore ведут себя
+// одинаково. Запускается из тестов internal/repository: на MemoryOrderStore
+// всегда, на MySQL и PostgreSQL — если заданы STORETEST_MYSQL_DSN / STORETEST_POSTGRES_DSN.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+)
+
+// cases — по subtest на проверку: упавшую можно перезапустить отдельно,
+// например go test -run 'TestMemoryOrderStore/Expiry'.
+var cases = []struct {
+	name  string
+	check func(c *checker)
+}{
+	{"CreateAndGet", (*checker).createAndGet},
+	{"StatusChanges", (*checker).statusChanges},
+	{"Fulfilment", (*checker).fulfilment},
+	{"Batch", (*checker).batch},
+	{"Expiry", (*checker).expiry},
+	{"Compensations", (*checker).compensations},
+	{"Stream", (*checker).stream},
+}
+
+// Run runs every conformance check as a subtest of t, each against the store
+// returned by newStore. Use an empty store or a scratch database only: the
+// checks create orders, and ExpirePending cancels any pending order it finds.
+func Run(t *testing.T, newStore func(t *testing.T) repository.OrderStore) {
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &checker{t: t, ctx: t.Context(), store: newStore(t), userID: time.Now().UnixNano()}
+			tc.check(c)
+		})
+	}
+}
+
+type checker struct {
+	t      *testing.T
+	ctx    context.Context
+	store  repository.OrderStore
+	userID int64 // у каждого прогона свой пользователь
+}
+
+func (c *checker) errorf(format string, args ...any) {
+	c.t.Helper()
+	c.t.Errorf(format, args...)
+}
+
+func (c *checker) newOrder(status string) *model.Order {
+	return &model.Order{
+		UserID:      c.userID,
+		Status:      status,
+		Currency:    "RUB",
+		TotalAmount: 2*49900 + 1990 - 500,
+		PromoCode:   "STORETEST",
+		Discount:    500,
+		Shipping: &model.ShippingAddress{
+			Recipient:  "Store Test",
+			Line1:      "Test street 1",
+			City:       "Moscow",
+			PostalCode: "101000",
+			Country:    "RU",
+		},
+		Items: []model.OrderItem{
+			{ProductID: 1, Quantity: 2, UnitPrice: 49900, LineTotal: 2 * 49900},
+			{ProductID: 2, Quantity: 1, UnitPrice: 1990, LineTotal: 1990},
+		},
+	}
+}
+
+// create stores a new order; on failure it records the error and returns nil.
+func (c *checker) create(status string) *model.Order {
+	c.t.Helper()
+	order := c.newOrder(status)
+	if err := c.store.Create(c.ctx, order); err != nil {
+		c.errorf("Create: %v", err)
+		return nil
+	}
+	return order
+}
+
+func (c *checker) status(id int64) string {
+	c.t.Helper()
+	order, err := c.store.GetByID(c.ctx, id)
+	if err != nil {
+		c.errorf("GetByID(%d): %v", id, err)
+		return ""
+	}
+	return order.Status
+}
+
+func (c *checker) createAndGet() {
+	order := c.create(model.OrderStatusPending)
+	if order == nil {
+		return
+	}
+	if order.ID <= 0 {
+		c.errorf("Create: order ID %d, want positive", order.ID)
+	}
+	if order.CreatedAt.IsZero() {
+		c.errorf("Create: CreatedAt not set")
+	}
+	for _, item := range order.Items {
+		if item.ID <= 0 || item.OrderID != order.ID {
+			c.errorf("Create: item %+v has no ID or wrong OrderID", item)
+		}
+	}
+
+	got, err := c.store.GetByID(c.ctx, order.ID)
+	if err != nil {
+		c.errorf("GetByID after Create: %v", err)
+		return
+	}
+	if got.UserID != order.UserID || got.Status != order.Status || got.Currency != order.Currency ||
+		got.TotalAmount != order.TotalAmount || got.PromoCode != order.PromoCode || got.Discount != order.Discount {
+		c.errorf("GetByID: got %+v, want %+v", got, order)
+	}
+	if !reflect.DeepEqual(got.Shipping, order.Shipping) {
+		c.errorf("GetByID: shipping %+v, want %+v", got.Shipping, order.Shipping)
+	}
+	if !reflect.DeepEqual(got.Items, order.Items) {
+		c.errorf("GetByID: items %+v, want %+v", got.Items, order.Items)
+	}
+
+	// Изменение прочитанного заказа не должно протекать в хранилище
+	got.Status = model.OrderStatusCancelled
+	got.Items[0].Quantity = 100
+	if again, err := c.store.GetByID(c.ctx, order.ID); err == nil &&
+		(again.Status != model.OrderStatusPending || again.Items[0].Quantity != 2) {
+		c.errorf("GetByID: returned order shares state with the store")
+	}
+
+	if _, err := c.store.GetByID(c.ctx, -1); !errors.Is(err, repository.ErrNotFound) {
+		c.errorf("GetByID(unknown): %v, want ErrNotFound", err)
+	}
+}
+
+func (c *checker) statusChanges() {
+	order := c.create(model.OrderStatusPending)
+	if order == nil {
+		return
+	}
+
+	if err := c.store.UpdateStatus(c.ctx, order.ID, model.OrderStatusPending, model.OrderStatusPaid, "storetest"); err != nil {
+		c.errorf("UpdateStatus: %v", err)
+	}
+	if got, err := c.store.GetByID(c.ctx, order.ID); err == nil &&
+		(got.Status != model.OrderStatusPaid || got.StatusReason != "storetest") {
+		c.errorf("UpdateStatus: status %q reason %q, want %q %q", got.Status, got.StatusReason, model.OrderStatusPaid, "storetest")
+	}
+
+	err := c.store.UpdateStatus(c.ctx, order.ID, model.OrderStatusPending, model.OrderStatusCancelled, "")
+	if !errors.Is(err, repository.ErrStatusConflict) {
+		c.errorf("UpdateStatus from a stale status: %v, want ErrStatusConflict", err)
+	}
+	err = c.store.UpdateStatus(c.ctx, -1, model.OrderStatusPending, model.OrderStatusPaid, "")
+	if !errors.Is(err, repository.ErrStatusConflict) {
+		c.errorf("UpdateStatus(unknown): %v, want ErrStatusConflict", err)
+	}
+
+	history, err := c.store.History(c.ctx, order.ID)
+	if err != nil {
+		c.errorf("History: %v", err)
+		return
+	}
+	want := []struct{ from, to string }{
+		{"", model.OrderStatusPending},
+		{model.OrderStatusPending, model.OrderStatusPaid},
+	}
+	if len(history) != len(want) {
+		c.errorf("History: %d entries, want %d", len(history), len(want))
+		return
+	}
+	for i, h := range history {
+		if h.OrderID != order.ID || h.FromStatus != want[i].from || h.ToStatus != want[i].to {
+			c.errorf("History[%d]: %s -> %s, want %s -> %s", i, h.FromStatus, h.ToStatus, want[i].from, want[i].to)
+		}
+	}
+	if history[1].Reason != "storetest" || history[1].ID <= history[0].ID {
+		c.errorf("History: entries out of order or reason lost: %+v", history)
+	}
+}
+
+func (c *checker) fulfilment() {
+	order := c.create(model.OrderStatusPending)
+	if order == nil {
+		return
+	}
+
+	if _, err := c.store.GetShipment(c.ctx, order.ID); !errors.Is(err, repository.ErrNotFound) {
+		c.errorf("GetShipment before Ship: %v, want ErrNotFound", err)
+	}
+	if err := c.store.Ship(c.ctx, order.ID, &model.Shipment{Carrier: "cdek", TrackingNumber: "X"}); !errors.Is(err, repository.ErrStatusConflict) {
+		c.errorf("Ship of a pending order: %v, want ErrStatusConflict", err)
+	}
+
+	if err := c.store.UpdateStatus(c.ctx, order.ID, model.OrderStatusPending, model.OrderStatusPaid, ""); err != nil {
+		c.errorf("UpdateStatus: %v", err)
+		return
+	}
+	shipment := &model.Shipment{Carrier: "cdek", TrackingNumber: "CDEK-1"}
+	if err := c.store.Ship(c.ctx, order.ID, shipment); err != nil {
+		c.errorf("Ship: %v", err)
+		return
+	}
+	if shipment.ID <= 0 || shipment.OrderID != order.ID {
+		c.errorf("Ship: shipment %+v has no ID or wrong OrderID", shipment)
+	}
+	if got := c.status(order.ID); got != model.OrderStatusShipped {
+		c.errorf("Ship: status %q, want %q", got, model.OrderStatusShipped)
+	}
+
+	got, err := c.store.GetShipment(c.ctx, order.ID)
+	if err != nil {
+		c.errorf("GetShipment: %v", err)
+	} else if got.ID != shipment.ID || got.Carrier != "cdek" || got.TrackingNumber != "CDEK-1" || got.DeliveredAt != nil {
+		c.errorf("GetShipment: got %+v, want %+v", got, shipment)
+	}
+
+	if err := c.store.Deliver(c.ctx, order.ID); err != nil {
+		c.errorf("Deliver: %v", err)
+		return
+	}
+	if got := c.status(order.ID); got != model.OrderStatusDelivered {
+		c.errorf("Deliver: status %q, want %q", got, model.OrderStatusDelivered)
+	}
+	if got, err := c.store.GetShipment(c.ctx, order.ID); err == nil && got.DeliveredAt == nil {
+		c.errorf("Deliver: shipment DeliveredAt not set")
+	}
+	if err := c.store.Deliver(c.ctx, order.ID); !errors.Is(err, repository.ErrStatusConflict) {
+		c.errorf("Deliver twice: %v, want ErrStatusConflict", err)
+	}
+}
+
+func (c *checker) batch() {
+	orders := []*model.Order{
+		c.newOrder(model.OrderStatusPending),
+		c.newOrder(model.OrderStatusPending),
+		c.newOrder(model.OrderStatusPending),
+	}
+	if err := c.store.CreateBatch(c.ctx, orders); err != nil {
+		c.errorf("CreateBatch: %v", err)
+		return
+	}
+
+	seen := make(map[int64]bool)
+	for _, order := range orders {
+		if order.ID <= 0 || seen[order.ID] {
+			c.errorf("CreateBatch: order ID %d is not positive or not unique", order.ID)
+			continue
+		}
+		seen[order.ID] = true
+		if got, err := c.store.GetByID(c.ctx, order.ID); err != nil {
+			c.errorf("GetByID after CreateBatch: %v", err)
+		} else if len(got.Items) != len(order.Items) {
+			c.errorf("CreateBatch: order %d has %d items, want %d", order.ID, len(got.Items), len(order.Items))
+		}
+	}
+}
+
+func (c *checker) expiry() {
+	// Запас в час покрывает точность DATETIME в MySQL
+	cutoff := time.Now().Add(time.Hour)
+
+	order := c.create(model.OrderStatusPending)
+	paid := c.create(model.OrderStatusPending)
+	if order == nil || paid == nil {
+		return
+	}
+	if err := c.store.UpdateStatus(c.ctx, paid.ID, model.OrderStatusPending, model.OrderStatusPaid, ""); err != nil {
+		c.errorf("UpdateStatus: %v", err)
+		return
+	}
+
+	if ids, err := c.store.ExpirePending(c.ctx, time.Now().Add(-time.Hour), 100, "storetest"); err != nil {
+		c.errorf("ExpirePending: %v", err)
+	} else if slices.Contains(ids, order.ID) {
+		c.errorf("ExpirePending: cancelled an order created after the cutoff")
+	}
+
+	ids, err := c.store.ExpirePending(c.ctx, cutoff, 1000, "storetest")
+	if err != nil {
+		c.errorf("ExpirePending: %v", err)
+		return
+	}
+	if !slices.Contains(ids, order.ID) || slices.Contains(ids, paid.ID) {
+		c.errorf("ExpirePending: got %v, want %d and not %d", ids, order.ID, paid.ID)
+	}
+	if got, err := c.store.GetByID(c.ctx, order.ID); err == nil &&
+		(got.Status != model.OrderStatusCancelled || got.StatusReason != "storetest") {
+		c.errorf("ExpirePending: status %q reason %q, want %q %q", got.Status, got.StatusReason, model.OrderStatusCancelled, "storetest")
+	}
+	if got := c.status(paid.ID); got != model.OrderStatusPaid {
+		c.errorf("ExpirePending: paid order became %q", got)
+	}
+
+	// Все старые pending уже отменены, остаются только два новых
+	if c.create(model.OrderStatusPending) == nil || c.create(model.OrderStatusPending) == nil {
+		return
+	}
+	if ids, err := c.store.ExpirePending(c.ctx, cutoff, 1, "storetest"); err != nil {
+		c.errorf("ExpirePending: %v", err)
+	} else if len(ids) != 1 {
+		c.errorf("ExpirePending with limit 1: got %d orders", len(ids))
+	}
+}
+
+func (c *checker) compensations() {
+	kept := c.create(model.OrderStatusPending)
+	done := c.create(model.OrderStatusPending)
+	if kept == nil || done == nil {
+		return
+	}
+	if _, err := c.store.ExpirePending(c.ctx, time.Now().Add(time.Hour), 1000, "storetest"); err != nil {
+		c.errorf("ExpirePending: %v", err)
+		return
+	}
+
+	claim := func(retryAfter time.Duration) []int64 {
+		c.t.Helper()
+		ids, err := c.store.ClaimCompensations(c.ctx, 1000, retryAfter)
+		if err != nil {
+			c.errorf("ClaimCompensations: %v", err)
+		}
+		return ids
+	}
+
+	// Отрицательная отсрочка оставляет компенсации к выполнению сразу
+	if ids := claim(-time.Hour); !slices.Contains(ids, kept.ID) || !slices.Contains(ids, done.ID) {
+		c.errorf("ClaimCompensations: got %v, want expired orders %d and %d", ids, kept.ID, done.ID)
+	}
+	if err := c.store.CompleteCompensation(c.ctx, done.ID); err != nil {
+		c.errorf("CompleteCompensation: %v", err)
+	}
+	if ids := claim(time.Hour); !slices.Contains(ids, kept.ID) || slices.Contains(ids, done.ID) {
+		c.errorf("ClaimCompensations: got %v, want the uncompleted %d and not the completed %d", ids, kept.ID, done.ID)
+	}
+	if ids := claim(time.Hour); slices.Contains(ids, kept.ID) {
+		c.errorf("ClaimCompensations: claimed %d again before retryAfter passed", kept.ID)
+	}
+}
+
+func (c *checker) stream() {
+	start := time.Now().Add(-time.Hour)
+
+	order := c.create(model.OrderStatusPending)
+	if order == nil {
+		return
+	}
+
+	var got []*model.Order
+	err := c.store.StreamOrders(c.ctx, model.OrderFilter{From: start, Status: model.OrderStatusPending}, func(o *model.Order) error {
+		got = append(got, o)
+		return nil
+	})
+	if err != nil {
+		c.errorf("StreamOrders: %v", err)
+		return
+	}
+
+	var found bool
+	for i, o := range got {
+		if o.Status != model.OrderStatusPending {
+			c.errorf("StreamOrders: order %d has status %q despite the filter", o.ID, o.Status)
+		}
+		if i > 0 && o.ID <= got[i-1].ID {
+			c.errorf("StreamOrders: orders are not sorted by ID")
+		}
+		if o.ID == order.ID {
+			found = true
+			if !reflect.DeepEqual(o.Items, order.Items) {
+				c.errorf("StreamOrders: items %+v, want %+v", o.Items, order.Items)
+			}
+		}
+	}
+	if !found {
+		c.errorf("StreamOrders: order %d not streamed", order.ID)
+	}
+
+	sentinel := errors.New("stop")
+	err = c.store.StreamOrders(c.ctx, model.OrderFilter{To: start}, func(o *model.Order) error {
+		if o.UserID == c.userID {
+			return sentinel
+		}
+		return nil
+	})
+	if errors.Is(err, sentinel) {
+		c.errorf("StreamOrders: order created after To was streamed")
+	} else if err != nil {
+		c.errorf("StreamOrders: %v", err)
+	}
+}
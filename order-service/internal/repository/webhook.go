@@ -0,0 +1,259 @@
+// internal/repository/webhook.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"order-service/internal/model"
+)
+
+// WebhookStore — хранилище вебхуков и их доставок. Реализации: WebhookRepository
+// (MySQL и PostgreSQL) и MemoryWebhookStore для юнит-тестов.
+type WebhookStore interface {
+	Create(ctx context.Context, webhook *model.Webhook) error
+	Get(ctx context.Context, id int64) (*model.Webhook, error)
+	ListByUser(ctx context.Context, userID int64, activeOnly bool) ([]model.Webhook, error)
+	Delete(ctx context.Context, userID, id int64) error
+	CreateDelivery(ctx context.Context, d *model.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]model.WebhookDelivery, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, d *model.WebhookDelivery) error
+	Redeliver(ctx context.Context, id int64) error
+}
+
+var (
+	_ WebhookStore = (*WebhookRepository)(nil)
+	_ WebhookStore = (*MemoryWebhookStore)(nil)
+)
+
+type WebhookRepository struct {
+	db *DB
+}
+
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	webhook.CreatedAt = time.Now()
+	var err error
+	webhook.ID, err = r.db.insert(ctx, `
+		INSERT INTO webhooks (user_id, url, secret, events, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		webhook.UserID,
+		webhook.URL,
+		webhook.Secret,
+		strings.Join(webhook.Events, ","),
+		webhook.Active,
+		webhook.CreatedAt,
+	)
+	return err
+}
+
+func (r *WebhookRepository) Get(ctx context.Context, id int64) (*model.Webhook, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT id, user_id, url, secret, events, active, created_at
+		FROM webhooks WHERE id = ?`, id)
+
+	webhook, err := scanWebhook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return webhook, err
+}
+
+// ListByUser returns the user's webhooks; activeOnly skips disabled ones.
+func (r *WebhookRepository) ListByUser(ctx context.Context, userID int64, activeOnly bool) ([]model.Webhook, error) {
+	query := `
+		SELECT id, user_id, url, secret, events, active, created_at
+		FROM webhooks WHERE user_id = ?`
+	if activeOnly {
+		query += " AND active = TRUE"
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []model.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, userID, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, d *model.WebhookDelivery) error {
+	d.CreatedAt = time.Now()
+	d.NextAttemptAt = d.CreatedAt
+	d.Status = model.WebhookDeliveryPending
+
+	var err error
+	d.ID, err = r.db.insert(ctx, `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, 0, ?, ?)
+	`,
+		d.WebhookID,
+		d.EventID,
+		d.EventType,
+		[]byte(d.Payload),
+		d.Status,
+		d.NextAttemptAt,
+		d.CreatedAt,
+	)
+	return err
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id)
+
+	d, err := scanDelivery(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return d, err
+}
+
+// ListDeliveries returns the delivery log of a webhook, newest first.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, webhookID int64, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = ?
+		ORDER BY id DESC
+		LIMIT ?`, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
+
+// ClaimDue picks pending deliveries whose next attempt is due and leases them
+// for lease, so that other replicas skip them while they are being sent.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	now := time.Now()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+deliveryColumns+` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at <= ?
+		ORDER BY next_attempt_at
+		LIMIT ?`, model.WebhookDeliveryPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	due, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := due[:0]
+	for _, d := range due {
+		// Условный UPDATE: выигрывает только одна реплика
+		result, err := r.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries SET next_attempt_at = ?
+			WHERE id = ? AND status = ? AND next_attempt_at = ?`,
+			now.Add(lease), d.ID, model.WebhookDeliveryPending, d.NextAttemptAt)
+		if err != nil {
+			return nil, err
+		}
+		if n, _ := result.RowsAffected(); n == 1 {
+			claimed = append(claimed, d)
+		}
+	}
+	return claimed, nil
+}
+
+// SaveAttempt stores the outcome of a delivery attempt.
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, d *model.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, response_code = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+		WHERE id = ?`,
+		d.Status, d.Attempts, d.ResponseCode, d.LastError, d.NextAttemptAt, d.DeliveredAt, d.ID)
+	return err
+}
+
+// Redeliver puts a delivery back into the queue with a fresh attempt budget.
+func (r *WebhookRepository) Redeliver(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = 0, next_attempt_at = ?
+		WHERE id = ?`, model.WebhookDeliveryPending, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts,
+		COALESCE(response_code, 0), COALESCE(last_error, ''), next_attempt_at, delivered_at, created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row rowScanner) (*model.Webhook, error) {
+	webhook := &model.Webhook{}
+	var events string
+	err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, &events, &webhook.Active, &webhook.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Events = strings.Split(events, ",")
+	return webhook, nil
+}
+
+func scanDelivery(row rowScanner) (*model.WebhookDelivery, error) {
+	d := &model.WebhookDelivery{}
+	var payload []byte
+	var deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.ResponseCode, &d.LastError, &d.NextAttemptAt, &deliveredAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, nil
+}
+
+func scanDeliveries(rows *sql.Rows) ([]model.WebhookDelivery, error) {
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,103 @@
+// internal/service/address.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+var countryRe = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type AddressService struct {
+	repo *repository.AddressRepository
+}
+
+func NewAddressService(repo *repository.AddressRepository) *AddressService {
+	return &AddressService{repo: repo}
+}
+
+func (s *AddressService) Create(ctx context.Context, a *model.Address) error {
+	if err := validateShipping(&a.ShippingAddress); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, a)
+}
+
+func (s *AddressService) Get(ctx context.Context, userID, id int64) (*model.Address, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+func (s *AddressService) List(ctx context.Context, userID int64) ([]model.Address, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *AddressService) Update(ctx context.Context, a *model.Address) error {
+	if err := validateShipping(&a.ShippingAddress); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, a)
+}
+
+func (s *AddressService) Delete(ctx context.Context, userID, id int64) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+// Snapshot resolves the shipping address for a new order: an inline address wins,
+// then the saved address with addressID. Without both the order has no address.
+func (s *AddressService) Snapshot(ctx context.Context, userID, addressID int64, inline *model.ShippingAddress) (*model.ShippingAddress, error) {
+	if inline != nil {
+		if err := validateShipping(inline); err != nil {
+			return nil, err
+		}
+		return inline, nil
+	}
+	if addressID == 0 {
+		return nil, nil
+	}
+
+	a, err := s.repo.Get(ctx, userID, addressID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: address %d not found", ErrInvalidAddress, addressID)
+		}
+		return nil, err
+	}
+	snapshot := a.ShippingAddress
+	return &snapshot, nil
+}
+
+func validateShipping(a *model.ShippingAddress) error {
+	a.Recipient = strings.TrimSpace(a.Recipient)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.City = strings.TrimSpace(a.City)
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+
+	switch {
+	case a.Recipient == "":
+		return fmt.Errorf("%w: recipient is required", ErrInvalidAddress)
+	case a.Line1 == "":
+		return fmt.Errorf("%w: line1 is required", ErrInvalidAddress)
+	case a.City == "":
+		return fmt.Errorf("%w: city is required", ErrInvalidAddress)
+	case a.PostalCode == "":
+		return fmt.Errorf("%w: postal_code is required", ErrInvalidAddress)
+	case !countryRe.MatchString(a.Country):
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code, e.g. RU", ErrInvalidAddress)
+	}
+
+	for _, field := range []string{a.Recipient, a.Phone, a.Line1, a.Line2, a.City, a.Region, a.PostalCode} {
+		if len(field) > 255 {
+			return fmt.Errorf("%w: fields must be at most 255 characters", ErrInvalidAddress)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+// internal/service/errors.go
+package service
+
+import "errors"
+
+var (
+	ErrInvalidOrderState  = errors.New("operation is not allowed in the current order status")
+	ErrPaymentDeclined    = errors.New("payment declined")
+	ErrInvalidOrderItems  = errors.New("order items do not match the product catalog") // нет в каталоге, не продаётся или другая цена
+	ErrCatalogUnavailable = errors.New("product catalog is unavailable, try again later")
+)
@@ -0,0 +1,143 @@
+// internal/service/export.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/export"
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+
+	"contracts/events"
+)
+
+// ErrExportNotReady — файл асинхронной выгрузки ещё не готов.
+var ErrExportNotReady = errors.New("export is not finished yet")
+
+type ExportService struct {
+	orderRepo repository.OrderStore
+	jobs      *repository.ExportJobRepository
+	store     export.Store
+}
+
+func NewExportService(orderRepo repository.OrderStore, jobs *repository.ExportJobRepository, store export.Store) *ExportService {
+	return &ExportService{orderRepo: orderRepo, jobs: jobs, store: store}
+}
+
+// Stream writes matching orders to w in the given format as they are read.
+// It returns the number of exported orders.
+func (s *ExportService) Stream(ctx context.Context, filter model.OrderFilter, format string, w io.Writer) (int64, error) {
+	ew, err := export.NewWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	err = s.orderRepo.StreamOrders(ctx, filter, func(order *model.Order) error {
+		rows++
+		return ew.Write(order)
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, ew.Flush()
+}
+
+// StartJob registers an export job and runs it in the background.
+func (s *ExportService) StartJob(ctx context.Context, requestedBy int64, filter model.OrderFilter, format string) (*model.ExportJob, error) {
+	if _, err := export.NewWriter(format, io.Discard); err != nil {
+		return nil, err
+	}
+
+	job := &model.ExportJob{
+		ID:          events.NewEventID(),
+		Status:      model.ExportJobPending,
+		Format:      format,
+		Filter:      filter,
+		RequestedBy: requestedBy,
+		CreatedAt:   time.Now().UTC(),
+	}
+	job.FileName = fmt.Sprintf("orders-%s.%s", job.ID, format)
+	if err := s.jobs.Save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// Выгрузка переживает HTTP-запрос, но наследует его трейс
+	go s.run(context.WithoutCancel(ctx), *job)
+	return job, nil
+}
+
+func (s *ExportService) GetJob(ctx context.Context, id string) (*model.ExportJob, error) {
+	return s.jobs.Get(ctx, id)
+}
+
+// OpenResult opens the file of a finished job.
+func (s *ExportService) OpenResult(ctx context.Context, id string) (*model.ExportJob, io.ReadCloser, error) {
+	job, err := s.jobs.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != model.ExportJobDone {
+		return job, nil, ErrExportNotReady
+	}
+	f, err := s.store.Open(ctx, job.FileName)
+	if err != nil {
+		return job, nil, err
+	}
+	return job, f, nil
+}
+
+func (s *ExportService) run(ctx context.Context, job model.ExportJob) {
+	logger := utils.NewHelperLogger("order-service.service.export")
+
+	job.Status = model.ExportJobRunning
+	s.saveJob(ctx, &job)
+
+	rows, err := s.writeFile(ctx, &job)
+	now := time.Now().UTC()
+	job.FinishedAt = &now
+	job.Rows = rows
+	if err != nil {
+		job.Status = model.ExportJobFailed
+		job.Error = err.Error()
+		logger.LogError(ctx, "Order export failed", err,
+			log.KeyValue{Key: "job_id", Value: log.StringValue(job.ID)},
+		)
+	} else {
+		job.Status = model.ExportJobDone
+		logger.LogInfo(ctx, "Order export finished",
+			log.KeyValue{Key: "job_id", Value: log.StringValue(job.ID)},
+			log.KeyValue{Key: "rows", Value: log.Int64Value(rows)},
+		)
+	}
+	s.saveJob(ctx, &job)
+}
+
+func (s *ExportService) writeFile(ctx context.Context, job *model.ExportJob) (int64, error) {
+	f, err := s.store.Create(ctx, job.FileName)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.Stream(ctx, job.Filter, job.Format, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return rows, err
+}
+
+func (s *ExportService) saveJob(ctx context.Context, job *model.ExportJob) {
+	if err := s.jobs.Save(ctx, job); err != nil {
+		logger := utils.NewHelperLogger("order-service.service.export")
+		logger.LogError(ctx, "Failed to save export job", err,
+			log.KeyValue{Key: "job_id", Value: log.StringValue(job.ID)},
+		)
+	}
+}
@@ -3,76 +3,256 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 
 	"go.opentelemetry.io/otel/log"
 
+	"order-service/internal/catalog"
 	"order-service/internal/model"
 	"order-service/internal/repository"
 	"order-service/internal/utils"
 
+	"contracts/events"
+	ordersv1 "contracts/gen/orders/v1"
+
 	"github.com/segmentio/kafka-go"
 )
 
+// OrderListener получает события жизненного цикла заказа (вебхуки, стримы статусов).
+type OrderListener interface {
+	OnOrderEvent(ctx context.Context, eventType string, order *model.Order)
+}
+
+// Refunder возвращает деньги по оплаченному заказу: целиком при отмене
+// или частично при возврате товара.
+type Refunder interface {
+	RefundOrder(ctx context.Context, order *model.Order) error
+	RefundAmount(ctx context.Context, order *model.Order, amount int64) error
+}
+
+// ProductCatalog — каталог товаров, с которым сверяются позиции и цены заказа.
+type ProductCatalog interface {
+	Products(ctx context.Context, ids []int64) (map[int64]catalog.Product, error)
+}
+
+// CreateOrderInput — данные нового заказа от клиента.
+type CreateOrderInput struct {
+	Currency  string
+	Items     []model.OrderItem
+	PromoCode string
+	// Адрес доставки: сохранённый адрес пользователя или адрес целиком
+	AddressID int64
+	Shipping  *model.ShippingAddress
+}
+
 type OrderService struct {
-	orderRepo   *repository.OrderRepository
+	orderRepo   repository.OrderStore
+	promotions  *PromotionService
+	addresses   *AddressService
 	kafkaWriter *kafka.Writer
+	listeners   []OrderListener
+	refunder    Refunder
+	catalog     ProductCatalog
 }
 
-type OrderEvent struct {
-	OrderID   int64 `json:"order_id"`
-	UserID    int64 `json:"user_id"`
-	ProductID int64 `json:"product_id"`
-	Quantity  int   `json:"quantity"`
+func NewOrderService(orderRepo repository.OrderStore, promotions *PromotionService, addresses *AddressService, kafkaWriter *kafka.Writer) *OrderService {
+	return &OrderService{orderRepo: orderRepo, promotions: promotions, addresses: addresses, kafkaWriter: kafkaWriter}
 }
 
-func NewOrderService(orderRepo *repository.OrderRepository, kafkaWriter *kafka.Writer) *OrderService {
-	return &OrderService{orderRepo: orderRepo, kafkaWriter: kafkaWriter}
+// AddListener subscribes l to order lifecycle events. Not safe to call after startup.
+func (s *OrderService) AddListener(l OrderListener) {
+	s.listeners = append(s.listeners, l)
 }
 
-func (s *OrderService) CreateOrder(ctx context.Context, userID, productID int64, quantity int) error {
+// SetRefunder wires the payment side in; it is a setter because payments depend on orders.
+func (s *OrderService) SetRefunder(r Refunder) {
+	s.refunder = r
+}
+
+// SetCatalog turns on checking order items against the product catalog; without it
+// any product_id and unit_price are accepted.
+func (s *OrderService) SetCatalog(c ProductCatalog) {
+	s.catalog = c
+}
+
+func (s *OrderService) notify(ctx context.Context, eventType string, order *model.Order) {
+	for _, l := range s.listeners {
+		l.OnOrderEvent(ctx, eventType, order)
+	}
+}
+
+// CreateOrder stores a multi-line order and publishes a single order.created event.
+// Line totals and the order total are calculated here from quantities and unit prices;
+// a non-empty PromoCode reserves one usage of the promotion and reduces the total.
+// The shipping address is copied onto the order as a snapshot.
+func (s *OrderService) CreateOrder(ctx context.Context, userID int64, input CreateOrderInput) (*model.Order, error) {
 	logger := utils.NewHelperLogger("order-service.service.create-order")
 
-	order := &model.Order{
-		UserID:    userID,
-		ProductID: productID,
-		Quantity:  quantity,
-		Status:    "pending",
+	order, promo, err := s.prepareOrder(ctx, userID, input)
+	if err != nil {
+		return nil, err
 	}
 
 	if err := s.orderRepo.Create(ctx, order); err != nil {
+		if promo != nil {
+			s.promotions.ReleaseReservation(context.WithoutCancel(ctx), promo)
+		}
 		logger.LogError(ctx, "Failed to create order in database", err,
 			log.KeyValue{Key: "user_id", Value: log.Int64Value(userID)},
-			log.KeyValue{Key: "product_id", Value: log.Int64Value(productID)},
+			log.KeyValue{Key: "items", Value: log.IntValue(len(order.Items))},
 		)
-		return err
+		return nil, err
 	}
 
 	// Публикуем событие в Kafka
-	event := OrderEvent{
-		OrderID:   order.ID,
-		UserID:    order.UserID,
-		ProductID: order.ProductID,
-		Quantity:  order.Quantity,
-	}
-
-	payload, _ := json.Marshal(event)
-	err := s.kafkaWriter.WriteMessages(ctx, kafka.Message{
-		Topic: "order.created",
-		Value: payload,
-	})
+	msg, err := orderCreatedMessage(order)
+	s.publish(ctx, msg, err, order.ID)
+
+	s.notify(ctx, model.OrderEventCreated, order)
+
+	return order, nil
+}
+
+// prepareOrder builds a pending order from the input: the catalog check, the address
+// snapshot, line totals and the promo code discount. A returned reservation must be
+// attached to the order or released.
+func (s *OrderService) prepareOrder(ctx context.Context, userID int64, input CreateOrderInput) (*model.Order, *PromotionReservation, error) {
+	if err := s.checkCatalog(ctx, input.Currency, input.Items); err != nil {
+		return nil, nil, err
+	}
+
+	shipping, err := s.addresses.Snapshot(ctx, userID, input.AddressID, input.Shipping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order := &model.Order{
+		UserID:   userID,
+		Status:   model.OrderStatusPending,
+		Currency: input.Currency,
+		Shipping: shipping,
+		Items:    input.Items,
+	}
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.LineTotal = item.UnitPrice * int64(item.Quantity)
+		order.TotalAmount += item.LineTotal
+	}
+
+	if input.PromoCode == "" {
+		return order, nil, nil
+	}
+	promo, err := s.promotions.Reserve(ctx, input.PromoCode, userID, order.Currency, order.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+	order.PromoCode = promo.Code
+	order.PromoUsageID = promo.UsageID
+	order.Discount = promo.Discount
+	order.TotalAmount -= promo.Discount
+	return order, promo, nil
+}
+
+// checkCatalog verifies that every item is an active catalog product sold in currency
+// at exactly the unit price the client saw; otherwise the client has to refresh the cart.
+func (s *OrderService) checkCatalog(ctx context.Context, currency string, items []model.OrderItem) error {
+	if s.catalog == nil {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductID)
+	}
+	products, err := s.catalog.Products(ctx, ids)
+	if errors.Is(err, catalog.ErrUnavailable) {
+		logger := utils.NewHelperLogger("order-service.service.check-catalog")
+		logger.LogError(ctx, "Product catalog is unavailable", err,
+			log.KeyValue{Key: "items", Value: log.IntValue(len(items))},
+		)
+		return ErrCatalogUnavailable
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		p, ok := products[item.ProductID]
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: product %d is not in the catalog", ErrInvalidOrderItems, item.ProductID)
+		case !p.Active:
+			return fmt.Errorf("%w: product %d is not for sale", ErrInvalidOrderItems, item.ProductID)
+		case p.Currency != currency:
+			return fmt.Errorf("%w: product %d is sold in %s", ErrInvalidOrderItems, item.ProductID, p.Currency)
+		case p.Price != item.UnitPrice:
+			return fmt.Errorf("%w: unit_price of product %d is %d", ErrInvalidOrderItems, item.ProductID, p.Price)
+		}
+	}
+	return nil
+}
+
+func orderCreatedMessage(order *model.Order) (kafka.Message, error) {
+	event := &ordersv1.OrderCreated{
+		OrderId:     order.ID,
+		UserId:      order.UserID,
+		Currency:    order.Currency,
+		TotalAmount: order.TotalAmount,
+		Items:       eventItems(order.Items),
+	}
+	if order.Shipping != nil {
+		event.ShippingRegion = order.Shipping.Region
+	}
+	return events.NewOrderCreatedMessage(event)
+}
+
+// publish writes an already encoded event; encErr is the encoding error, if any.
+// Failures are logged and do not fail the calling operation.
+func (s *OrderService) publish(ctx context.Context, msg kafka.Message, encErr error, orderID int64) {
+	logger := utils.NewHelperLogger("order-service.service.publish")
+
+	err := encErr
+	if err == nil {
+		err = utils.WriteMessages(ctx, s.kafkaWriter, msg)
+	}
 	if err != nil {
 		logger.LogError(ctx, "Failed to publish to Kafka", err,
-			log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(orderID)},
+			log.KeyValue{Key: "topic", Value: log.StringValue(msg.Topic)},
 		)
 		// Можно добавить retry или dead-letter queue в продакшене
-	} else {
-		logger.LogInfo(ctx, "Order created and published to Kafka",
-			log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
-		)
+		return
 	}
 
-	return nil
+	logger.LogInfo(ctx, "Event published to Kafka",
+		log.KeyValue{Key: "order_id", Value: log.Int64Value(orderID)},
+		log.KeyValue{Key: "topic", Value: log.StringValue(msg.Topic)},
+	)
+}
+
+func eventItems(items []model.OrderItem) []*ordersv1.OrderItem {
+	result := make([]*ordersv1.OrderItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, &ordersv1.OrderItem{
+			ProductId: item.ProductID,
+			Quantity:  int32(item.Quantity),
+			UnitPrice: item.UnitPrice,
+		})
+	}
+	return result
+}
+
+// GetOrder returns the order if it belongs to userID, otherwise repository.ErrNotFound.
+func (s *OrderService) GetOrder(ctx context.Context, userID, orderID int64) (*model.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	return order, nil
 }
 
 func (s *OrderService) ValidateToken(token string) (int64, error) {
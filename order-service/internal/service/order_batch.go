@@ -0,0 +1,150 @@
+// internal/service/order_batch.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// ErrBatchRolledBack — заказ валиден, но не создан, потому что откатилась вся пачка.
+var ErrBatchRolledBack = errors.New("order not created: the batch was rolled back")
+
+// BatchOrderResult — итог по одному заказу пачки: Order при успехе, иначе Err.
+type BatchOrderResult struct {
+	Order *model.Order
+	Err   error
+}
+
+// CreateOrders creates a batch of orders for a B2B client.
+//
+// In atomic mode the orders are stored in a single transaction: if any order is
+// invalid or fails to insert, nothing is created. Otherwise every order is
+// created on its own and failures affect only that order.
+// order.created events for the created orders are published in one Kafka write.
+func (s *OrderService) CreateOrders(ctx context.Context, userID int64, inputs []CreateOrderInput, atomic bool) []BatchOrderResult {
+	logger := utils.NewHelperLogger("order-service.service.create-orders")
+
+	results := make([]BatchOrderResult, len(inputs))
+	promos := make([]*PromotionReservation, len(inputs))
+	for i, input := range inputs {
+		results[i].Order, promos[i], results[i].Err = s.prepareOrder(ctx, userID, input)
+	}
+
+	if atomic {
+		s.createAtomic(ctx, results, promos)
+	} else {
+		for i := range results {
+			if results[i].Err != nil {
+				continue
+			}
+			if err := s.orderRepo.Create(ctx, results[i].Order); err != nil {
+				s.failBatchOrder(ctx, &results[i], promos[i], err)
+			}
+		}
+	}
+
+	var created []*model.Order
+	for _, r := range results {
+		if r.Err == nil {
+			created = append(created, r.Order)
+		}
+	}
+	logger.LogInfo(ctx, "Order batch processed",
+		log.KeyValue{Key: "user_id", Value: log.Int64Value(userID)},
+		log.KeyValue{Key: "orders", Value: log.IntValue(len(inputs))},
+		log.KeyValue{Key: "created", Value: log.IntValue(len(created))},
+		log.KeyValue{Key: "atomic", Value: log.BoolValue(atomic)},
+	)
+
+	s.publishCreated(ctx, created)
+	for _, order := range created {
+		s.notify(ctx, model.OrderEventCreated, order)
+	}
+	return results
+}
+
+func (s *OrderService) createAtomic(ctx context.Context, results []BatchOrderResult, promos []*PromotionReservation) {
+	failed := -1
+	var err error
+	for i, r := range results {
+		if r.Err != nil {
+			failed, err = i, r.Err
+			break
+		}
+	}
+
+	if failed < 0 {
+		orders := make([]*model.Order, len(results))
+		for i, r := range results {
+			orders[i] = r.Order
+		}
+		err = s.orderRepo.CreateBatch(ctx, orders)
+		if err == nil {
+			return
+		}
+		var batchErr *repository.BatchError
+		if errors.As(err, &batchErr) {
+			failed, err = batchErr.Index, batchErr.Err
+		}
+	}
+
+	for i := range results {
+		switch {
+		case i == failed:
+			s.failBatchOrder(ctx, &results[i], promos[i], err)
+		case results[i].Err == nil && failed < 0:
+			// Commit не удался: пачка целиком не записана
+			s.failBatchOrder(ctx, &results[i], promos[i], fmt.Errorf("%w: %v", ErrBatchRolledBack, err))
+		case results[i].Err == nil:
+			s.failBatchOrder(ctx, &results[i], promos[i], ErrBatchRolledBack)
+		}
+	}
+}
+
+// failBatchOrder marks the order as not created and gives its promo code usage back.
+func (s *OrderService) failBatchOrder(ctx context.Context, r *BatchOrderResult, promo *PromotionReservation, err error) {
+	if promo != nil {
+		s.promotions.ReleaseReservation(context.WithoutCancel(ctx), promo)
+	}
+	r.Order = nil
+	r.Err = err
+}
+
+// publishCreated publishes order.created for all orders in a single batched write.
+func (s *OrderService) publishCreated(ctx context.Context, orders []*model.Order) {
+	logger := utils.NewHelperLogger("order-service.service.publish")
+
+	msgs := make([]kafka.Message, 0, len(orders))
+	for _, order := range orders {
+		msg, err := orderCreatedMessage(order)
+		if err != nil {
+			logger.LogError(ctx, "Failed to encode order event", err,
+				log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+			)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	if len(msgs) == 0 {
+		return
+	}
+
+	if err := utils.WriteMessages(ctx, s.kafkaWriter, msgs...); err != nil {
+		logger.LogError(ctx, "Failed to publish order batch to Kafka", err,
+			log.KeyValue{Key: "messages", Value: log.IntValue(len(msgs))},
+		)
+		return
+	}
+	logger.LogInfo(ctx, "Order batch published to Kafka",
+		log.KeyValue{Key: "messages", Value: log.IntValue(len(msgs))},
+	)
+}
@@ -0,0 +1,149 @@
+// internal/service/order_expiry.go
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/utils"
+)
+
+// ExpiryReason — причина отмены заказа, не оплаченного за PendingTTL.
+const ExpiryReason = "payment_timeout"
+
+// ExpiryConfig — параметры фоновой отмены неоплаченных заказов.
+type ExpiryConfig struct {
+	PendingTTL time.Duration
+	Interval   time.Duration
+	BatchSize  int
+}
+
+// OrderExpiryService cancels orders that stayed pending longer than PendingTTL.
+// It runs on every replica; row locking in the repository keeps them from
+// expiring the same order twice.
+type OrderExpiryService struct {
+	orderService *OrderService
+	cfg          ExpiryConfig
+}
+
+func NewOrderExpiryService(orderService *OrderService, cfg ExpiryConfig) *OrderExpiryService {
+	return &OrderExpiryService{orderService: orderService, cfg: cfg}
+}
+
+// compensationRetryAfter — через сколько повторить компенсацию, которая не
+// завершилась: упала с ошибкой или реплика умерла посреди неё.
+const compensationRetryAfter = time.Minute
+
+// Run expires pending orders every Interval until ctx is cancelled, then
+// compensates the expired ones.
+func (s *OrderExpiryService) Run(ctx context.Context) {
+	logger := utils.NewHelperLogger("order-service.service.order-expiry")
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Разбираем очередь пачками, пока она не опустеет
+		for {
+			n, err := s.expireBatch(ctx)
+			if err != nil {
+				logger.LogError(ctx, "Failed to expire pending orders", err)
+				break
+			}
+			if n < s.cfg.BatchSize {
+				break
+			}
+		}
+
+		// Компенсации разбираем и тогда, когда отмена не удалась: в очереди
+		// могут остаться заказы прошлых тиков
+		for {
+			n, err := s.compensateBatch(ctx)
+			if err != nil {
+				logger.LogError(ctx, "Failed to claim order compensations", err)
+				break
+			}
+			if n < s.cfg.BatchSize {
+				break
+			}
+		}
+	}
+}
+
+func (s *OrderExpiryService) expireBatch(ctx context.Context) (int, error) {
+	logger := utils.NewHelperLogger("order-service.service.order-expiry")
+
+	before := time.Now().Add(-s.cfg.PendingTTL)
+	ids, err := s.orderService.orderRepo.ExpirePending(ctx, before, s.cfg.BatchSize, ExpiryReason)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		logger.LogInfo(ctx, "Pending order expired",
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(id)},
+		)
+	}
+	return len(ids), nil
+}
+
+// compensateBatch runs the compensations recorded by ExpirePending. A failed
+// one stays claimed and is retried after compensationRetryAfter.
+func (s *OrderExpiryService) compensateBatch(ctx context.Context) (int, error) {
+	logger := utils.NewHelperLogger("order-service.service.order-expiry")
+
+	ids, err := s.orderService.orderRepo.ClaimCompensations(ctx, s.cfg.BatchSize, compensationRetryAfter)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		if err := s.compensate(ctx, id); err != nil {
+			logger.LogError(ctx, "Failed to compensate expired order", err,
+				log.KeyValue{Key: "order_id", Value: log.Int64Value(id)},
+			)
+		}
+	}
+	return len(ids), nil
+}
+
+// compensate returns the promo code usage and publishes order.cancelled for an
+// expired order. Both steps are safe to repeat, so a retry after a partial
+// failure does no harm.
+func (s *OrderExpiryService) compensate(ctx context.Context, orderID int64) error {
+	order, err := s.orderService.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("load order: %w", err)
+	}
+
+	if order.PromoCode != "" {
+		if err := s.orderService.promotions.ReleaseOrder(ctx, order.ID); err != nil {
+			return fmt.Errorf("release promo code usage: %w", err)
+		}
+	}
+
+	// Ожидающий заказ ещё не оплачен — возвращать деньги не нужно.
+	// Если списание всё же придёт позже, MarkPaid оформит возврат.
+	msg, err := orderCancelledMessage(order, false)
+	if err != nil {
+		return fmt.Errorf("encode order.cancelled: %w", err)
+	}
+	if err := utils.WriteMessages(ctx, s.orderService.kafkaWriter, msg); err != nil {
+		return fmt.Errorf("publish order.cancelled: %w", err)
+	}
+
+	if err := s.orderService.orderRepo.CompleteCompensation(ctx, order.ID); err != nil {
+		return fmt.Errorf("complete compensation: %w", err)
+	}
+	s.orderService.notify(ctx, model.OrderEventCancelled, order)
+	return nil
+}
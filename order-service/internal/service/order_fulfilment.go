@@ -0,0 +1,92 @@
+// internal/service/order_fulfilment.go
+package service
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+
+	"contracts/events"
+	ordersv1 "contracts/gen/orders/v1"
+)
+
+// ShipOrder hands a paid order over to the carrier. Used by warehouse operators.
+func (s *OrderService) ShipOrder(ctx context.Context, orderID int64, carrier, trackingNumber string) (*model.Shipment, error) {
+	logger := utils.NewHelperLogger("order-service.service.fulfilment")
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.CanTransition(order.Status, model.OrderStatusShipped) {
+		return nil, ErrInvalidOrderState
+	}
+
+	shipment := &model.Shipment{Carrier: carrier, TrackingNumber: trackingNumber}
+	if err := s.orderRepo.Ship(ctx, order.ID, shipment); err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			return nil, ErrInvalidOrderState
+		}
+		return nil, err
+	}
+	order.Status = model.OrderStatusShipped
+	order.StatusReason = ""
+
+	logger.LogInfo(ctx, "Order shipped",
+		log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+		log.KeyValue{Key: "carrier", Value: log.StringValue(carrier)},
+	)
+
+	msg, err := events.NewOrderShippedMessage(&ordersv1.OrderShipped{
+		OrderId:        order.ID,
+		UserId:         order.UserID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+	})
+	s.publish(ctx, msg, err, order.ID)
+
+	s.notify(ctx, model.OrderEventShipped, order)
+	return shipment, nil
+}
+
+// DeliverOrder marks a shipped order as delivered. Used by warehouse operators.
+func (s *OrderService) DeliverOrder(ctx context.Context, orderID int64) (*model.Order, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if !model.CanTransition(order.Status, model.OrderStatusDelivered) {
+		return nil, ErrInvalidOrderState
+	}
+
+	if err := s.orderRepo.Deliver(ctx, order.ID); err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			return nil, ErrInvalidOrderState
+		}
+		return nil, err
+	}
+	order.Status = model.OrderStatusDelivered
+	order.StatusReason = ""
+
+	msg, err := events.NewOrderDeliveredMessage(&ordersv1.OrderDelivered{
+		OrderId: order.ID,
+		UserId:  order.UserID,
+	})
+	s.publish(ctx, msg, err, order.ID)
+
+	s.notify(ctx, model.OrderEventDelivered, order)
+	return order, nil
+}
+
+// GetShipment returns the shipment of the user's order.
+func (s *OrderService) GetShipment(ctx context.Context, userID, orderID int64) (*model.Shipment, error) {
+	if _, err := s.GetOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.GetShipment(ctx, orderID)
+}
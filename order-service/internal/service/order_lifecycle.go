@@ -0,0 +1,177 @@
+// internal/service/order_lifecycle.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+
+	"contracts/events"
+	ordersv1 "contracts/gen/orders/v1"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MarkPaid advances a pending order to paid once its payment is captured.
+// If the order was cancelled while the capture was in flight, the money is returned.
+func (s *OrderService) MarkPaid(ctx context.Context, orderID int64) error {
+	logger := utils.NewHelperLogger("order-service.service.order-lifecycle")
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status == model.OrderStatusCancelled {
+		logger.LogWarn(ctx, "Payment captured for a cancelled order, refunding",
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(orderID)},
+		)
+		if s.refunder == nil {
+			return fmt.Errorf("order %d: no refunder configured", orderID)
+		}
+		return s.refunder.RefundOrder(ctx, order)
+	}
+
+	if err := s.transition(ctx, order, model.OrderStatusPaid, ""); err != nil {
+		return err
+	}
+
+	msg, err := events.NewOrderPaidMessage(&ordersv1.OrderPaid{
+		OrderId:  order.ID,
+		UserId:   order.UserID,
+		Currency: order.Currency,
+		Amount:   order.TotalAmount,
+	})
+	s.publish(ctx, msg, err, order.ID)
+
+	s.notify(ctx, model.OrderEventPaid, order)
+	return nil
+}
+
+// CancelOrder cancels the user's order. A paid order is refunded first;
+// if the refund fails the order stays paid.
+func (s *OrderService) CancelOrder(ctx context.Context, userID, orderID int64, reason string) (*model.Order, error) {
+	order, err := s.GetOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cancel(ctx, order, reason); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// RejectOrder cancels an order whose stock inventory-service could not reserve;
+// a paid order is refunded. Orders that are already cancelled or further along
+// are left alone.
+func (s *OrderService) RejectOrder(ctx context.Context, orderID int64, detail string) error {
+	logger := utils.NewHelperLogger("order-service.service.order-lifecycle")
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		logger.LogWarn(ctx, "Stock reservation failed for an unknown order",
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(orderID)},
+		)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if order.Status != model.OrderStatusPending && order.Status != model.OrderStatusPaid {
+		logger.LogWarn(ctx, "Stock reservation failed, order left as is",
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(orderID)},
+			log.KeyValue{Key: "status", Value: log.StringValue(order.Status)},
+		)
+		return nil
+	}
+
+	logger.LogWarn(ctx, "Cancelling order: stock could not be reserved",
+		log.KeyValue{Key: "order_id", Value: log.Int64Value(orderID)},
+		log.KeyValue{Key: "detail", Value: log.StringValue(detail)},
+	)
+	// ErrInvalidOrderState — статус сменился параллельно; повтор перечитает заказ
+	return s.cancel(ctx, order, OutOfStockReason)
+}
+
+func (s *OrderService) cancel(ctx context.Context, order *model.Order, reason string) error {
+	if !model.CanTransition(order.Status, model.OrderStatusCancelled) {
+		return ErrInvalidOrderState
+	}
+
+	refunded := false
+	if order.Status == model.OrderStatusPaid {
+		if s.refunder == nil {
+			return fmt.Errorf("order %d: no refunder configured", order.ID)
+		}
+		if err := s.refunder.RefundOrder(ctx, order); err != nil {
+			return err
+		}
+		refunded = true
+	}
+
+	if err := s.transition(ctx, order, model.OrderStatusCancelled, reason); err != nil {
+		return err
+	}
+
+	s.onCancelled(ctx, order, refunded)
+	return nil
+}
+
+// onCancelled runs the compensations for an already cancelled order: the promo
+// code usage is returned, and the order.cancelled event makes inventory return
+// the deducted stock. Listeners are notified last.
+func (s *OrderService) onCancelled(ctx context.Context, order *model.Order, refunded bool) {
+	if order.PromoCode != "" {
+		if err := s.promotions.ReleaseOrder(ctx, order.ID); err != nil {
+			logger := utils.NewHelperLogger("order-service.service.order-lifecycle")
+			logger.LogError(ctx, "Failed to release promo code usage", err,
+				log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+			)
+		}
+	}
+
+	msg, err := orderCancelledMessage(order, refunded)
+	s.publish(ctx, msg, err, order.ID)
+
+	s.notify(ctx, model.OrderEventCancelled, order)
+}
+
+func orderCancelledMessage(order *model.Order, refunded bool) (kafka.Message, error) {
+	return events.NewOrderCancelledMessage(&ordersv1.OrderCancelled{
+		OrderId:  order.ID,
+		UserId:   order.UserID,
+		Reason:   order.StatusReason,
+		Items:    eventItems(order.Items),
+		Refunded: refunded,
+	})
+}
+
+// History returns the status history of the user's order.
+func (s *OrderService) History(ctx context.Context, userID, orderID int64) ([]model.OrderStatusChange, error) {
+	if _, err := s.GetOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	return s.orderRepo.History(ctx, orderID)
+}
+
+// transition updates the status in the database and on the order itself.
+func (s *OrderService) transition(ctx context.Context, order *model.Order, to, reason string) error {
+	if !model.CanTransition(order.Status, to) {
+		return ErrInvalidOrderState
+	}
+	if err := s.orderRepo.UpdateStatus(ctx, order.ID, order.Status, to, reason); err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			return ErrInvalidOrderState
+		}
+		return err
+	}
+	order.Status = to
+	order.StatusReason = reason
+	return nil
+}
@@ -0,0 +1,244 @@
+// internal/service/payment.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/payment"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+)
+
+type PaymentService struct {
+	paymentRepo  *repository.PaymentRepository
+	orderService *OrderService
+	provider     payment.Provider
+}
+
+func NewPaymentService(paymentRepo *repository.PaymentRepository, orderService *OrderService, provider payment.Provider) *PaymentService {
+	return &PaymentService{paymentRepo: paymentRepo, orderService: orderService, provider: provider}
+}
+
+// Pay authorizes the order total and requests a capture. The order moves to
+// paid only when the provider confirms the capture via HandleNotification.
+// Paying an order that already has a payment in flight returns that payment;
+// if its capture request failed earlier, Pay retries it.
+func (s *PaymentService) Pay(ctx context.Context, userID, orderID int64, paymentMethod string) (*model.Payment, error) {
+	logger := utils.NewHelperLogger("order-service.service.payment")
+
+	order, err := s.orderService.GetOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != model.OrderStatusPending {
+		return nil, ErrInvalidOrderState
+	}
+
+	existing, err := s.paymentRepo.GetActiveByOrder(ctx, orderID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Status == model.PaymentStatusAuthorized {
+			return s.capture(ctx, existing)
+		}
+		return existing, nil
+	}
+
+	// Ключ — номер попытки: повтор той же попытки (параллельный Pay или авторизация,
+	// не дошедшая до базы) провайдер узнает, а оплата после отказа — новая авторизация
+	attempts, err := s.paymentRepo.CountByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	auth, err := s.provider.Authorize(ctx, payment.AuthorizeRequest{
+		OrderID:        order.ID,
+		Amount:         order.TotalAmount,
+		Currency:       order.Currency,
+		PaymentMethod:  paymentMethod,
+		IdempotencyKey: paymentIdempotencyKey(order.ID, attempts+1),
+	})
+	if err != nil {
+		logger.LogError(ctx, "Payment authorization failed", err,
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+		)
+		return nil, err
+	}
+
+	p := &model.Payment{
+		OrderID:           order.ID,
+		Provider:          s.provider.Name(),
+		ProviderPaymentID: auth.ProviderPaymentID,
+		Amount:            order.TotalAmount,
+		Currency:          order.Currency,
+		Status:            model.PaymentStatusAuthorized,
+	}
+	if auth.Status == payment.StatusFailed {
+		p.Status = model.PaymentStatusFailed
+		p.FailureReason = auth.FailureReason
+	}
+	if err := s.paymentRepo.Create(ctx, p); err != nil {
+		if !errors.Is(err, repository.ErrAlreadyExists) {
+			return nil, err
+		}
+		// Параллельный Pay успел создать платёж первым. Нашу авторизацию не
+		// списываем: без capture провайдер снимет холд сам.
+		logger.LogWarn(ctx, "Concurrent payment for the order, dropping the authorization",
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+			log.KeyValue{Key: "provider_payment_id", Value: log.StringValue(p.ProviderPaymentID)},
+		)
+		return s.paymentRepo.GetActiveByOrder(ctx, orderID)
+	}
+	if p.Status == model.PaymentStatusFailed {
+		return p, fmt.Errorf("%w: %s", ErrPaymentDeclined, p.FailureReason)
+	}
+	return s.capture(ctx, p)
+}
+
+// paymentIdempotencyKey identifies the attempt-th authorization of the order.
+func paymentIdempotencyKey(orderID int64, attempt int) string {
+	return "order-" + strconv.FormatInt(orderID, 10) + "-attempt-" + strconv.Itoa(attempt)
+}
+
+// capture requests the capture of an authorized payment. If the request fails the
+// payment stays authorized and the next Pay retries the capture.
+func (s *PaymentService) capture(ctx context.Context, p *model.Payment) (*model.Payment, error) {
+	logger := utils.NewHelperLogger("order-service.service.payment")
+
+	if _, err := s.provider.Capture(ctx, p.ProviderPaymentID, p.Amount); err != nil {
+		logger.LogError(ctx, "Payment capture request failed", err,
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(p.OrderID)},
+			log.KeyValue{Key: "payment_id", Value: log.Int64Value(p.ID)},
+		)
+		return nil, err
+	}
+	if err := s.paymentRepo.UpdateStatus(ctx, p.ID, model.PaymentStatusAuthorized, model.PaymentStatusCapturePending, ""); err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			// Платёж уже продвинул параллельный Pay или уведомление провайдера
+			return s.paymentRepo.GetByProviderID(ctx, p.Provider, p.ProviderPaymentID)
+		}
+		return nil, err
+	}
+	p.Status = model.PaymentStatusCapturePending
+
+	logger.LogInfo(ctx, "Payment capture requested",
+		log.KeyValue{Key: "order_id", Value: log.Int64Value(p.OrderID)},
+		log.KeyValue{Key: "payment_id", Value: log.Int64Value(p.ID)},
+	)
+	return p, nil
+}
+
+// HandleNotification applies an asynchronous provider notification.
+// Notifications may be repeated; already applied ones are ignored, except that a
+// repeated capture finishes an order left pending by a failed MarkPaid.
+func (s *PaymentService) HandleNotification(ctx context.Context, n payment.Notification) error {
+	logger := utils.NewHelperLogger("order-service.service.payment")
+
+	p, err := s.paymentRepo.GetByProviderID(ctx, s.provider.Name(), n.ProviderPaymentID)
+	if err != nil {
+		return err
+	}
+
+	var from, to string
+	switch n.Type {
+	case payment.NotificationCaptured:
+		from, to = model.PaymentStatusCapturePending, model.PaymentStatusCaptured
+	case payment.NotificationFailed:
+		from, to = model.PaymentStatusCapturePending, model.PaymentStatusFailed
+	case payment.NotificationRefunded:
+		// Наши возвраты учитываются синхронно в refund; уведомление лишь
+		// подтверждает их. Частичный возврат статус платежа не меняет.
+		if n.Amount < p.Amount {
+			return nil
+		}
+		from, to = model.PaymentStatusCaptured, model.PaymentStatusRefunded
+	default:
+		return fmt.Errorf("unknown payment notification type %q", n.Type)
+	}
+
+	if err := s.paymentRepo.UpdateStatus(ctx, p.ID, from, to, n.FailureReason); err != nil {
+		if !errors.Is(err, repository.ErrStatusConflict) {
+			return err
+		}
+		// Уже обработано. Но если в прошлый раз платёж сохранился как captured, а
+		// MarkPaid упал, заказ остался pending (и его отменил бы таймаут оплаты) или
+		// отменён без возврата — провайдер повторяет уведомление, доводим заказ сейчас.
+		if to == model.PaymentStatusCaptured && p.Status == model.PaymentStatusCaptured {
+			return s.finishCaptured(ctx, p)
+		}
+		return nil
+	}
+
+	logger.LogInfo(ctx, "Payment notification applied",
+		log.KeyValue{Key: "order_id", Value: log.Int64Value(p.OrderID)},
+		log.KeyValue{Key: "type", Value: log.StringValue(n.Type)},
+	)
+
+	if to == model.PaymentStatusCaptured {
+		return s.orderService.MarkPaid(ctx, p.OrderID)
+	}
+	return nil
+}
+
+// finishCaptured re-runs MarkPaid for a captured payment whose order was not moved on.
+func (s *PaymentService) finishCaptured(ctx context.Context, p *model.Payment) error {
+	order, err := s.orderService.orderRepo.GetByID(ctx, p.OrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != model.OrderStatusPending && order.Status != model.OrderStatusCancelled {
+		return nil
+	}
+
+	logger := utils.NewHelperLogger("order-service.service.payment")
+	logger.LogWarn(ctx, "Captured payment of an unfinished order, retrying MarkPaid",
+		log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+		log.KeyValue{Key: "order_status", Value: log.StringValue(order.Status)},
+	)
+	return s.orderService.MarkPaid(ctx, order.ID)
+}
+
+// RefundOrder refunds whatever is left of the order's captured payment. It implements Refunder.
+func (s *PaymentService) RefundOrder(ctx context.Context, order *model.Order) error {
+	p, err := s.paymentRepo.GetActiveByOrder(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if p.Status == model.PaymentStatusRefunded {
+		return nil
+	}
+	return s.refund(ctx, p, p.Amount-p.RefundedAmount)
+}
+
+// RefundAmount refunds part of the order's captured payment, e.g. for returned items.
+// It implements Refunder.
+func (s *PaymentService) RefundAmount(ctx context.Context, order *model.Order, amount int64) error {
+	p, err := s.paymentRepo.GetActiveByOrder(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if amount > p.Amount-p.RefundedAmount {
+		return fmt.Errorf("refund of %d exceeds the refundable amount %d of payment %d", amount, p.Amount-p.RefundedAmount, p.ID)
+	}
+	return s.refund(ctx, p, amount)
+}
+
+func (s *PaymentService) refund(ctx context.Context, p *model.Payment, amount int64) error {
+	if p.Status != model.PaymentStatusCaptured {
+		return fmt.Errorf("payment %d is %s, nothing to refund", p.ID, p.Status)
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	if _, err := s.provider.Refund(ctx, p.ProviderPaymentID, amount); err != nil {
+		return err
+	}
+	return s.paymentRepo.AddRefund(ctx, p.ID, amount)
+}
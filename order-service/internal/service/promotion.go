@@ -0,0 +1,180 @@
+// internal/service/promotion.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+)
+
+var (
+	ErrInvalidPromotion       = errors.New("invalid promotion")
+	ErrPromotionNotApplicable = errors.New("promo code cannot be applied")
+)
+
+var promoCodeRe = regexp.MustCompile(`^[A-Z0-9_-]{3,64}$`)
+
+// PromotionReservation — зарезервированное использование промокода под создаваемый заказ.
+type PromotionReservation struct {
+	UsageID  int64
+	Code     string
+	Discount int64
+}
+
+type PromotionService struct {
+	repo *repository.PromotionRepository
+}
+
+func NewPromotionService(repo *repository.PromotionRepository) *PromotionService {
+	return &PromotionService{repo: repo}
+}
+
+func (s *PromotionService) Create(ctx context.Context, p *model.Promotion) error {
+	if err := validatePromotion(p); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, p)
+}
+
+func (s *PromotionService) Get(ctx context.Context, id int64) (*model.Promotion, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *PromotionService) List(ctx context.Context) ([]model.Promotion, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *PromotionService) Update(ctx context.Context, p *model.Promotion) error {
+	if err := validatePromotion(p); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, p)
+}
+
+func (s *PromotionService) Delete(ctx context.Context, id int64) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Reserve checks the promo code against the order and takes one usage of it.
+// The caller must attach the reservation to the order or release it.
+func (s *PromotionService) Reserve(ctx context.Context, code string, userID int64, currency string, items []model.OrderItem) (*PromotionReservation, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	var discount int64
+	now := time.Now()
+	_, usageID, err := s.repo.Reserve(ctx, code, userID, func(p *model.Promotion, userUses int) error {
+		if !p.ActiveAt(now) {
+			return fmt.Errorf("%w: promo code is not active", ErrPromotionNotApplicable)
+		}
+		if p.MaxUses > 0 && p.UsedCount >= p.MaxUses {
+			return fmt.Errorf("%w: promo code usage limit reached", ErrPromotionNotApplicable)
+		}
+		if p.MaxUsesPerUser > 0 && userUses >= p.MaxUsesPerUser {
+			return fmt.Errorf("%w: promo code was already used", ErrPromotionNotApplicable)
+		}
+
+		var err error
+		discount, err = calculateDiscount(p, currency, items)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown promo code", ErrPromotionNotApplicable)
+		}
+		return nil, err
+	}
+
+	return &PromotionReservation{UsageID: usageID, Code: code, Discount: discount}, nil
+}
+
+// ReleaseReservation returns a usage whose order was never created.
+func (s *PromotionService) ReleaseReservation(ctx context.Context, r *PromotionReservation) {
+	if err := s.repo.ReleaseUsage(ctx, r.UsageID); err != nil {
+		logger := utils.NewHelperLogger("order-service.service.promotions")
+		logger.LogError(ctx, "Failed to release promo code reservation", err,
+			log.KeyValue{Key: "usage_id", Value: log.Int64Value(r.UsageID)},
+		)
+	}
+}
+
+// ReleaseOrder returns the usage held by a cancelled order.
+func (s *PromotionService) ReleaseOrder(ctx context.Context, orderID int64) error {
+	return s.repo.ReleaseOrder(ctx, orderID)
+}
+
+// calculateDiscount returns the discount for the order lines covered by the promotion.
+// The discount never exceeds the sum of those lines.
+func calculateDiscount(p *model.Promotion, currency string, items []model.OrderItem) (int64, error) {
+	if p.Currency != "" && p.Currency != currency {
+		return 0, fmt.Errorf("%w: promo code is valid for %s orders only", ErrPromotionNotApplicable, p.Currency)
+	}
+
+	var subtotal, eligible int64
+	for _, item := range items {
+		subtotal += item.LineTotal
+		if p.AppliesTo(item.ProductID) {
+			eligible += item.LineTotal
+		}
+	}
+	if subtotal < p.MinOrderAmount {
+		return 0, fmt.Errorf("%w: order total is below the promo code minimum", ErrPromotionNotApplicable)
+	}
+	if eligible == 0 {
+		return 0, fmt.Errorf("%w: no items in the order qualify for the promo code", ErrPromotionNotApplicable)
+	}
+
+	switch p.Type {
+	case model.PromotionPercentage:
+		return eligible * p.Value / 100, nil
+	default:
+		return min(p.Value, eligible), nil
+	}
+}
+
+func validatePromotion(p *model.Promotion) error {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if !promoCodeRe.MatchString(p.Code) {
+		return fmt.Errorf("%w: code must be 3-64 characters A-Z, 0-9, '_' or '-'", ErrInvalidPromotion)
+	}
+
+	switch p.Type {
+	case model.PromotionPercentage:
+		if p.Value < 1 || p.Value > 100 {
+			return fmt.Errorf("%w: percentage value must be between 1 and 100", ErrInvalidPromotion)
+		}
+	case model.PromotionFixed:
+		if p.Value <= 0 {
+			return fmt.Errorf("%w: fixed value must be positive", ErrInvalidPromotion)
+		}
+		if p.Currency == "" {
+			return fmt.Errorf("%w: currency is required for fixed discounts", ErrInvalidPromotion)
+		}
+	default:
+		return fmt.Errorf("%w: type must be %q or %q", ErrInvalidPromotion, model.PromotionPercentage, model.PromotionFixed)
+	}
+
+	if p.MinOrderAmount < 0 || p.MaxUses < 0 || p.MaxUsesPerUser < 0 {
+		return fmt.Errorf("%w: limits must not be negative", ErrInvalidPromotion)
+	}
+	if p.MinOrderAmount > 0 && p.Currency == "" {
+		return fmt.Errorf("%w: currency is required with min_order_amount", ErrInvalidPromotion)
+	}
+	if p.StartsAt != nil && p.EndsAt != nil && !p.EndsAt.After(*p.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidPromotion)
+	}
+	for _, id := range p.ProductIDs {
+		if id <= 0 {
+			return fmt.Errorf("%w: product_ids must be positive", ErrInvalidPromotion)
+		}
+	}
+	return nil
+}
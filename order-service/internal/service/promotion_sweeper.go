@@ -0,0 +1,67 @@
+// internal/service/promotion_sweeper.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+)
+
+// promotionSweepBatch — сколько брошенных использований снимается за транзакцию.
+const promotionSweepBatch = 100
+
+// PromotionSweepConfig — параметры возврата брошенных использований промокодов.
+type PromotionSweepConfig struct {
+	// OrphanTTL — сколько использование может оставаться без заказа. Заказ
+	// создаётся сразу после резервирования, так что запас нужен только на сбои.
+	OrphanTTL time.Duration
+	Interval  time.Duration
+}
+
+// PromotionSweeper returns promo code usages that were reserved for an order
+// which was never created. The usage is committed before the order insert, so
+// a failed insert or a replica dying in between would otherwise hold the
+// usage forever.
+type PromotionSweeper struct {
+	repo *repository.PromotionRepository
+	cfg  PromotionSweepConfig
+}
+
+func NewPromotionSweeper(repo *repository.PromotionRepository, cfg PromotionSweepConfig) *PromotionSweeper {
+	return &PromotionSweeper{repo: repo, cfg: cfg}
+}
+
+// Run releases orphaned usages every Interval until ctx is cancelled.
+func (s *PromotionSweeper) Run(ctx context.Context) {
+	logger := utils.NewHelperLogger("order-service.service.promotion-sweeper")
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			n, err := s.repo.ReleaseOrphaned(ctx, time.Now().Add(-s.cfg.OrphanTTL), promotionSweepBatch)
+			if err != nil {
+				logger.LogError(ctx, "Failed to release orphaned promo code usages", err)
+				break
+			}
+			if n > 0 {
+				logger.LogWarn(ctx, "Released orphaned promo code usages",
+					log.KeyValue{Key: "count", Value: log.IntValue(n)},
+				)
+			}
+			if n < promotionSweepBatch {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,255 @@
+// internal/service/return.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+
+	"contracts/events"
+	ordersv1 "contracts/gen/orders/v1"
+)
+
+var ErrInvalidReturn = errors.New("invalid return")
+
+// ErrInvalidReturnState — шаг возврата не подходит к его текущему статусу.
+var ErrInvalidReturnState = errors.New("operation is not allowed in the current return status")
+
+// ReturnService ведёт возвраты (RMA): заявка покупателя, решение оператора,
+// приёмка на склад (inventory возвращает товар в остатки) и возврат денег.
+type ReturnService struct {
+	repo         *repository.ReturnRepository
+	orderService *OrderService
+}
+
+func NewReturnService(repo *repository.ReturnRepository, orderService *OrderService) *ReturnService {
+	return &ReturnService{repo: repo, orderService: orderService}
+}
+
+// RequestReturn opens a return for some units of a delivered order.
+// The refund is the price paid for those units, including their share of the order discount.
+func (s *ReturnService) RequestReturn(ctx context.Context, userID, orderID int64, items []model.ReturnItem) (*model.Return, error) {
+	order, err := s.orderService.GetOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != model.OrderStatusDelivered {
+		return nil, ErrInvalidOrderState
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: items must not be empty", ErrInvalidReturn)
+	}
+
+	ordered := make(map[int64]model.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		ordered[item.ProductID] = item
+	}
+
+	ret := &model.Return{OrderID: order.ID, UserID: userID}
+	seen := make(map[int64]bool, len(items))
+	for _, item := range items {
+		line, ok := ordered[item.ProductID]
+		if !ok {
+			return nil, fmt.Errorf("%w: product %d is not in the order", ErrInvalidReturn, item.ProductID)
+		}
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("%w: duplicate product_id %d", ErrInvalidReturn, item.ProductID)
+		}
+		seen[item.ProductID] = true
+		if item.Quantity <= 0 || item.Quantity > line.Quantity {
+			return nil, fmt.Errorf("%w: quantity of product %d must be between 1 and %d", ErrInvalidReturn, item.ProductID, line.Quantity)
+		}
+
+		item.RefundAmount = refundFor(order, line, item.Quantity)
+		ret.RefundAmount += item.RefundAmount
+		ret.Items = append(ret.Items, item)
+	}
+
+	err = s.repo.Create(ctx, ret, func(claimed map[int64]int) error {
+		for _, item := range ret.Items {
+			if left := ordered[item.ProductID].Quantity - claimed[item.ProductID]; item.Quantity > left {
+				return fmt.Errorf("%w: only %d unit(s) of product %d can still be returned", ErrInvalidReturn, left, item.ProductID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// List returns the returns of the user's order.
+func (s *ReturnService) List(ctx context.Context, userID, orderID int64) ([]model.Return, error) {
+	if _, err := s.orderService.GetOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByOrder(ctx, orderID)
+}
+
+func (s *ReturnService) Get(ctx context.Context, id int64) (*model.Return, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Approve lets the customer send the goods back.
+func (s *ReturnService) Approve(ctx context.Context, id int64, note string) (*model.Return, error) {
+	return s.decide(ctx, id, model.ReturnStatusApproved, note)
+}
+
+// Reject closes the return without a refund.
+func (s *ReturnService) Reject(ctx context.Context, id int64, note string) (*model.Return, error) {
+	return s.decide(ctx, id, model.ReturnStatusRejected, note)
+}
+
+func (s *ReturnService) decide(ctx context.Context, id int64, to, note string) (*model.Return, error) {
+	ret, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ret.Note = note
+	if err := s.updateStatus(ctx, ret, model.ReturnStatusRequested, to); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Receive records that the goods arrived at the warehouse, tells inventory to put
+// them back into stock (or into quarantine) and refunds the customer.
+// If the refund fails the return stays received and can be refunded again with Refund.
+func (s *ReturnService) Receive(ctx context.Context, id int64, quarantine bool, note string) (*model.Return, error) {
+	ret, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ret.Quarantine = quarantine
+	ret.Note = note
+	if err := s.updateStatus(ctx, ret, model.ReturnStatusApproved, model.ReturnStatusReceived); err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderService.orderRepo.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*ordersv1.OrderItem, 0, len(ret.Items))
+	for _, item := range ret.Items {
+		items = append(items, &ordersv1.OrderItem{ProductId: item.ProductID, Quantity: int32(item.Quantity)})
+	}
+	msg, err := events.NewOrderReturnReceivedMessage(&ordersv1.OrderReturnReceived{
+		ReturnId:   ret.ID,
+		OrderId:    ret.OrderID,
+		UserId:     ret.UserID,
+		Items:      items,
+		Quarantine: quarantine,
+	})
+	s.orderService.publish(ctx, msg, err, ret.OrderID)
+
+	if err := s.refund(ctx, ret, order); err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
+
+// Refund retries the refund of a received return.
+func (s *ReturnService) Refund(ctx context.Context, id int64) (*model.Return, error) {
+	ret, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orderService.orderRepo.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refund(ctx, ret, order); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// refund marks the return refunded before calling the provider, so a retry
+// cannot pay twice; if the provider fails, the status is rolled back.
+func (s *ReturnService) refund(ctx context.Context, ret *model.Return, order *model.Order) error {
+	logger := utils.NewHelperLogger("order-service.service.returns")
+
+	if s.orderService.refunder == nil {
+		return fmt.Errorf("return %d: no refunder configured", ret.ID)
+	}
+	if err := s.updateStatus(ctx, ret, model.ReturnStatusReceived, model.ReturnStatusRefunded); err != nil {
+		return err
+	}
+
+	if err := s.orderService.refunder.RefundAmount(ctx, order, ret.RefundAmount); err != nil {
+		logger.LogError(ctx, "Return refund failed", err,
+			log.KeyValue{Key: "return_id", Value: log.Int64Value(ret.ID)},
+		)
+		ret.Note = "refund failed, retry with /refund"
+		if rollbackErr := s.repo.UpdateStatus(context.WithoutCancel(ctx), ret, model.ReturnStatusRefunded, model.ReturnStatusReceived); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	logger.LogInfo(ctx, "Return refunded",
+		log.KeyValue{Key: "return_id", Value: log.Int64Value(ret.ID)},
+		log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+		log.KeyValue{Key: "amount", Value: log.Int64Value(ret.RefundAmount)},
+	)
+	return s.closeOrderIfReturned(ctx, order)
+}
+
+// closeOrderIfReturned moves the order to returned once every unit is refunded.
+func (s *ReturnService) closeOrderIfReturned(ctx context.Context, order *model.Order) error {
+	refunded, err := s.repo.RefundedQuantities(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	for _, item := range order.Items {
+		if refunded[item.ProductID] < item.Quantity {
+			return nil
+		}
+	}
+
+	if err := s.orderService.transition(ctx, order, model.OrderStatusReturned, "all items returned"); err != nil {
+		if errors.Is(err, ErrInvalidOrderState) {
+			return nil // уже закрыт параллельным возвратом
+		}
+		return err
+	}
+	s.orderService.notify(ctx, model.OrderEventReturned, order)
+	return nil
+}
+
+func (s *ReturnService) updateStatus(ctx context.Context, ret *model.Return, from, to string) error {
+	if ret.Status != from {
+		return ErrInvalidReturnState
+	}
+	if err := s.repo.UpdateStatus(ctx, ret, from, to); err != nil {
+		if errors.Is(err, repository.ErrStatusConflict) {
+			return ErrInvalidReturnState
+		}
+		return err
+	}
+	return nil
+}
+
+// refundFor returns the amount paid for quantity units of the line: the line
+// price minus its proportional share of the order discount. Rounding is down,
+// so partial refunds never add up to more than the order total.
+func refundFor(order *model.Order, line model.OrderItem, quantity int) int64 {
+	amount := line.UnitPrice * int64(quantity)
+	subtotal := order.TotalAmount + order.Discount
+	if order.Discount == 0 || subtotal == 0 {
+		return amount
+	}
+	// amount * total может не поместиться в int64
+	r := new(big.Int).Mul(big.NewInt(amount), big.NewInt(order.TotalAmount))
+	return r.Quo(r, big.NewInt(subtotal)).Int64()
+}
@@ -0,0 +1,207 @@
+// internal/service/status_stream.go
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+)
+
+// statusSubscriptionBuffer — сколько live-событий ждут медленного подписчика;
+// переполнение не теряет события, подписчик дочитает их из стрима.
+const statusSubscriptionBuffer = 16
+
+// StatusStreamService публикует изменения статусов заказов для live-подписчиков.
+// На под приходится одна подписка Redis (Run), события раздаются локальным
+// подписчикам в памяти; стрим заказа читается только чтобы дочитать пропущенное.
+type StatusStreamService struct {
+	repo *repository.StatusStreamRepository
+
+	mu   sync.Mutex
+	subs map[int64]map[*StatusSubscription]struct{}
+}
+
+func NewStatusStreamService(repo *repository.StatusStreamRepository) *StatusStreamService {
+	return &StatusStreamService{repo: repo, subs: make(map[int64]map[*StatusSubscription]struct{})}
+}
+
+func (s *StatusStreamService) OnOrderEvent(ctx context.Context, eventType string, order *model.Order) {
+	event := &model.OrderStatusEvent{
+		Type:      eventType,
+		OrderID:   order.ID,
+		Status:    order.Status,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.Append(ctx, event); err != nil {
+		logger := utils.NewHelperLogger("order-service.service.status-stream")
+		logger.LogError(ctx, "Failed to publish order status event", err,
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+		)
+	}
+}
+
+// Run keeps the pod's Redis subscription and fans events out to local
+// subscribers until ctx is cancelled. A dropped subscription is re-established.
+func (s *StatusStreamService) Run(ctx context.Context) {
+	logger := utils.NewHelperLogger("order-service.service.status-stream")
+
+	for {
+		err := s.repo.Listen(ctx, s.dispatch)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.LogError(ctx, "Order status subscription failed", err)
+
+		// Подписчики могли пропустить события, пока подписки не было
+		s.markAllStale()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Cursor returns the position a new subscriber without Last-Event-ID starts from.
+func (s *StatusStreamService) Cursor(ctx context.Context, orderID int64) (string, error) {
+	return s.repo.LastID(ctx, orderID)
+}
+
+// Subscribe registers a live subscriber for the order's events. The caller
+// must Close the subscription.
+func (s *StatusStreamService) Subscribe(orderID int64) *StatusSubscription {
+	sub := &StatusSubscription{
+		service: s,
+		orderID: orderID,
+		events:  make(chan model.OrderStatusEvent, statusSubscriptionBuffer),
+	}
+	// Сначала дочитываем стрим: события до подписки в канал уже не попадут
+	sub.stale.Store(true)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subs[orderID] == nil {
+		s.subs[orderID] = make(map[*StatusSubscription]struct{})
+	}
+	s.subs[orderID][sub] = struct{}{}
+	return sub
+}
+
+func (s *StatusStreamService) unsubscribe(sub *StatusSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subs[sub.orderID], sub)
+	if len(s.subs[sub.orderID]) == 0 {
+		delete(s.subs, sub.orderID)
+	}
+}
+
+func (s *StatusStreamService) dispatch(event model.OrderStatusEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subs[event.OrderID] {
+		select {
+		case sub.events <- event:
+		default:
+			// Подписчик не успевает: следующий Next дочитает стрим
+			sub.stale.Store(true)
+		}
+	}
+}
+
+func (s *StatusStreamService) markAllStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subs := range s.subs {
+		for sub := range subs {
+			sub.stale.Store(true)
+		}
+	}
+}
+
+// StatusSubscription — live-подписка одного SSE-соединения на события заказа.
+type StatusSubscription struct {
+	service *StatusStreamService
+	orderID int64
+	events  chan model.OrderStatusEvent
+	// stale — в канал могли не попасть события: их надо дочитать из стрима
+	stale atomic.Bool
+}
+
+// Next waits up to wait for events after cursor. An empty result means the
+// wait timed out. After a timeout the stream is checked once more, so an event
+// lost by Pub/Sub arrives with the next call at the latest.
+func (sub *StatusSubscription) Next(ctx context.Context, cursor string, wait time.Duration) ([]model.OrderStatusEvent, error) {
+	if sub.stale.Swap(false) {
+		events, err := sub.service.repo.Read(ctx, sub.orderID, cursor)
+		if err != nil {
+			sub.stale.Store(true)
+			return nil, err
+		}
+		if len(events) > 0 {
+			// Пачка могла быть не последней: следующий вызов дочитает остаток
+			sub.stale.Store(true)
+			return events, nil
+		}
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			sub.stale.Store(true)
+			return nil, nil
+		case event := <-sub.events:
+			// Событие могло уже прийти из стрима
+			if !streamIDAfter(event.ID, cursor) {
+				continue
+			}
+			events := []model.OrderStatusEvent{event}
+			for len(sub.events) > 0 {
+				if next := <-sub.events; streamIDAfter(next.ID, events[len(events)-1].ID) {
+					events = append(events, next)
+				}
+			}
+			return events, nil
+		}
+	}
+}
+
+// Close unregisters the subscription.
+func (sub *StatusSubscription) Close() {
+	sub.service.unsubscribe(sub)
+}
+
+// streamIDAfter reports whether Redis Stream ID a ("ms-seq") is newer than b.
+func streamIDAfter(a, b string) bool {
+	aMs, aSeq := parseStreamID(a)
+	bMs, bSeq := parseStreamID(b)
+	if aMs != bMs {
+		return aMs > bMs
+	}
+	return aSeq > bSeq
+}
+
+func parseStreamID(id string) (ms, seq uint64) {
+	msPart, seqPart, _ := strings.Cut(id, "-")
+	ms, _ = strconv.ParseUint(msPart, 10, 64)
+	seq, _ = strconv.ParseUint(seqPart, 10, 64)
+	return ms, seq
+}
@@ -0,0 +1,95 @@
+// internal/service/stock_rejection.go
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/utils"
+
+	"contracts/events"
+)
+
+// OutOfStockReason — причина отмены заказа, товар которого inventory-service не смог удержать.
+const OutOfStockReason = "out_of_stock"
+
+const maxRejectionBackoff = time.Minute
+
+// ReservationFailedConsumer cancels orders from inventory.reservation_failed. The
+// offset is committed after the order is cancelled, so a failure means the event
+// is delivered again; a repeated event finds the order already cancelled.
+type ReservationFailedConsumer struct {
+	reader       *kafka.Reader
+	orderService *OrderService
+}
+
+func NewReservationFailedConsumer(reader *kafka.Reader, orderService *OrderService) *ReservationFailedConsumer {
+	return &ReservationFailedConsumer{reader: reader, orderService: orderService}
+}
+
+// Run handles events until ctx is cancelled. An event that fails is retried with
+// a growing pause: the next ones wait, as each of them needs the same database.
+func (c *ReservationFailedConsumer) Run(ctx context.Context) {
+	logger := utils.NewHelperLogger("order-service.service.stock-rejection")
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			logger.LogError(ctx, "Failed to read reservation_failed event", err)
+			if !sleepCtx(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		for n := 1; ; n++ {
+			err := c.handle(ctx, msg)
+			if err == nil {
+				break
+			}
+			logger.LogError(ctx, "Failed to cancel order after failed stock reservation", err,
+				log.KeyValue{Key: "partition", Value: log.IntValue(msg.Partition)},
+				log.KeyValue{Key: "offset", Value: log.Int64Value(msg.Offset)},
+			)
+			if !sleepCtx(ctx, min(time.Second<<min(n, 6), maxRejectionBackoff)) {
+				return
+			}
+		}
+		if err := c.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			logger.LogError(ctx, "Failed to commit reservation_failed offset", err)
+		}
+	}
+}
+
+func (c *ReservationFailedConsumer) handle(ctx context.Context, msg kafka.Message) error {
+	event, err := events.DecodeReservationFailed(msg)
+	if err != nil {
+		// Сообщение не прочитать и при повторе — пропускаем, чтобы не встала партиция
+		logger := utils.NewHelperLogger("order-service.service.stock-rejection")
+		logger.LogError(ctx, "Skipping unreadable reservation_failed event", err,
+			log.KeyValue{Key: "offset", Value: log.Int64Value(msg.Offset)},
+		)
+		return nil
+	}
+	return c.orderService.RejectOrder(ctx, event.GetOrderId(), event.GetReason())
+}
+
+// sleepCtx waits for d and reports false if ctx was cancelled first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
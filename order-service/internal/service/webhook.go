@@ -0,0 +1,308 @@
+// internal/service/webhook.go
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+
+	"contracts/events"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+// WebhookConfig — параметры доставки вебхуков.
+type WebhookConfig struct {
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	Timeout      time.Duration
+	// AllowPrivateHosts разрешает вебхуки на loopback и внутренние адреса — только для локальной разработки.
+	AllowPrivateHosts bool
+}
+
+type WebhookService struct {
+	repo   repository.WebhookStore
+	client *http.Client
+	cfg    WebhookConfig
+}
+
+func NewWebhookService(repo repository.WebhookStore, cfg WebhookConfig) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		client: newWebhookClient(cfg.Timeout, cfg.AllowPrivateHosts),
+		cfg:    cfg,
+	}
+}
+
+// webhookPayload — тело запроса, которое получает мерчант.
+type webhookPayload struct {
+	ID        string       `json:"id"`
+	Type      string       `json:"type"`
+	CreatedAt time.Time    `json:"created_at"`
+	Data      *model.Order `json:"data"`
+}
+
+// Register creates a webhook with a freshly generated signing secret. URLs that
+// point into the service network (loopback, private, link-local) are rejected.
+func (s *WebhookService) Register(ctx context.Context, userID int64, rawURL string, eventTypes []string) (*model.Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) URL", ErrInvalidWebhook)
+	}
+	if !s.cfg.AllowPrivateHosts {
+		if err := checkWebhookHost(ctx, u.Hostname()); err != nil {
+			return nil, fmt.Errorf("%w: url host %s: %v", ErrInvalidWebhook, u.Hostname(), err)
+		}
+	}
+	if len(eventTypes) == 0 {
+		return nil, fmt.Errorf("%w: events must not be empty", ErrInvalidWebhook)
+	}
+	for _, e := range eventTypes {
+		if e != "*" && !slices.Contains(model.OrderEventTypes, e) {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+	}
+
+	webhook := &model.Webhook{
+		UserID: userID,
+		URL:    u.String(),
+		Secret: newWebhookSecret(),
+		Events: eventTypes,
+		Active: true,
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) List(ctx context.Context, userID int64) ([]model.Webhook, error) {
+	webhooks, err := s.repo.ListByUser(ctx, userID, false)
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, err
+}
+
+func (s *WebhookService) Delete(ctx context.Context, userID, id int64) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, userID, webhookID int64, limit int) ([]model.WebhookDelivery, error) {
+	if _, err := s.ownedWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, webhookID, limit)
+}
+
+// Redeliver queues a past delivery again, regardless of its current status.
+func (s *WebhookService) Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) error {
+	if _, err := s.ownedWebhook(ctx, userID, webhookID); err != nil {
+		return err
+	}
+	d, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return err
+	}
+	if d.WebhookID != webhookID {
+		return repository.ErrNotFound
+	}
+	return s.repo.Redeliver(ctx, deliveryID)
+}
+
+func (s *WebhookService) ownedWebhook(ctx context.Context, userID, webhookID int64) (*model.Webhook, error) {
+	webhook, err := s.repo.Get(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.UserID != userID {
+		return nil, repository.ErrNotFound
+	}
+	return webhook, nil
+}
+
+// OnOrderEvent queues a delivery for every active webhook of the order owner
+// that is subscribed to eventType. Sending happens in Run.
+func (s *WebhookService) OnOrderEvent(ctx context.Context, eventType string, order *model.Order) {
+	logger := utils.NewHelperLogger("order-service.service.webhooks")
+
+	webhooks, err := s.repo.ListByUser(ctx, order.UserID, true)
+	if err != nil {
+		logger.LogError(ctx, "Failed to load webhooks", err,
+			log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+		)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(eventType) {
+			continue
+		}
+
+		payload := webhookPayload{
+			ID:        events.NewEventID(),
+			Type:      eventType,
+			CreatedAt: time.Now().UTC(),
+			Data:      order,
+		}
+		body, err := json.Marshal(payload)
+		if err != nil {
+			// Остальные подписчики получают событие как обычно
+			logger.LogError(ctx, "Failed to encode webhook payload", err,
+				log.KeyValue{Key: "webhook_id", Value: log.Int64Value(webhook.ID)},
+				log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+				log.KeyValue{Key: "event_type", Value: log.StringValue(eventType)},
+			)
+			continue
+		}
+
+		delivery := &model.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   payload.ID,
+			EventType: eventType,
+			Payload:   body,
+		}
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			logger.LogError(ctx, "Failed to queue webhook delivery", err,
+				log.KeyValue{Key: "webhook_id", Value: log.Int64Value(webhook.ID)},
+				log.KeyValue{Key: "order_id", Value: log.Int64Value(order.ID)},
+			)
+		}
+	}
+}
+
+// Run delivers queued webhooks until ctx is cancelled.
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.deliverDue(ctx)
+	}
+}
+
+// deliverDue makes one attempt for every delivery that is due now.
+func (s *WebhookService) deliverDue(ctx context.Context) {
+	// Аренда с запасом: попытка не может длиться дольше таймаута HTTP-клиента
+	due, err := s.repo.ClaimDue(ctx, 50, 2*s.cfg.Timeout)
+	if err != nil {
+		logger := utils.NewHelperLogger("order-service.service.webhooks")
+		logger.LogError(ctx, "Failed to claim webhook deliveries", err)
+		return
+	}
+	for i := range due {
+		s.attempt(ctx, &due[i])
+	}
+}
+
+func (s *WebhookService) attempt(ctx context.Context, d *model.WebhookDelivery) {
+	logger := utils.NewHelperLogger("order-service.service.webhooks")
+
+	webhook, err := s.repo.Get(ctx, d.WebhookID)
+	if err != nil {
+		// Webhook удалён — доставлять больше некуда
+		d.Status = model.WebhookDeliveryFailed
+		d.LastError = err.Error()
+		s.saveAttempt(ctx, d)
+		return
+	}
+
+	d.Attempts++
+	code, err := s.send(ctx, webhook, d)
+	d.ResponseCode = code
+	if err == nil {
+		now := time.Now()
+		d.Status = model.WebhookDeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+	} else {
+		d.LastError = err.Error()
+		if d.Attempts >= s.cfg.MaxAttempts {
+			d.Status = model.WebhookDeliveryFailed
+		} else {
+			d.NextAttemptAt = time.Now().Add(s.backoff(d.Attempts))
+		}
+		logger.LogWarn(ctx, "Webhook delivery attempt failed",
+			log.KeyValue{Key: "delivery_id", Value: log.Int64Value(d.ID)},
+			log.KeyValue{Key: "attempt", Value: log.IntValue(d.Attempts)},
+			log.KeyValue{Key: "error", Value: log.StringValue(err.Error())},
+		)
+	}
+	s.saveAttempt(ctx, d)
+}
+
+func (s *WebhookService) saveAttempt(ctx context.Context, d *model.WebhookDelivery) {
+	if err := s.repo.SaveAttempt(ctx, d); err != nil {
+		logger := utils.NewHelperLogger("order-service.service.webhooks")
+		logger.LogError(ctx, "Failed to save webhook delivery attempt", err,
+			log.KeyValue{Key: "delivery_id", Value: log.Int64Value(d.ID)},
+		)
+	}
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook *model.Webhook, d *model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, d.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, utils.SignPayload(webhook.Secret, time.Now(), d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns BaseBackoff * 2^(attempt-1), capped at MaxBackoff.
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	d := s.cfg.BaseBackoff
+	for i := 1; i < attempt && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, s.cfg.MaxBackoff)
+}
+
+func newWebhookSecret() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return "whsec_" + hex.EncodeToString(bytes)
+}
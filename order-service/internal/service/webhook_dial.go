@@ -0,0 +1,91 @@
+// internal/service/webhook_dial.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// errPrivateAddress — адрес вебхука внутри сети сервиса (SSRF).
+var errPrivateAddress = errors.New("address is loopback, private or otherwise internal")
+
+// internalPrefixes — диапазоны, которых нет среди net/netip-проверок Is*: CGNAT,
+// "this network", IETF-протоколы, бенчмарки и NAT64, через который видна IPv4-сеть.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// isInternalAddr reports whether a webhook must not be sent to addr: loopback,
+// RFC 1918 / ULA, link-local (включая метаданные облака 169.254.169.254), multicast
+// и служебные диапазоны. Адреса pod'ов и сервисов кластера — частные, они сюда попадают.
+func isInternalAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return true
+	}
+	for _, p := range internalPrefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhookHost resolves host and rejects it if any of its addresses is internal.
+func checkWebhookHost(ctx context.Context, host string) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isInternalAddr(addr) {
+			return errPrivateAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("cannot resolve host: %w", err)
+	}
+	for _, addr := range addrs {
+		if isInternalAddr(addr) {
+			return errPrivateAddress
+		}
+	}
+	return nil
+}
+
+// newWebhookClient returns the HTTP client for deliveries. Unless allowPrivate is set,
+// the dialer refuses internal addresses after DNS resolution, so a host that resolves
+// to an internal address later (DNS rebinding) or a redirect there is not reached either.
+// Вебхуки идут напрямую, без HTTP_PROXY: прокси внутри сети проверка бы отклонила.
+func newWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if isInternalAddr(addrPort.Addr()) {
+				return fmt.Errorf("webhook to %s: %w", address, errPrivateAddress)
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"order-service/internal/model"
+	"order-service/internal/repository"
+	"order-service/internal/utils"
+)
+
+// receiver — локальный стенд мерчанта: отвечает статусами из statuses по очереди
+// (дальше — 200) и запоминает запросы.
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest(nil), r.requests...)
+}
+
+func newTestWebhookService(allowPrivate bool) (*WebhookService, *repository.MemoryWebhookStore) {
+	store := repository.NewMemoryWebhookStore()
+	return NewWebhookService(store, WebhookConfig{
+		MaxAttempts:       3,
+		BaseBackoff:       10 * time.Second,
+		MaxBackoff:        time.Minute,
+		PollInterval:      time.Second,
+		Timeout:           2 * time.Second,
+		AllowPrivateHosts: allowPrivate,
+	}), store
+}
+
+// queueOrderEvent registers a webhook of user 1 on url and queues one order.created delivery.
+func queueOrderEvent(t *testing.T, s *WebhookService, url string) (*model.Webhook, model.WebhookDelivery) {
+	t.Helper()
+	ctx := context.Background()
+	webhook, err := s.Register(ctx, 1, url, []string{model.OrderEventCreated})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	s.OnOrderEvent(ctx, model.OrderEventCreated, &model.Order{ID: 42, UserID: 1, Status: model.OrderStatusPending})
+	return webhook, onlyDelivery(t, s, webhook.ID)
+}
+
+func onlyDelivery(t *testing.T, s *WebhookService, webhookID int64) model.WebhookDelivery {
+	t.Helper()
+	deliveries, err := s.repo.ListDeliveries(context.Background(), webhookID, 10)
+	if err != nil || len(deliveries) != 1 {
+		t.Fatalf("deliveries = %v, %v; want exactly one", deliveries, err)
+	}
+	return deliveries[0]
+}
+
+// makeDue moves the next attempt of a delivery to now, as if the backoff had passed.
+func makeDue(t *testing.T, store *repository.MemoryWebhookStore, id int64) {
+	t.Helper()
+	d, err := store.GetDelivery(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.NextAttemptAt = time.Now()
+	if err := store.SaveAttempt(context.Background(), d); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookDeliveryIsSigned(t *testing.T) {
+	recv := newReceiver(t)
+	s, _ := newTestWebhookService(true)
+	webhook, d := queueOrderEvent(t, s, recv.URL+"/hooks/orders")
+
+	s.deliverDue(context.Background())
+
+	requests := recv.received()
+	if len(requests) != 1 {
+		t.Fatalf("receiver got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+	if err := utils.VerifyPayload(webhook.Secret, req.header.Get(WebhookSignatureHeader), req.body, time.Minute); err != nil {
+		t.Errorf("signature %q does not verify: %v", req.header.Get(WebhookSignatureHeader), err)
+	}
+	if err := utils.VerifyPayload("whsec_other", req.header.Get(WebhookSignatureHeader), req.body, time.Minute); !errors.Is(err, utils.ErrInvalidSignature) {
+		t.Errorf("signature verifies with a wrong secret")
+	}
+	if got := req.header.Get(WebhookEventHeader); got != model.OrderEventCreated {
+		t.Errorf("%s = %q", WebhookEventHeader, got)
+	}
+	if got := req.header.Get(WebhookDeliveryHeader); got != strconv.FormatInt(d.ID, 10) {
+		t.Errorf("%s = %q, want %d", WebhookDeliveryHeader, got, d.ID)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != d.EventID || payload.Type != model.OrderEventCreated || payload.Data.ID != 42 {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	if d := onlyDelivery(t, s, webhook.ID); d.Status != model.WebhookDeliveryDelivered || d.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want delivered", d)
+	}
+}
+
+func TestWebhookRetriesWithBackoff(t *testing.T) {
+	recv := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway)
+	s, store := newTestWebhookService(true)
+	webhook, d := queueOrderEvent(t, s, recv.URL)
+	ctx := context.Background()
+
+	before := time.Now()
+	s.deliverDue(ctx)
+	d = onlyDelivery(t, s, webhook.ID)
+	if d.Status != model.WebhookDeliveryPending || d.Attempts != 1 || d.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("after a 500: %+v", d)
+	}
+	if wait := d.NextAttemptAt.Sub(before); wait < 10*time.Second || wait > 11*time.Second {
+		t.Errorf("first retry in %s, want BaseBackoff (10s)", wait)
+	}
+
+	// До истечения backoff повторной попытки нет
+	s.deliverDue(ctx)
+	if n := len(recv.received()); n != 1 {
+		t.Fatalf("receiver got %d requests before the backoff passed", n)
+	}
+
+	makeDue(t, store, d.ID)
+	before = time.Now()
+	s.deliverDue(ctx)
+	d = onlyDelivery(t, s, webhook.ID)
+	if d.Attempts != 2 || d.ResponseCode != http.StatusBadGateway {
+		t.Fatalf("after a 502: %+v", d)
+	}
+	if wait := d.NextAttemptAt.Sub(before); wait < 20*time.Second || wait > 21*time.Second {
+		t.Errorf("second retry in %s, want 2×BaseBackoff (20s)", wait)
+	}
+
+	makeDue(t, store, d.ID)
+	s.deliverDue(ctx)
+	if d = onlyDelivery(t, s, webhook.ID); d.Status != model.WebhookDeliveryDelivered || d.Attempts != 3 {
+		t.Errorf("after a 200: %+v", d)
+	}
+}
+
+func TestWebhookFailsAfterMaxAttempts(t *testing.T) {
+	recv := newReceiver(t, 500, 500, 500)
+	s, store := newTestWebhookService(true)
+	webhook, d := queueOrderEvent(t, s, recv.URL)
+
+	for i := 0; i < 3; i++ {
+		makeDue(t, store, d.ID)
+		s.deliverDue(context.Background())
+	}
+	if d = onlyDelivery(t, s, webhook.ID); d.Status != model.WebhookDeliveryFailed || d.Attempts != 3 {
+		t.Errorf("delivery = %+v, want failed after 3 attempts", d)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	s, _ := newTestWebhookService(true)
+	for attempt, want := range map[int]time.Duration{
+		1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 10: time.Minute,
+	} {
+		if got := s.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestWebhookRedeliver(t *testing.T) {
+	recv := newReceiver(t, 500, 500, 500)
+	s, store := newTestWebhookService(true)
+	webhook, d := queueOrderEvent(t, s, recv.URL)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		makeDue(t, store, d.ID)
+		s.deliverDue(ctx)
+	}
+
+	if err := s.Redeliver(ctx, 2, webhook.ID, d.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("redeliver by another user: %v, want ErrNotFound", err)
+	}
+	if err := s.Redeliver(ctx, 1, webhook.ID, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if d = onlyDelivery(t, s, webhook.ID); d.Status != model.WebhookDeliveryPending || d.Attempts != 0 {
+		t.Fatalf("after redeliver: %+v", d)
+	}
+
+	s.deliverDue(ctx)
+	requests := recv.received()
+	if d = onlyDelivery(t, s, webhook.ID); d.Status != model.WebhookDeliveryDelivered || len(requests) != 4 {
+		t.Errorf("delivery = %+v after %d requests, want delivered on the 4th", d, len(requests))
+	}
+	// Повторная доставка — то же событие: тот же event id и тело
+	if string(requests[3].body) != string(requests[0].body) {
+		t.Errorf("redelivered body differs from the original")
+	}
+}
+
+func TestWebhookRegisterRejectsInternalHosts(t *testing.T) {
+	s, _ := newTestWebhookService(false)
+	for _, url := range []string{
+		"http://127.0.0.1:8082/hook",
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.0.0.5/hook",
+		"http://172.16.3.4/hook",
+		"http://192.168.0.176/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://100.64.0.1/hook",
+		"http://0.0.0.0/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://[fd00::1]/hook",
+		"ftp://example.com/hook",
+	} {
+		if _, err := s.Register(context.Background(), 1, url, []string{"*"}); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("Register(%s) = %v, want ErrInvalidWebhook", url, err)
+		}
+	}
+
+	if _, err := s.Register(context.Background(), 1, "https://93.184.216.34/hook", []string{"*"}); err != nil {
+		t.Errorf("public address rejected: %v", err)
+	}
+}
+
+func TestWebhookDialRefusesInternalAddresses(t *testing.T) {
+	recv := newReceiver(t)
+	s, store := newTestWebhookService(false)
+
+	// Адрес мог пройти регистрацию и позже начать резолвиться во внутреннюю сеть
+	webhook := &model.Webhook{UserID: 1, URL: recv.URL, Secret: "whsec_test", Events: []string{"*"}, Active: true}
+	if err := store.Create(context.Background(), webhook); err != nil {
+		t.Fatal(err)
+	}
+	s.OnOrderEvent(context.Background(), model.OrderEventCreated, &model.Order{ID: 42, UserID: 1})
+	s.deliverDue(context.Background())
+
+	if n := len(recv.received()); n != 0 {
+		t.Fatalf("receiver on loopback got %d requests", n)
+	}
+	if d := onlyDelivery(t, s, webhook.ID); d.Status != model.WebhookDeliveryPending || d.Attempts != 1 || d.LastError == "" {
+		t.Errorf("delivery = %+v, want a failed attempt", d)
+	}
+}
+
+func TestWebhookOnlySubscribedEvents(t *testing.T) {
+	s, _ := newTestWebhookService(true)
+	ctx := context.Background()
+	paid, err := s.Register(ctx, 1, "http://127.0.0.1/paid", []string{model.OrderEventPaid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := s.Register(ctx, 1, "http://127.0.0.1/all", []string{"*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.OnOrderEvent(ctx, model.OrderEventCreated, &model.Order{ID: 42, UserID: 1})
+
+	if deliveries, _ := s.repo.ListDeliveries(ctx, paid.ID, 10); len(deliveries) != 0 {
+		t.Errorf("order.paid webhook got %d order.created deliveries", len(deliveries))
+	}
+	onlyDelivery(t, s, all.ID)
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"context"
+
+	"contracts/events"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const kafkaTracerName = "order-service.kafka"
+
+// WriteMessages publishes msgs inside a producer span and injects the W3C
+// trace context and baggage into the headers of every message, so consumers
+// can continue the same trace.
+func WriteMessages(ctx context.Context, w *kafka.Writer, msgs ...kafka.Message) error {
+	topic := w.Topic
+	if topic == "" && len(msgs) > 0 {
+		topic = msgs[0].Topic
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingOperationTypeSend,
+		semconv.MessagingOperationName("send"),
+		semconv.MessagingDestinationName(topic),
+	}
+	if len(msgs) == 1 {
+		attrs = append(attrs, semconv.MessagingKafkaMessageKey(string(msgs[0].Key)))
+	} else {
+		attrs = append(attrs, semconv.MessagingBatchMessageCount(len(msgs)))
+	}
+
+	ctx, span := otel.Tracer(kafkaTracerName).Start(ctx, "send "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	propagator := otel.GetTextMapPropagator()
+	for i := range msgs {
+		propagator.Inject(ctx, events.NewHeaderCarrier(&msgs[i]))
+	}
+
+	if err := w.WriteMessages(ctx, msgs...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(semconv.ErrorType(err))
+		return err
+	}
+	return nil
+}
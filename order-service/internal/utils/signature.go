@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SignPayload builds a signature header value "t=<unix>,v1=<hex>",
+// where v1 = HMAC-SHA256(secret, "<unix>." + body).
+func SignPayload(secret string, ts time.Time, body []byte) string {
+	unix := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + unix + ",v1=" + payloadMAC(secret, unix, body)
+}
+
+// VerifyPayload checks a header produced by SignPayload and rejects
+// timestamps older than tolerance to prevent replays.
+func VerifyPayload(secret, header string, body []byte, tolerance time.Duration) error {
+	var unix, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			unix = v
+		case "v1":
+			sig = v
+		}
+	}
+	if unix == "" || sig == "" {
+		return ErrInvalidSignature
+	}
+
+	ts, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(payloadMAC(secret, unix, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func payloadMAC(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
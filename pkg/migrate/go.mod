@@ -0,0 +1,3 @@
+module pkg/migrate
+
+go 1.25.1
@@ -0,0 +1,282 @@
+// migrate.go
+
+// Package migrate применяет версионированные SQL-миграции, вшитые в бинарник
+// сервиса. Общий для всех сервисов: от базы зависят только Driver (соединение,
+// транзакции) и SQL advisory lock — SQLDriver для database/sql (MySQL, PostgreSQL),
+// go-pg-драйвер лежит в inventory-service.
+//
+// Файлы называются NNNN_name.up.sql и NNNN_name.down.sql. Применённые версии
+// записываются в schema_migrations.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var fileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status — миграция и время её применения; AppliedAt == nil, если она ещё не применена.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Driver opens sessions on the service database.
+type Driver interface {
+	// Lock opens a session holding the advisory lock name; closing the session releases it.
+	Lock(ctx context.Context, name string) (Session, error)
+	// Session opens a session without the lock.
+	Session(ctx context.Context) (Session, error)
+}
+
+// Session — одно соединение с базой: advisory lock принадлежит сессии.
+type Session interface {
+	// Exec runs a statement; arguments use "?" placeholders.
+	Exec(ctx context.Context, query string, args ...any) error
+	// Applied returns the versions recorded in schema_migrations with their time.
+	Applied(ctx context.Context) (map[int64]time.Time, error)
+	// InTx runs fn in a transaction; exec runs statements inside it.
+	InTx(ctx context.Context, fn func(exec func(query string, args ...any) error) error) error
+	Close(ctx context.Context) error
+}
+
+// Migrator applies migrations under an advisory lock, so replicas starting at
+// the same time run them once: the others wait and then find nothing to do.
+//
+// Each migration runs in a transaction. MySQL commits DDL implicitly, so there
+// a failed migration may be applied partially; migrations should therefore be
+// safe to re-run (CREATE TABLE IF NOT EXISTS and the like).
+type Migrator struct {
+	driver     Driver
+	lockName   string
+	migrations []Migration
+}
+
+// New loads the migrations in dir of fsys. lockName must be the same for all
+// replicas of a service and differ between services sharing a database server.
+func New(driver Driver, lockName string, fsys fs.FS, dir string) (*Migrator, error) {
+	migrations, err := load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{driver: driver, lockName: lockName, migrations: migrations}, nil
+}
+
+// Up applies up to limit pending migrations in version order (all if limit <= 0).
+func (m *Migrator) Up(ctx context.Context, limit int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(s Session) error {
+		applied, err := s.Applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if limit > 0 && len(done) == limit {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := apply(ctx, s, mig, true); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(s Session) error {
+		applied, err := s.Applied(ctx)
+		if err != nil {
+			return err
+		}
+		for _, mig := range slices.Backward(m.migrations) {
+			if len(done) == steps {
+				break
+			}
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %d_%s has no down script", mig.Version, mig.Name)
+			}
+			if err := apply(ctx, s, mig, false); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Status lists all known migrations with their application time.
+func (m *Migrator) Status(ctx context.Context) (_ []Status, err error) {
+	s, err := m.driver.Session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { err = errors.Join(err, s.Close(context.WithoutCancel(ctx))) }()
+
+	if err := ensureTable(ctx, s); err != nil {
+		return nil, err
+	}
+	applied, err := s.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			st.AppliedAt = &at
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on the session holding the advisory lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(s Session) error) (err error) {
+	s, err := m.driver.Lock(ctx, m.lockName)
+	if err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() { err = errors.Join(err, s.Close(context.WithoutCancel(ctx))) }()
+
+	if err := ensureTable(ctx, s); err != nil {
+		return err
+	}
+	return fn(s)
+}
+
+func ensureTable(ctx context.Context, s Session) error {
+	return s.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`)
+}
+
+func apply(ctx context.Context, s Session, mig Migration, up bool) error {
+	return s.InTx(ctx, func(exec func(query string, args ...any) error) error {
+		script := mig.Down
+		if up {
+			script = mig.Up
+		}
+		for _, stmt := range splitStatements(script) {
+			if err := exec(stmt); err != nil {
+				return fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		if up {
+			return exec(`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`, mig.Version, mig.Name)
+		}
+		return exec(`DELETE FROM schema_migrations WHERE version = ?`, mig.Version)
+	})
+}
+
+// load reads NNNN_name.up.sql / NNNN_name.down.sql pairs from dir, sorted by version.
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		match := fileRe.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", e.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return int(a.Version - b.Version) })
+	return migrations, nil
+}
+
+// splitStatements splits a script on semicolons outside quotes and comments.
+// Backticks are MySQL identifier quotes. Function bodies in dollar quotes are
+// not supported.
+func splitStatements(script string) []string {
+	var stmts []string
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			b.WriteByte('\n')
+			continue
+		case c == ';':
+			if s := strings.TrimSpace(b.String()); s != "" {
+				stmts = append(stmts, s)
+			}
+			b.Reset()
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if s := strings.TrimSpace(b.String()); s != "" {
+		stmts = append(stmts, s)
+	}
+	return stmts
+}
@@ -0,0 +1,149 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// fakeDriver записывает выполненные statements и ведёт schema_migrations в памяти.
+type fakeDriver struct {
+	locked  []string
+	stmts   []string
+	applied map[int64]time.Time
+	failOn  string
+}
+
+func (d *fakeDriver) Lock(_ context.Context, name string) (Session, error) {
+	d.locked = append(d.locked, name)
+	return &fakeSession{d: d}, nil
+}
+
+func (d *fakeDriver) Session(context.Context) (Session, error) {
+	return &fakeSession{d: d}, nil
+}
+
+type fakeSession struct {
+	d *fakeDriver
+}
+
+func (s *fakeSession) Exec(_ context.Context, query string, _ ...any) error {
+	return nil
+}
+
+func (s *fakeSession) Applied(context.Context) (map[int64]time.Time, error) {
+	return s.d.applied, nil
+}
+
+func (s *fakeSession) InTx(_ context.Context, fn func(exec func(query string, args ...any) error) error) error {
+	var stmts []string
+	applied := make(map[int64]time.Time)
+	var deleted []int64
+	err := fn(func(query string, args ...any) error {
+		if query == s.d.failOn {
+			return errors.New("boom")
+		}
+		switch {
+		case len(args) == 2:
+			applied[args[0].(int64)] = time.Now()
+		case len(args) == 1:
+			deleted = append(deleted, args[0].(int64))
+		default:
+			stmts = append(stmts, query)
+		}
+		return nil
+	})
+	if err != nil {
+		return err // откат: ничего не записано
+	}
+	s.d.stmts = append(s.d.stmts, stmts...)
+	for v, at := range applied {
+		s.d.applied[v] = at
+	}
+	for _, v := range deleted {
+		delete(s.d.applied, v)
+	}
+	return nil
+}
+
+func (s *fakeSession) Close(context.Context) error {
+	return nil
+}
+
+var testFS = fstest.MapFS{
+	"m/0001_init.up.sql":     {Data: []byte("CREATE TABLE a (id INT); CREATE TABLE b (id INT);")},
+	"m/0001_init.down.sql":   {Data: []byte("DROP TABLE b; DROP TABLE a;")},
+	"m/0002_extra.up.sql":    {Data: []byte("-- комментарий; не statement\nALTER TABLE a ADD note TEXT DEFAULT 'x;y'")},
+	"m/0002_extra.down.sql":  {Data: []byte("ALTER TABLE a DROP note")},
+	"m/0010_broken.up.sql":   {Data: []byte("CREATE TABLE c (id INT)")},
+	"m/0010_broken.down.sql": {Data: []byte("DROP TABLE c")},
+}
+
+func TestUpDown(t *testing.T) {
+	ctx := context.Background()
+	d := &fakeDriver{applied: map[int64]time.Time{}, failOn: "CREATE TABLE c (id INT)"}
+	m, err := New(d, "test.migrate", testFS, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := m.Up(ctx, 0)
+	if err == nil {
+		t.Fatal("Up applied a failing migration")
+	}
+	if versions(done) != "1,2" {
+		t.Fatalf("applied %s before the failure, want 1,2", versions(done))
+	}
+	want := []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)", "ALTER TABLE a ADD note TEXT DEFAULT 'x;y'"}
+	if !slices.Equal(d.stmts, want) {
+		t.Errorf("statements = %q, want %q", d.stmts, want)
+	}
+	if len(d.applied) != 2 || !slices.Equal(d.locked, []string{"test.migrate"}) {
+		t.Errorf("applied = %v, locks = %v", d.applied, d.locked)
+	}
+
+	d.failOn = ""
+	if done, err = m.Up(ctx, 0); err != nil || versions(done) != "10" {
+		t.Fatalf("second Up: %s, %v", versions(done), err)
+	}
+
+	if done, err = m.Down(ctx, 2); err != nil || versions(done) != "10,2" {
+		t.Fatalf("Down(2): %s, %v", versions(done), err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if (s.AppliedAt != nil) != (s.Version == 1) {
+			t.Errorf("status of %d: applied at %v", s.Version, s.AppliedAt)
+		}
+	}
+}
+
+func TestLoadRejectsBadFiles(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"unexpected name": {"m/1-init.sql": {}},
+		"two names":       {"m/0001_a.up.sql": {Data: []byte("x")}, "m/0001_b.down.sql": {Data: []byte("x")}},
+		"no up script":    {"m/0001_a.down.sql": {Data: []byte("x")}},
+	} {
+		if _, err := load(fsys, "m"); err == nil {
+			t.Errorf("%s: load succeeded", name)
+		}
+	}
+}
+
+func versions(migs []Migration) string {
+	var s string
+	for i, m := range migs {
+		if i > 0 {
+			s += ","
+		}
+		s += strconv.FormatInt(m.Version, 10)
+	}
+	return s
+}
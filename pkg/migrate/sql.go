@@ -0,0 +1,146 @@
+// sql.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+)
+
+const defaultLockTimeout = 5 * time.Minute
+
+// SQLDriver — Driver для database/sql. Блокировка: pg_advisory_lock на PostgreSQL,
+// GET_LOCK на MySQL/MariaDB (с таймаутом LockTimeout).
+type SQLDriver struct {
+	db          *sql.DB
+	dialect     string
+	LockTimeout time.Duration
+}
+
+func NewSQLDriver(db *sql.DB, dialect string) (*SQLDriver, error) {
+	if dialect != DialectMySQL && dialect != DialectPostgres {
+		return nil, fmt.Errorf("unsupported database dialect %q", dialect)
+	}
+	return &SQLDriver{db: db, dialect: dialect, LockTimeout: defaultLockTimeout}, nil
+}
+
+func (d *SQLDriver) Session(ctx context.Context) (Session, error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlSession{conn: conn, dialect: d.dialect}, nil
+}
+
+func (d *SQLDriver) Lock(ctx context.Context, name string) (Session, error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &sqlSession{conn: conn, dialect: d.dialect}
+
+	if d.dialect == DialectPostgres {
+		if err := s.Exec(ctx, `SELECT pg_advisory_lock(hashtext(?))`, name); err != nil {
+			return nil, errors.Join(err, conn.Close())
+		}
+		s.unlock = func(ctx context.Context) error {
+			return s.Exec(ctx, `SELECT pg_advisory_unlock(hashtext(?))`, name)
+		}
+		return s, nil
+	}
+
+	var ok sql.NullInt64
+	if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, name, int(d.LockTimeout.Seconds())).Scan(&ok); err != nil {
+		return nil, errors.Join(err, conn.Close())
+	}
+	if ok.Int64 != 1 {
+		return nil, errors.Join(fmt.Errorf("timed out after %s", d.LockTimeout), conn.Close())
+	}
+	s.unlock = func(ctx context.Context) error {
+		return s.Exec(ctx, `SELECT RELEASE_LOCK(?)`, name)
+	}
+	return s, nil
+}
+
+type sqlSession struct {
+	conn    *sql.Conn
+	dialect string
+	unlock  func(ctx context.Context) error
+}
+
+func (s *sqlSession) Exec(ctx context.Context, query string, args ...any) error {
+	_, err := s.conn.ExecContext(ctx, s.rebind(query, args), args...)
+	return err
+}
+
+func (s *sqlSession) Applied(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := s.conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+func (s *sqlSession) InTx(ctx context.Context, fn func(exec func(query string, args ...any) error) error) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(func(query string, args ...any) error {
+		_, err := tx.ExecContext(ctx, s.rebind(query, args), args...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *sqlSession) Close(ctx context.Context) error {
+	var err error
+	if s.unlock != nil {
+		err = s.unlock(ctx)
+	}
+	return errors.Join(err, s.conn.Close())
+}
+
+// rebind turns "?" into $1, $2, ... for PostgreSQL. Statements without arguments
+// (the migration scripts) are left alone: "?" there is a jsonb operator.
+func (s *sqlSession) rebind(query string, args []any) string {
+	if s.dialect != DialectPostgres || len(args) == 0 {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}